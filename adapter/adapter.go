@@ -0,0 +1,93 @@
+// Package adapter mengubah halaman sumber (API JSON, tabel HTML) menjadi
+// daftar proxy beserta metadata yang dipublikasikan sumber: protokol,
+// negara, dan tingkat anonimitas.
+package adapter
+
+import (
+	"net"
+	"strings"
+)
+
+// Proxy adalah satu proxy dari sumber beserta metadata yang menyertainya.
+// Field metadata kosong jika sumber tidak menyediakannya.
+type Proxy struct {
+	IP        string   `json:"ip"`
+	Port      string   `json:"port"`
+	Protocols []string `json:"protocols,omitempty"`
+	Country   string   `json:"country,omitempty"`
+	Anonymity string   `json:"anonymity,omitempty"`
+	// Field berikut hanya dipublikasikan sebagian situs tabel HTML.
+	CountryName string `json:"country_name,omitempty"`
+	Google      bool   `json:"google,omitempty"`
+	LastChecked string `json:"last_checked,omitempty"`
+}
+
+// Page adalah hasil parsing satu halaman sumber.
+type Page struct {
+	Proxies []Proxy
+	// Items adalah jumlah item atau baris data mentah di halaman, termasuk
+	// yang tidak menghasilkan proxy; dipakai untuk paginasi.
+	Items int
+	// Total adalah jumlah seluruh item yang dilaporkan sumber, 0 jika tidak
+	// diketahui.
+	Total int
+}
+
+// Addr mengembalikan alamat ip:port, IPv6 ditulis dalam kurung siku.
+func (p Proxy) Addr() string {
+	return net.JoinHostPort(p.IP, p.Port)
+}
+
+// Merge melengkapi field metadata yang kosong dari proxy lain dengan alamat sama.
+func (p *Proxy) Merge(other Proxy) {
+	for _, proto := range other.Protocols {
+		if !containsFold(p.Protocols, proto) {
+			p.Protocols = append(p.Protocols, proto)
+		}
+	}
+	if p.Country == "" {
+		p.Country = other.Country
+	}
+	if p.Anonymity == "" {
+		p.Anonymity = other.Anonymity
+	}
+	if p.CountryName == "" {
+		p.CountryName = other.CountryName
+	}
+	if p.LastChecked == "" {
+		p.LastChecked = other.LastChecked
+	}
+	p.Google = p.Google || other.Google
+}
+
+// FromAddr membuat Proxy dari teks ip:port, false jika formatnya salah.
+func FromAddr(addr string) (Proxy, bool) {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(addr))
+	if err != nil || host == "" || port == "" {
+		return Proxy{}, false
+	}
+	return Proxy{IP: host, Port: port}, true
+}
+
+// splitList memecah daftar seperti "http, https" atau "HTTP/SOCKS5".
+func splitList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '/' || r == ';' || r == '|' || r == ' '
+	})
+	out := fields[:0]
+	for _, f := range fields {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,500 @@
+package adapter
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Decoder membuka penyamaran di halaman sebelum tabel dibaca, misalnya
+// alamat yang ditulis lewat JavaScript atau port yang dirender lewat CSS.
+type Decoder func(doc *goquery.Document)
+
+// AllDecoders menjalankan semua decoder bawaan dengan urutan yang aman:
+// CSS dulu agar elemen umpan tersembunyi dibuang, lalu script, lalu blob.
+var AllDecoders = []Decoder{DecodeCSS, DecodeScripts, DecodeBlobs}
+
+// --- CSS ---
+
+var (
+	cssContentRule = regexp.MustCompile(`\.([\w-]+)\s*:{1,2}(before|after)\s*\{[^}]*?content\s*:\s*["']([^"']*)["']`)
+	cssHiddenRule  = regexp.MustCompile(`\.([\w-]+)\s*\{[^}]*?(?:display\s*:\s*none|visibility\s*:\s*hidden)`)
+	inlineHidden   = regexp.MustCompile(`(?i)display\s*:\s*none|visibility\s*:\s*hidden`)
+)
+
+// DecodeCSS menerapkan aturan dari blok <style>: elemen dengan kelas
+// tersembunyi dibuang, dan isi ::before/::after (biasanya digit port)
+// ditulis sebagai teks biasa agar terbaca oleh parser tabel.
+func DecodeCSS(doc *goquery.Document) {
+	before := make(map[string]string)
+	after := make(map[string]string)
+	hidden := make(map[string]bool)
+	doc.Find("style").Each(func(_ int, s *goquery.Selection) {
+		css := s.Text()
+		for _, m := range cssContentRule.FindAllStringSubmatch(css, -1) {
+			if m[2] == "before" {
+				before[m[1]] = m[3]
+			} else {
+				after[m[1]] = m[3]
+			}
+		}
+		for _, m := range cssHiddenRule.FindAllStringSubmatch(css, -1) {
+			hidden[m[1]] = true
+		}
+	})
+
+	doc.Find("[style]").Each(func(_ int, s *goquery.Selection) {
+		if style, _ := s.Attr("style"); inlineHidden.MatchString(style) {
+			s.Remove()
+		}
+	})
+	doc.Find("[class]").Each(func(_ int, s *goquery.Selection) {
+		class, _ := s.Attr("class")
+		for _, c := range strings.Fields(class) {
+			if hidden[c] {
+				s.Remove()
+				return
+			}
+		}
+		for _, c := range strings.Fields(class) {
+			if text, ok := before[c]; ok {
+				s.PrependHtml(html.EscapeString(text))
+			}
+			if text, ok := after[c]; ok {
+				s.AppendHtml(html.EscapeString(text))
+			}
+		}
+	})
+}
+
+// --- blob base64 / hex ---
+
+var (
+	base64Blob = regexp.MustCompile(`^[A-Za-z0-9+/]{4,}={0,2}$`)
+	hexBlob    = regexp.MustCompile(`^(?:[0-9a-fA-F]{2}){4,}$`)
+)
+
+// DecodeBlobs mengganti isi sel yang seluruhnya berupa blob base64 atau
+// hex dengan hasil decode-nya, asalkan hasilnya terlihat seperti alamat.
+func DecodeBlobs(doc *goquery.Document) {
+	decodeCells(doc, func(text string) (string, bool) {
+		if hexBlob.MatchString(text) {
+			if b, err := hex.DecodeString(text); err == nil && looksLikeAddr(string(b)) {
+				return string(b), true
+			}
+		}
+		if base64Blob.MatchString(text) && len(text)%4 == 0 {
+			if b, err := base64.StdEncoding.DecodeString(text); err == nil && looksLikeAddr(string(b)) {
+				return string(b), true
+			}
+		}
+		return "", false
+	})
+}
+
+// DecodeXORHex seperti DecodeBlobs untuk blob hex yang setiap byte-nya
+// di-XOR dengan key yang sama.
+func DecodeXORHex(key byte) Decoder {
+	return func(doc *goquery.Document) {
+		decodeCells(doc, func(text string) (string, bool) {
+			if !hexBlob.MatchString(text) {
+				return "", false
+			}
+			b, err := hex.DecodeString(text)
+			if err != nil {
+				return "", false
+			}
+			for i := range b {
+				b[i] ^= key
+			}
+			return string(b), looksLikeAddr(string(b))
+		})
+	}
+}
+
+func decodeCells(doc *goquery.Document, decode func(string) (string, bool)) {
+	doc.Find("td").Each(func(_ int, cell *goquery.Selection) {
+		if cell.Children().Length() > 0 {
+			return
+		}
+		if text, ok := decode(strings.TrimSpace(cell.Text())); ok {
+			cell.SetText(text)
+		}
+	})
+}
+
+// looksLikeAddr menerima teks berisi IP, port, atau ip:port saja.
+func looksLikeAddr(s string) bool {
+	digit := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digit = true
+		case r == '.' || r == ':' || r == '[' || r == ']',
+			r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+		default:
+			return false
+		}
+	}
+	return digit
+}
+
+// --- JavaScript ---
+
+// DecodeScripts mengevaluasi script sederhana yang menulis alamat lewat
+// document.write: penggabungan string, atob/Base64.decode, unescape,
+// String.fromCharCode, XOR angka, dan variabel yang didefinisikan di
+// script sebelumnya. Setiap <script> yang memanggil document.write diganti
+// dengan hasilnya; script yang tidak dipahami dibiarkan.
+func DecodeScripts(doc *goquery.Document) {
+	vars := make(map[string]jsValue)
+	doc.Find("script").Each(func(_ int, s *goquery.Selection) {
+		if src, ok := s.Attr("src"); ok && src != "" {
+			return
+		}
+		out, wrote := runScript(s.Text(), vars)
+		if wrote {
+			s.ReplaceWithHtml(out)
+		}
+	})
+}
+
+type jsValue struct {
+	str   string
+	num   int64
+	isNum bool
+}
+
+func (v jsValue) String() string {
+	if v.isNum {
+		return strconv.FormatInt(v.num, 10)
+	}
+	return v.str
+}
+
+type jsToken struct {
+	kind byte // 's' string, 'n' angka, 'i' identifier, 'p' tanda baca
+	text string
+	num  int64
+}
+
+// runScript menjalankan pernyataan yang dikenali dan mengembalikan semua
+// keluaran document.write. Pernyataan lain dilewati sampai ';' berikutnya.
+func runScript(src string, vars map[string]jsValue) (string, bool) {
+	tokens, ok := tokenizeJS(src)
+	if !ok {
+		return "", false
+	}
+	p := &jsParser{tokens: tokens, vars: vars}
+	var out strings.Builder
+	wrote := false
+	for !p.done() {
+		start := p.pos
+		if p.statement(&out, &wrote) {
+			continue
+		}
+		p.pos = start
+		p.skipStatement()
+	}
+	return out.String(), wrote
+}
+
+func tokenizeJS(src string) ([]jsToken, bool) {
+	var tokens []jsToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, false
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			s, n, ok := readJSString(src[i:])
+			if !ok {
+				return nil, false
+			}
+			tokens = append(tokens, jsToken{kind: 's', text: s})
+			i += n
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			n, err := strconv.ParseInt(src[i:j], 0, 64)
+			if err != nil {
+				return nil, false
+			}
+			tokens = append(tokens, jsToken{kind: 'n', num: n})
+			i = j
+		case isIdentChar(c) || c == '$':
+			j := i
+			for j < len(src) && (isIdentChar(src[j]) || src[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, jsToken{kind: 'i', text: src[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, jsToken{kind: 'p', text: string(c)})
+			i++
+		}
+	}
+	return tokens, true
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// readJSString membaca literal string beserta escape \x, \u dan karakter biasa.
+func readJSString(s string) (string, int, bool) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, true
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'x':
+				if i+2 >= len(s) {
+					return "", 0, false
+				}
+				v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+				if err != nil {
+					return "", 0, false
+				}
+				b.WriteByte(byte(v))
+				i += 2
+			case 'u':
+				if i+4 >= len(s) {
+					return "", 0, false
+				}
+				v, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+				if err != nil {
+					return "", 0, false
+				}
+				b.WriteRune(rune(v))
+				i += 4
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+type jsParser struct {
+	tokens []jsToken
+	pos    int
+	vars   map[string]jsValue
+}
+
+func (p *jsParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *jsParser) peek() jsToken {
+	if p.done() {
+		return jsToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *jsParser) accept(kind byte, text string) bool {
+	if t := p.peek(); t.kind == kind && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *jsParser) skipStatement() {
+	for !p.done() {
+		t := p.tokens[p.pos]
+		p.pos++
+		if t.kind == 'p' && t.text == ";" {
+			return
+		}
+	}
+}
+
+// statement mengenali "var x = expr", "x = expr" dan "document.write(expr)".
+func (p *jsParser) statement(out *strings.Builder, wrote *bool) bool {
+	if p.accept('p', ";") {
+		return true
+	}
+	t := p.peek()
+	if t.kind != 'i' {
+		return false
+	}
+	if t.text == "var" || t.text == "let" || t.text == "const" {
+		p.pos++
+		t = p.peek()
+		if t.kind != 'i' {
+			return false
+		}
+	}
+	name := p.dotted()
+	switch {
+	case name == "document.write" || name == "document.writeln":
+		if !p.accept('p', "(") {
+			return false
+		}
+		v, ok := p.expr()
+		if !ok || !p.accept('p', ")") {
+			return false
+		}
+		out.WriteString(v.String())
+		*wrote = true
+	case p.accept('p', "="):
+		v, ok := p.expr()
+		if !ok {
+			return false
+		}
+		p.vars[name] = v
+	default:
+		return false
+	}
+	return p.done() || p.accept('p', ";") || p.peek().kind == 'i'
+}
+
+// dotted membaca identifier bertitik seperti Base64.decode.
+func (p *jsParser) dotted() string {
+	name := p.tokens[p.pos].text
+	p.pos++
+	for p.peek().kind == 'p' && p.peek().text == "." && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == 'i' {
+		name += "." + p.tokens[p.pos+1].text
+		p.pos += 2
+	}
+	return name
+}
+
+// expr = xor ('+' xor)*
+func (p *jsParser) expr() (jsValue, bool) {
+	left, ok := p.xor()
+	if !ok {
+		return jsValue{}, false
+	}
+	for p.accept('p', "+") {
+		right, ok := p.xor()
+		if !ok {
+			return jsValue{}, false
+		}
+		if left.isNum && right.isNum {
+			left = jsValue{num: left.num + right.num, isNum: true}
+		} else {
+			left = jsValue{str: left.String() + right.String()}
+		}
+	}
+	return left, true
+}
+
+// xor = primary ('^' primary)*
+func (p *jsParser) xor() (jsValue, bool) {
+	left, ok := p.primary()
+	if !ok {
+		return jsValue{}, false
+	}
+	for p.accept('p', "^") {
+		right, ok := p.primary()
+		if !ok || !left.isNum || !right.isNum {
+			return jsValue{}, false
+		}
+		left = jsValue{num: left.num ^ right.num, isNum: true}
+	}
+	return left, true
+}
+
+func (p *jsParser) primary() (jsValue, bool) {
+	t := p.peek()
+	switch t.kind {
+	case 's':
+		p.pos++
+		return jsValue{str: t.text}, true
+	case 'n':
+		p.pos++
+		return jsValue{num: t.num, isNum: true}, true
+	case 'p':
+		if !p.accept('p', "(") {
+			return jsValue{}, false
+		}
+		v, ok := p.expr()
+		if !ok || !p.accept('p', ")") {
+			return jsValue{}, false
+		}
+		return v, true
+	case 'i':
+		name := p.dotted()
+		if !p.accept('p', "(") {
+			v, ok := p.vars[name]
+			return v, ok
+		}
+		var args []jsValue
+		for !p.accept('p', ")") {
+			if len(args) > 0 && !p.accept('p', ",") {
+				return jsValue{}, false
+			}
+			v, ok := p.expr()
+			if !ok {
+				return jsValue{}, false
+			}
+			args = append(args, v)
+		}
+		return callJS(name, args)
+	}
+	return jsValue{}, false
+}
+
+// callJS menjalankan fungsi bawaan yang sering dipakai untuk menyamarkan alamat.
+func callJS(name string, args []jsValue) (jsValue, bool) {
+	switch name {
+	case "atob", "window.atob", "Base64.decode":
+		if len(args) != 1 {
+			return jsValue{}, false
+		}
+		b, err := base64.StdEncoding.DecodeString(args[0].String())
+		if err != nil {
+			return jsValue{}, false
+		}
+		return jsValue{str: string(b)}, true
+	case "unescape", "decodeURIComponent", "decodeURI":
+		if len(args) != 1 {
+			return jsValue{}, false
+		}
+		s, err := url.PathUnescape(args[0].String())
+		if err != nil {
+			return jsValue{}, false
+		}
+		return jsValue{str: s}, true
+	case "String.fromCharCode":
+		var b strings.Builder
+		for _, a := range args {
+			if !a.isNum {
+				return jsValue{}, false
+			}
+			b.WriteRune(rune(a.num))
+		}
+		return jsValue{str: b.String()}, true
+	}
+	return jsValue{}, false
+}
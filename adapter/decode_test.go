@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func loadFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func addrs(proxies []Proxy) []string {
+	var out []string
+	for _, p := range proxies {
+		out = append(out, p.Addr())
+	}
+	return out
+}
+
+func TestTableDecoders(t *testing.T) {
+	tests := []struct {
+		fixture string
+		table   Table
+		want    []string
+	}{
+		{
+			fixture: "free-proxy-cz.html",
+			table:   Table{Selector: "table#proxy_list", Decoders: []Decoder{DecodeScripts}},
+			want:    []string{"185.217.199.176:4444", "45.77.177.53:3128"},
+		},
+		{
+			fixture: "spys-xor.html",
+			table: Table{
+				Headers:  map[string]Field{"proxy address:port": FieldIP, "proxy type": FieldProtocols},
+				Decoders: []Decoder{DecodeScripts},
+			},
+			want: []string{"103.152.112.162:8080", "8.219.97.248:8080"},
+		},
+		{
+			fixture: "css-ports.html",
+			table:   Table{Selector: "table.proxies", Decoders: AllDecoders},
+			want:    []string{"51.158.68.44:8081", "172.67.189.40:38080", "138.68.60.8:8088"},
+		},
+		{
+			fixture: "xor-hex.html",
+			table:   Table{Selector: "table#list", Decoders: []Decoder{DecodeXORHex(0x4a)}},
+			want:    []string{"10.20.30.40:3128"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			proxies, err := tt.table.Parse(loadFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := addrs(proxies); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableDecodersMetadata(t *testing.T) {
+	proxies, err := Table{Selector: "table#proxy_list", Decoders: []Decoder{DecodeScripts}}.Parse(loadFixture(t, "free-proxy-cz.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Proxy{
+		IP:          "185.217.199.176",
+		Port:        "4444",
+		Protocols:   []string{"socks5"},
+		Anonymity:   "high anonymity",
+		CountryName: "Czech Republic",
+		LastChecked: "7 minutes ago",
+	}
+	if len(proxies) == 0 || !reflect.DeepEqual(proxies[0], want) {
+		t.Errorf("got %+v, want %+v", proxies, want)
+	}
+}
+
+func TestWithoutDecoders(t *testing.T) {
+	// Tanpa decoder, alamat yang ditulis lewat script tidak terbaca, tetapi
+	// barisnya tetap dihitung untuk paginasi
+	page, err := Table{Selector: "table#proxy_list"}.ParsePage(loadFixture(t, "free-proxy-cz.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Proxies) != 0 {
+		t.Errorf("got %v, want none", addrs(page.Proxies))
+	}
+	if page.Items != 2 {
+		t.Errorf("Items = %d, want 2", page.Items)
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`document.write(atob("MS4yLjMuNA=="))`, "1.2.3.4"},
+		{`var a = "1.2"; var b = '.3.4'; document.write(a + b + ":" + (80^0))`, "1.2.3.4:80"},
+		{`document.write(unescape("%31%2e%32") + "\x2e3")`, "1.2.3"},
+		{`document.write(String.fromCharCode(0x38, 0x30))`, "80"},
+		{`foo(); document.write("ok")`, "ok"},
+	}
+	for _, tt := range tests {
+		got, wrote := runScript(tt.src, make(map[string]jsValue))
+		if !wrote || got != tt.want {
+			t.Errorf("runScript(%q) = %q, %v; want %q", tt.src, got, wrote, tt.want)
+		}
+	}
+}
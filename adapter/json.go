@@ -0,0 +1,188 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSON memetakan respons API JSON ke Proxy lewat selector mirip JSONPath:
+// "$.data[*]", "data", "result.items", "ports[0]". Selector field dibaca
+// relatif terhadap satu item. Port boleh berupa angka atau string, dan jika
+// Port kosong nilai IP boleh berbentuk "ip:port".
+type JSON struct {
+	// Items menunjuk ke array proxy; kosong berarti dokumen itu sendiri array.
+	Items     string
+	IP        string
+	Port      string
+	Protocols string
+	Country   string
+	Anonymity string
+	// Total menunjuk ke jumlah seluruh item di sumber, dipakai untuk paginasi.
+	Total string
+}
+
+// Parse membaca body dan mengembalikan proxy beserta total dari sumber
+// (0 jika tidak diketahui). Item tanpa ip atau port dilewati.
+func (j JSON) Parse(body []byte) ([]Proxy, int, error) {
+	page, err := j.ParsePage(body)
+	return page.Proxies, page.Total, err
+}
+
+// ParsePage seperti Parse tetapi juga melaporkan jumlah item mentah.
+func (j JSON) ParsePage(body []byte) (Page, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return Page{}, fmt.Errorf("json tidak valid: %w", err)
+	}
+
+	items, ok := lookup(doc, j.Items)
+	if !ok {
+		return Page{}, fmt.Errorf("selector %q tidak ditemukan", j.Items)
+	}
+	list, ok := items.([]any)
+	if !ok {
+		return Page{}, fmt.Errorf("selector %q bukan array", j.Items)
+	}
+
+	page := Page{Items: len(list)}
+	if j.Total != "" {
+		if v, ok := lookup(doc, j.Total); ok {
+			page.Total, _ = strconv.Atoi(scalar(v))
+		}
+	}
+
+	for _, item := range list {
+		p, ok := j.proxy(item)
+		if ok {
+			page.Proxies = append(page.Proxies, p)
+		}
+	}
+	return page, nil
+}
+
+func (j JSON) proxy(item any) (Proxy, bool) {
+	p := Proxy{
+		IP:        j.field(item, j.IP),
+		Port:      j.field(item, j.Port),
+		Country:   strings.ToUpper(j.field(item, j.Country)),
+		Anonymity: strings.ToLower(j.field(item, j.Anonymity)),
+	}
+	if p.Port == "" {
+		addr, ok := FromAddr(p.IP)
+		if !ok {
+			return Proxy{}, false
+		}
+		p.IP, p.Port = addr.IP, addr.Port
+	}
+	if p.IP == "" || p.Port == "" {
+		return Proxy{}, false
+	}
+	if j.Protocols != "" {
+		if v, ok := lookup(item, j.Protocols); ok {
+			switch v := v.(type) {
+			case []any:
+				for _, e := range v {
+					p.Protocols = append(p.Protocols, splitList(scalar(e))...)
+				}
+			default:
+				p.Protocols = splitList(scalar(v))
+			}
+		}
+	}
+	return p, true
+}
+
+func (j JSON) field(item any, selector string) string {
+	if selector == "" {
+		return ""
+	}
+	v, ok := lookup(item, selector)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(scalar(v))
+}
+
+// lookup mengikuti selector di dalam dokumen hasil decode. Awalan "$" dan
+// akhiran "[*]" diabaikan sehingga "$.data[*]" sama dengan "data".
+func lookup(doc any, selector string) (any, bool) {
+	selector = strings.TrimPrefix(strings.TrimPrefix(selector, "$"), ".")
+	selector = strings.TrimSuffix(selector, "[*]")
+	if selector == "" {
+		return doc, true
+	}
+
+	cur := doc
+	for _, part := range strings.Split(selector, ".") {
+		name, indexes, err := splitIndexes(part)
+		if err != nil {
+			return nil, false
+		}
+		if name != "" {
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = obj[name]; !ok {
+				return nil, false
+			}
+		}
+		for _, i := range indexes {
+			arr, ok := cur.([]any)
+			if !ok || i < 0 || i >= len(arr) {
+				return nil, false
+			}
+			cur = arr[i]
+		}
+	}
+	return cur, true
+}
+
+// splitIndexes memecah "ports[0][1]" menjadi "ports" dan [0 1].
+func splitIndexes(part string) (string, []int, error) {
+	name, rest, found := strings.Cut(part, "[")
+	if !found {
+		return part, nil, nil
+	}
+	var indexes []int
+	for _, seg := range strings.Split("["+rest, "[")[1:] {
+		seg, ok := strings.CutSuffix(seg, "]")
+		if !ok {
+			return "", nil, fmt.Errorf("selector tidak valid: %q", part)
+		}
+		i, err := strconv.Atoi(seg)
+		if err != nil {
+			return "", nil, fmt.Errorf("indeks tidak valid: %q", part)
+		}
+		indexes = append(indexes, i)
+	}
+	return name, indexes, nil
+}
+
+// scalar mengubah nilai JSON sederhana menjadi string; angka ditulis tanpa
+// notasi desimal agar port 8080 tidak menjadi "8080.0".
+func scalar(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+		if f, err := v.Float64(); err == nil && f == float64(int64(f)) {
+			return strconv.FormatInt(int64(f), 10)
+		}
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
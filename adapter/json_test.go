@@ -0,0 +1,31 @@
+package adapter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONParsePage(t *testing.T) {
+	body := []byte(`{"total": 120, "data": [
+		{"ip": "1.2.3.4", "port": 8080, "protocols": ["http"]},
+		{"ip": "5.6.7.8:3128"},
+		{"ip": "", "port": "80"},
+		{"host": "9.9.9.9"}
+	]}`)
+	j := JSON{Items: "$.data[*]", IP: "ip", Port: "port", Protocols: "protocols", Total: "total"}
+	page, err := j.ParsePage(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Item yang tidak menghasilkan proxy tetap dihitung di Items
+	if page.Items != 4 || page.Total != 120 {
+		t.Errorf("Items %d Total %d, want 4 dan 120", page.Items, page.Total)
+	}
+	if got, want := addrs(page.Proxies), []string{"1.2.3.4:8080", "5.6.7.8:3128"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := (JSON{Items: "rows"}).ParsePage(body); err == nil {
+		t.Error("selector yang tidak ada diterima")
+	}
+}
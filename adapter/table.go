@@ -0,0 +1,221 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Field adalah nama field Proxy yang bisa diisi dari kolom tabel.
+type Field string
+
+const (
+	FieldIP          Field = "ip"
+	FieldPort        Field = "port"
+	FieldCode        Field = "code"
+	FieldCountry     Field = "country"
+	FieldAnonymity   Field = "anonymity"
+	FieldGoogle      Field = "google"
+	FieldHTTPS       Field = "https"
+	FieldProtocols   Field = "protocols"
+	FieldLastChecked Field = "last_checked"
+)
+
+// defaultHeaders memetakan teks header yang umum dipakai situs daftar proxy.
+var defaultHeaders = map[string]Field{
+	"ip":              FieldIP,
+	"ip address":      FieldIP,
+	"ip adress":       FieldIP,
+	"host":            FieldIP,
+	"port":            FieldPort,
+	"code":            FieldCode,
+	"country code":    FieldCode,
+	"country":         FieldCountry,
+	"anonymity":       FieldAnonymity,
+	"anonymity level": FieldAnonymity,
+	"level":           FieldAnonymity,
+	"google":          FieldGoogle,
+	"https":           FieldHTTPS,
+	"ssl":             FieldHTTPS,
+	"protocol":        FieldProtocols,
+	"protocols":       FieldProtocols,
+	"type":            FieldProtocols,
+	"last checked":    FieldLastChecked,
+	"last check":      FieldLastChecked,
+	"checked":         FieldLastChecked,
+	"last update":     FieldLastChecked,
+}
+
+// Table membaca tabel HTML dan memetakan kolom lewat teks header, bukan
+// posisi kolom, sehingga kolom yang berpindah urutan tetap terbaca. Jika
+// Selector tidak lagi menemukan tabel yang cocok, semua tabel di halaman
+// diperiksa dan tabel pertama yang punya kolom ip dan port dipakai.
+type Table struct {
+	// Selector menunjuk tabel proxy, misalnya "table#proxylisttable".
+	Selector string
+	// Headers menambah atau menimpa pemetaan header (huruf kecil) ke field.
+	Headers map[string]Field
+	// Decoders dijalankan berurutan pada halaman sebelum tabel dibaca.
+	Decoders []Decoder
+}
+
+// Parse mengembalikan proxy dari tabel pertama yang kolomnya dikenali.
+func (t Table) Parse(doc *goquery.Document) ([]Proxy, error) {
+	page, err := t.ParsePage(doc)
+	return page.Proxies, err
+}
+
+// ParsePage seperti Parse tetapi juga melaporkan jumlah baris data mentah,
+// termasuk baris yang alamatnya tidak terbaca.
+func (t Table) ParsePage(doc *goquery.Document) (Page, error) {
+	for _, decode := range t.Decoders {
+		decode(doc)
+	}
+	if t.Selector != "" {
+		if page, ok := t.parseFirst(doc.Find(t.Selector)); ok {
+			return page, nil
+		}
+	}
+	if page, ok := t.parseFirst(doc.Find("table")); ok {
+		return page, nil
+	}
+	return Page{}, fmt.Errorf("tidak ada tabel dengan kolom ip dan port (selector %q)", t.Selector)
+}
+
+func (t Table) parseFirst(tables *goquery.Selection) (Page, bool) {
+	var page Page
+	found := false
+	tables.EachWithBreak(func(_ int, table *goquery.Selection) bool {
+		columns, headerRow := t.columns(table)
+		if !hasField(columns, FieldIP) {
+			return true
+		}
+		found = true
+		page = t.rows(table, columns, headerRow)
+		return false
+	})
+	return page, found
+}
+
+// columns membaca header dari thead, atau dari baris pertama jika tabel
+// tidak punya thead. headerRow adalah baris yang harus dilewati saat membaca data.
+func (t Table) columns(table *goquery.Selection) ([]Field, *goquery.Selection) {
+	row := table.Find("thead tr").First()
+	if row.Length() == 0 {
+		row = table.Find("tr").First()
+	}
+	cells := row.Find("th, td")
+	columns := make([]Field, cells.Length())
+	cells.Each(func(i int, cell *goquery.Selection) {
+		columns[i] = t.field(cell.Text())
+	})
+	return columns, row
+}
+
+func (t Table) field(header string) Field {
+	header = strings.ToLower(strings.Join(strings.Fields(header), " "))
+	header = strings.TrimSuffix(header, ":")
+	if f, ok := t.Headers[header]; ok {
+		return f
+	}
+	return defaultHeaders[header]
+}
+
+func (t Table) rows(table *goquery.Selection, columns []Field, headerRow *goquery.Selection) Page {
+	// Tanpa kolom port, kolom ip diharapkan berisi ip:port
+	combined := !hasField(columns, FieldPort)
+
+	var page Page
+	table.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		if row.IsSelection(headerRow) || row.Find("th").Length() == len(columns) {
+			return
+		}
+		// Baris tanpa sel data atau satu sel selebar tabel (iklan, pemisah)
+		// bukan item
+		if cells := row.Find("td").Length(); cells == 0 || (cells == 1 && len(columns) > 1) {
+			return
+		}
+		page.Items++
+		var p Proxy
+		row.Find("td").Each(func(i int, cell *goquery.Selection) {
+			if i >= len(columns) {
+				return
+			}
+			setField(&p, columns[i], cellText(cell))
+		})
+		if combined {
+			addr, ok := FromAddr(p.IP)
+			if !ok {
+				return
+			}
+			p.IP, p.Port = addr.IP, addr.Port
+		}
+		if p.IP != "" && p.Port != "" {
+			page.Proxies = append(page.Proxies, p)
+		}
+	})
+	return page
+}
+
+// cellText mengembalikan teks sel tanpa isi <script> dan <style>, agar
+// kode penyamaran yang belum di-decode tidak terbaca sebagai alamat.
+func cellText(cell *goquery.Selection) string {
+	if cell.Find("script, style").Length() == 0 {
+		return strings.TrimSpace(cell.Text())
+	}
+	clone := cell.Clone()
+	clone.Find("script, style").Remove()
+	return strings.TrimSpace(clone.Text())
+}
+
+func setField(p *Proxy, f Field, value string) {
+	switch f {
+	case FieldIP:
+		p.IP = value
+	case FieldPort:
+		p.Port = value
+	case FieldCode:
+		p.Country = strings.ToUpper(value)
+	case FieldCountry:
+		// Sebagian situs hanya punya kolom Country berisi kode dua huruf
+		if len(value) == 2 && p.Country == "" {
+			p.Country = strings.ToUpper(value)
+		} else {
+			p.CountryName = value
+		}
+	case FieldAnonymity:
+		p.Anonymity = strings.ToLower(value)
+	case FieldGoogle:
+		p.Google = isYes(value)
+	case FieldHTTPS:
+		if isYes(value) && !containsFold(p.Protocols, "https") {
+			p.Protocols = append(p.Protocols, "https")
+		}
+	case FieldProtocols:
+		for _, proto := range splitList(value) {
+			if !containsFold(p.Protocols, proto) {
+				p.Protocols = append(p.Protocols, proto)
+			}
+		}
+	case FieldLastChecked:
+		p.LastChecked = value
+	}
+}
+
+func isYes(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "y", "true", "1", "+":
+		return true
+	}
+	return false
+}
+
+func hasField(columns []Field, f Field) bool {
+	for _, c := range columns {
+		if c == f {
+			return true
+		}
+	}
+	return false
+}
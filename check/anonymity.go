@@ -0,0 +1,79 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Tingkat anonimitas proxy menurut judge.
+const (
+	// Transparent berarti IP asli kita terlihat oleh target.
+	Transparent = "transparent"
+	// Anonymous berarti IP asli tersembunyi tetapi proxy mengaku sebagai
+	// proxy lewat header seperti Via atau X-Forwarded-For.
+	Anonymous = "anonymous"
+	// Elite berarti target tidak melihat tanda proxy sama sekali.
+	Elite = "elite"
+)
+
+// DefaultJudge memantulkan header request dan IP asal dalam body.
+const DefaultJudge = "http://httpbin.org/get"
+
+// proxyHeaders adalah header yang ditambahkan proxy non-elite. Judge JSON
+// seperti httpbin menulisnya sebagai "via", judge gaya azenv.php sebagai
+// HTTP_VIA.
+var proxyHeaders = []string{
+	"via", "forwarded", "x-forwarded-for", "x-real-ip", "client-ip",
+	"x-client-ip", "x-originating-ip", "proxy-connection", "x-proxy-id",
+}
+
+// Anonymity mengklasifikasikan body judge yang diambil lewat proxy. realIP
+// adalah IP publik kita sendiri, lihat PublicIP.
+func Anonymity(body []byte, realIP string) string {
+	if ip := net.ParseIP(realIP); ip != nil {
+		realIP = ip.String()
+	}
+	for _, ip := range ips(body) {
+		if ip == realIP {
+			return Transparent
+		}
+	}
+	text := strings.ReplaceAll(strings.ToLower(string(body)), "_", "-")
+	for _, h := range proxyHeaders {
+		if strings.Contains(text, `"`+h+`"`) || strings.Contains(text, "http-"+h) {
+			return Anonymous
+		}
+	}
+	return Elite
+}
+
+// PublicIP mengambil judge tanpa proxy dan mengembalikan IP pertama di
+// body, yaitu IP publik kita menurut judge.
+func PublicIP(ctx context.Context, judge string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", judge, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("judge membalas HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", err
+	}
+	found := ips(body)
+	if len(found) == 0 {
+		return "", fmt.Errorf("judge tidak mengembalikan IP")
+	}
+	return found[0], nil
+}
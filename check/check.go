@@ -0,0 +1,325 @@
+// Package check memvalidasi proxy dalam dua tahap: pre-screen TCP singkat
+// untuk membuang port yang tertutup, lalu request HTTP lewat proxy ke
+// beberapa URL tes. Checker tidak menyimpan state sehingga aman dipakai
+// dari banyak goroutine.
+package check
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"deep.go/limiter"
+	"deep.go/parse"
+	"deep.go/prescreen"
+	"deep.go/ratelimit"
+	"deep.go/reason"
+)
+
+// Tahap pengecekan yang dicatat di Result
+const (
+	StageTCP  = "tcp"
+	StageHTTP = "http"
+)
+
+// DefaultTestURLs adalah URL tes bawaan. Keduanya mengembalikan IP pemanggil
+// sehingga body bisa dipakai untuk memastikan proxy benar-benar meneruskan.
+var DefaultTestURLs = []string{
+	"http://httpbin.org/ip",
+	"http://icanhazip.com",
+}
+
+// DefaultUserAgent dikirim agar request tes tidak langsung dikenali sebagai bot.
+const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+
+// Result adalah hasil pengecekan satu proxy beserta alasan kegagalannya.
+type Result struct {
+	Proxy     string        `json:"proxy"`
+	Valid     bool          `json:"valid"`
+	Stage     string        `json:"stage"`
+	Reason    reason.Reason `json:"reason,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	LatencyMs float64       `json:"latency_ms,omitempty"`
+	Sources   []string      `json:"sources,omitempty"`
+	// Anonymity adalah Transparent, Anonymous atau Elite; kosong jika
+	// judge tidak dipakai atau gagal diambil.
+	Anonymity string    `json:"anonymity,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+
+	// Err adalah error asli, misalnya untuk limiter.Classify; tidak disimpan.
+	Err error `json:"-"`
+}
+
+// NewResult membuat Result untuk proxy pada tahap stage. err nil berarti valid.
+func NewResult(proxy parse.Proxy, stage string, err error, latency time.Duration) Result {
+	result := Result{
+		Proxy:     proxy.String(),
+		Valid:     err == nil,
+		Stage:     stage,
+		Sources:   proxy.Sources,
+		CheckedAt: time.Now(),
+		Err:       err,
+	}
+	if err == nil {
+		result.LatencyMs = float64(latency.Microseconds()) / 1000
+	} else {
+		result.Reason = reason.Of(err)
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Options mengatur Checker. Nilai nol memakai default.
+type Options struct {
+	// Timeout untuk satu request tes, termasuk connect ke proxy. Default 10 detik.
+	Timeout time.Duration
+	// DialTimeout untuk connect TCP ke proxy. Default 5 detik.
+	DialTimeout time.Duration
+	// TestURLs dicoba berurutan; proxy valid jika Required di antaranya
+	// berhasil. Default DefaultTestURLs.
+	TestURLs []string
+	// Required adalah jumlah URL tes yang harus berhasil. Default 1.
+	Required int
+	// ValidBody memeriksa body response 200 dari URL tes. Default body harus
+	// memuat alamat IP, cocok untuk URL tes yang mengembalikan IP pemanggil.
+	ValidBody func(body []byte) bool
+	// UserAgent untuk request tes. Default DefaultUserAgent.
+	UserAgent string
+	// RateLimit membatasi request tes per proxy, per host URL tes dan
+	// global; nil berarti tanpa batas. Waktu tunggu tidak dihitung sebagai
+	// latency.
+	RateLimit *ratelimit.Limiter
+	// Judge adalah URL yang memantulkan header request, misalnya
+	// DefaultJudge. Jika Judge dan RealIP diisi, proxy yang valid diambilkan
+	// Judge sekali lagi untuk mengisi Result.Anonymity.
+	Judge string
+	// RealIP adalah IP publik kita; lihat PublicIP.
+	RealIP string
+}
+
+// Checker menjalankan pengecekan HTTP terhadap proxy.
+type Checker struct {
+	opts Options
+}
+
+// New membuat Checker dengan opts.
+func New(opts Options) *Checker {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if len(opts.TestURLs) == 0 {
+		opts.TestURLs = DefaultTestURLs
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+	opts.Required = min(max(opts.Required, 1), len(opts.TestURLs))
+	if opts.ValidBody == nil {
+		opts.ValidBody = containsIP
+	}
+	return &Checker{opts: opts}
+}
+
+// Timeout mengembalikan timeout request tes yang berlaku.
+func (c *Checker) Timeout() time.Duration { return c.opts.Timeout }
+
+// Check menjalankan tahap HTTP untuk satu proxy. Jika URL tes yang berhasil
+// kurang dari Required, error terakhir menentukan alasan kegagalan di Result.
+func (c *Checker) Check(ctx context.Context, proxy parse.Proxy) Result {
+	start := time.Now()
+	var waited time.Duration
+	var lastErr error
+	passed := 0
+	for i, testURL := range c.opts.TestURLs {
+		d, err := c.opts.RateLimit.Wait(ctx, proxy.Key(), testHost(testURL))
+		waited += d
+		if err != nil {
+			lastErr = err
+			break
+		}
+		err = c.fetch(ctx, proxy, testURL)
+		if err == nil {
+			passed++
+			if passed >= c.opts.Required {
+				break
+			}
+			continue
+		}
+		lastErr = err
+
+		// Kalau proxy sendiri tidak bisa dihubungi, target lain pasti gagal
+		// juga; berhenti juga jika sisa URL tes tidak lagi cukup
+		remaining := len(c.opts.TestURLs) - i - 1
+		if isProxyLevelError(err) || passed+remaining < c.opts.Required {
+			break
+		}
+	}
+	if passed >= c.opts.Required {
+		lastErr = nil
+	}
+	result := NewResult(proxy, StageHTTP, lastErr, time.Since(start)-waited)
+	if result.Valid && c.opts.Judge != "" && c.opts.RealIP != "" {
+		result.Anonymity = c.anonymity(ctx, proxy)
+	}
+	return result
+}
+
+// anonymity mengambil Judge lewat proxy. Kegagalan di sini tidak membuat
+// proxy tidak valid; anonimitasnya saja yang tidak diketahui.
+func (c *Checker) anonymity(ctx context.Context, proxy parse.Proxy) string {
+	if _, err := c.opts.RateLimit.Wait(ctx, proxy.Key(), testHost(c.opts.Judge)); err != nil {
+		return ""
+	}
+	body, err := c.get(ctx, proxy, c.opts.Judge)
+	if err != nil {
+		return ""
+	}
+	return Anonymity(body, c.opts.RealIP)
+}
+
+// testHost mengambil host dari URL tes untuk batas per host.
+func testHost(testURL string) string {
+	u, err := url.Parse(testURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// CheckAll mengecek proxies dengan paling banyak workers pengecekan
+// bersamaan. Result dikembalikan dengan urutan yang sama dengan proxies.
+func (c *Checker) CheckAll(ctx context.Context, proxies []parse.Proxy, workers int) []Result {
+	results := make([]Result, len(proxies))
+	sem := make(chan struct{}, max(1, workers))
+	var wg sync.WaitGroup
+	for i, proxy := range proxies {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, proxy parse.Proxy) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.Check(ctx, proxy)
+		}(i, proxy)
+	}
+	wg.Wait()
+	return results
+}
+
+// Prescreen menjalankan tahap pre-screen TCP. Result dikembalikan dengan
+// urutan yang sama dengan proxies: Valid berarti proxy lolos ke tahap HTTP,
+// selain itu portnya tidak bisa dihubungi.
+func Prescreen(ctx context.Context, proxies []parse.Proxy, cfg prescreen.Config) ([]Result, prescreen.Stats) {
+	addrs := make([]string, len(proxies))
+	for i, proxy := range proxies {
+		addrs[i] = proxy.Addr()
+	}
+
+	screened, stats := prescreen.Screen(ctx, addrs, cfg)
+
+	results := make([]Result, len(proxies))
+	for i, proxy := range proxies {
+		var err error
+		if !screened[i].Passed {
+			err = screened[i].Err
+		}
+		results[i] = NewResult(proxy, StageTCP, err, 0)
+	}
+	return results, stats
+}
+
+// isProxyLevelError melaporkan apakah err berarti proxy-nya sendiri yang
+// bermasalah (gagal connect atau timeout), bukan target tes tertentu.
+func isProxyLevelError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && (opErr.Op == "proxyconnect" || opErr.Op == "dial") {
+		return true
+	}
+	return limiter.Classify(err) == limiter.Timeout
+}
+
+func (c *Checker) fetch(ctx context.Context, proxy parse.Proxy, testURL string) error {
+	body, err := c.get(ctx, proxy, testURL)
+	if err != nil {
+		return err
+	}
+	// Body kosong, halaman captcha, atau isi yang diganti proxy (menurut
+	// ValidBody) berarti proxy tidak benar-benar meneruskan request
+	if r := reason.FromBody(body, c.opts.ValidBody); r != reason.None {
+		return reason.Errorf(r, "unexpected body from %s", testURL)
+	}
+	return nil
+}
+
+// get mengambil testURL lewat proxy dan mengembalikan body response 200.
+func (c *Checker) get(ctx context.Context, proxy parse.Proxy, testURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	// Kredensial di URL dikirim oleh transport sebagai Proxy-Authorization
+	// (HTTP) atau autentikasi username/password (SOCKS5)
+	transport := &http.Transport{
+		Proxy: http.ProxyURL(proxy.URL()),
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			d := net.Dialer{Timeout: c.opts.DialTimeout}
+			return d.DialContext(ctx, network, addr)
+		},
+		// Koneksi tidak dipakai ulang; tanpa ini setiap transport
+		// meninggalkan socket idle dan file descriptor cepat habis
+		DisableKeepAlives: true,
+	}
+	defer transport.CloseIdleConnections()
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   c.opts.Timeout,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", testURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, reason.Errorf(reason.FromStatus(resp.StatusCode), "HTTP %d", resp.StatusCode)
+	}
+
+	// Baca response untuk memastikan proxy bekerja
+	return io.ReadAll(resp.Body)
+}
+
+// containsIP melaporkan apakah body memuat setidaknya satu alamat IP.
+func containsIP(body []byte) bool {
+	return len(ips(body)) > 0
+}
+
+// ips mengembalikan semua alamat IP di body dalam bentuk kanonik.
+func ips(body []byte) []string {
+	fields := strings.FieldsFunc(string(body), func(r rune) bool {
+		return !(r == '.' || r == ':' || (r >= '0' && r <= '9') ||
+			(r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F'))
+	})
+	var found []string
+	for _, field := range fields {
+		if ip := net.ParseIP(field); ip != nil {
+			found = append(found, ip.String())
+		}
+	}
+	return found
+}
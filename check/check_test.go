@@ -0,0 +1,117 @@
+package check
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"deep.go/parse"
+	"deep.go/reason"
+)
+
+// forwardProxy adalah proxy HTTP minimal yang meneruskan request absolut.
+func forwardProxy(t *testing.T) parse.Proxy {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(srv.Close)
+	p, _ := parse.Token(srv.Listener.Addr().String())
+	return p
+}
+
+func target(t *testing.T, status int, body string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestCheckRequired(t *testing.T) {
+	proxy := forwardProxy(t)
+	ip, html, broken := target(t, 200, "203.0.113.7"), target(t, 200, "<html>halo</html>"), target(t, 500, "")
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantValid bool
+		wantWhy   reason.Reason
+	}{
+		{"satu cukup", Options{TestURLs: []string{broken, ip}}, true, reason.None},
+		{"body tanpa IP", Options{TestURLs: []string{html}}, false, reason.TamperedBody},
+		{"body bebas", Options{TestURLs: []string{html}, ValidBody: func([]byte) bool { return true }}, true, reason.None},
+		{"dua dari tiga", Options{TestURLs: []string{ip, broken, ip}, Required: 2}, true, reason.None},
+		{"dua dari tiga gagal", Options{TestURLs: []string{broken, ip, broken}, Required: 2}, false, reason.FromStatus(500)},
+		{"required melebihi URL", Options{TestURLs: []string{ip}, Required: 5}, true, reason.None},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Timeout = 5 * time.Second
+			result := New(tt.opts).Check(context.Background(), proxy)
+			if result.Valid != tt.wantValid || result.Reason != tt.wantWhy {
+				t.Errorf("Check = valid %v alasan %q (%s), ingin %v %q", result.Valid, result.Reason, result.Error, tt.wantValid, tt.wantWhy)
+			}
+		})
+	}
+}
+
+func TestAnonymity(t *testing.T) {
+	const real = "198.51.100.7"
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"elite", `{"headers": {"Host": "httpbin.org"}, "origin": "203.0.113.9"}`, Elite},
+		{"via", `{"headers": {"Via": "1.1 squid"}, "origin": "203.0.113.9"}`, Anonymous},
+		{"forwarded tanpa IP asli", `{"headers": {"X-Forwarded-For": "10.0.0.1"}, "origin": "203.0.113.9"}`, Anonymous},
+		{"IP asli di origin", `{"origin": "198.51.100.7, 203.0.113.9"}`, Transparent},
+		{"azenv", "HTTP_HOST = judge\nHTTP_X_FORWARDED_FOR = 198.51.100.7\nREMOTE_ADDR = 203.0.113.9", Transparent},
+		{"azenv via", "HTTP_VIA = 1.1 proxy\nREMOTE_ADDR = 203.0.113.9", Anonymous},
+		{"IP mirip", `{"origin": "198.51.100.70"}`, Elite},
+	}
+	for _, tt := range tests {
+		if got := Anonymity([]byte(tt.body), real); got != tt.want {
+			t.Errorf("%s: Anonymity = %q, ingin %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckJudge(t *testing.T) {
+	proxy := forwardProxy(t)
+	ip := target(t, 200, "203.0.113.7")
+	judge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"headers": {"Host": "judge"}, "origin": "`+r.Header.Get("X-Origin")+`"}`)
+	}))
+	defer judge.Close()
+
+	realIP, err := PublicIP(context.Background(), ip)
+	if err != nil || realIP != "203.0.113.7" {
+		t.Fatalf("PublicIP = %q %v", realIP, err)
+	}
+
+	// forwardProxy tidak menambah header apa pun sehingga dianggap elite
+	c := New(Options{Timeout: 5 * time.Second, TestURLs: []string{ip}, Judge: judge.URL, RealIP: "198.51.100.7"})
+	if result := c.Check(context.Background(), proxy); !result.Valid || result.Anonymity != Elite {
+		t.Errorf("Check = valid %v anonimitas %q, ingin elite", result.Valid, result.Anonymity)
+	}
+	// Tanpa RealIP judge tidak dipakai
+	c = New(Options{Timeout: 5 * time.Second, TestURLs: []string{ip}, Judge: judge.URL})
+	if result := c.Check(context.Background(), proxy); result.Anonymity != "" {
+		t.Errorf("Anonymity tanpa RealIP = %q", result.Anonymity)
+	}
+}
@@ -0,0 +1,36 @@
+package check_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"deep.go/check"
+	"deep.go/parse"
+	"deep.go/pool"
+	"deep.go/prescreen"
+)
+
+// Pre-screen TCP lalu pengecekan HTTP, dan proxy yang valid dimasukkan ke pool.
+func Example() {
+	proxies := parse.Text("1.2.3.4:8080\nsocks5://5.6.7.8:1080")
+	ctx := context.Background()
+
+	var survivors []parse.Proxy
+	screened, _ := check.Prescreen(ctx, proxies, prescreen.Config{Timeout: 2 * time.Second, MaxConcurrency: 100})
+	for i, r := range screened {
+		if r.Valid {
+			survivors = append(survivors, proxies[i])
+		}
+	}
+
+	checker := check.New(check.Options{Timeout: 5 * time.Second})
+	live := pool.New(pool.Options{Timeout: checker.Timeout()})
+	for i, r := range checker.CheckAll(ctx, survivors, 50) {
+		live.AddResult(survivors[i], r)
+		if !r.Valid {
+			fmt.Println(r.Proxy, r.Reason)
+		}
+	}
+	fmt.Println(live.Len(), "proxy valid")
+}
@@ -1,353 +1,1336 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
-	"net/url"
 	"os"
-	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"deep.go/check"
+	"deep.go/export"
+	"deep.go/gateway"
+	"deep.go/health"
+	"deep.go/input"
+	"deep.go/limiter"
+	"deep.go/logging"
+	"deep.go/metrics"
+	"deep.go/netfilter"
+	"deep.go/notify"
+	"deep.go/parse"
+	"deep.go/pool"
+	"deep.go/prescreen"
+	"deep.go/progress"
+	"deep.go/publish"
+	"deep.go/ratelimit"
+	"deep.go/reason"
+	"deep.go/report"
+	"deep.go/sourcecache"
+	"deep.go/sources"
 )
 
-type ProxySource struct {
-	Name string
-	URL  string
-}
+// Tahap pengecekan yang dicatat di check.Result
+const (
+	stageTCP  = check.StageTCP
+	stageHTTP = check.StageHTTP
+)
 
-type Proxy struct {
-	IP   string
-	Port string
-	Full string
-}
+const checkResultsFile = "check_results.jsonl"
 
+// ProxyChecker menjalankan check.Checker untuk satu run dan mencatat hasil,
+// statistik per tahap, metrik, dan progres.
 type ProxyChecker struct {
-	timeout       time.Duration
-	maxWorkers    int
-	validProxies  []Proxy
-	invalidProxies []Proxy
-	mu            sync.RWMutex
-	wg            sync.WaitGroup
-}
-
-// Daftar sumber proxy gratis
-var proxySources = []ProxySource{
-	{"ProxyList-1", "https://raw.githubusercontent.com/TheSpeedX/PROXY-List/master/http.txt"},
-	{"ProxyList-2", "https://raw.githubusercontent.com/monosans/proxy-list/main/proxies/http.txt"},
-	{"ProxyList-3", "https://raw.githubusercontent.com/clarketm/proxy-list/master/proxy-list-raw.txt"},
-	{"ProxyList-4", "https://raw.githubusercontent.com/sunny9577/proxy-scraper/master/proxies.txt"},
-	{"ProxyList-5", "https://raw.githubusercontent.com/ShiftyTR/Proxy-List/master/http.txt"},
-	{"ProxyList-6", "https://raw.githubusercontent.com/roosterkid/openproxylist/main/HTTPS_RAW.txt"},
-	{"ProxyList-7", "https://raw.githubusercontent.com/mmpx12/proxy-list/master/http.txt"},
-	{"ProxyList-8", "https://raw.githubusercontent.com/proxy4parsing/proxy-list/main/http.txt"},
-}
-
-func NewProxyChecker(timeout time.Duration, maxWorkers int) *ProxyChecker {
+	checker        *check.Checker
+	limiter        *limiter.Limiter
+	validProxies   []parse.Proxy
+	invalidProxies []parse.Proxy
+	mu             sync.RWMutex
+	wg             sync.WaitGroup
+
+	// Statistik per tahap pengecekan
+	prescreenStats prescreen.Stats
+	httpChecked    int
+	httpEliminated int
+
+	results []check.Result
+	reasons reason.Counter
+
+	// progress menampilkan kemajuan tahap HTTP dan baris per proxy (verbose)
+	progress *progress.Tracker
+}
+
+func NewProxyChecker(opts check.Options, lim *limiter.Limiter) *ProxyChecker {
 	return &ProxyChecker{
-		timeout:    timeout,
-		maxWorkers: maxWorkers,
+		checker: check.New(opts),
+		limiter: lim,
 	}
 }
 
 func main() {
-	fmt.Println("🚀 Memulai Proxy Scraper dan Validator")
-	fmt.Println("=====================================")
+	// Subcommand; tanpa subcommand jalankan scrape dan validasi penuh
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sources":
+			runSourcesCommand(os.Args[2:])
+			return
+		case "check":
+			runCheckCommand(os.Args[2:])
+			return
+		case "gateway":
+			runGatewayCommand(os.Args[2:])
+			return
+		}
+	}
+
+	checkOpts := addCheckFlags(flag.CommandLine)
+	healthFile := flag.String("health-file", defaultHealthFile, "file statistik kesehatan sumber antar run")
+	allSources := flag.Bool("all-sources", false, "ambil juga sumber yang dinonaktifkan otomatis")
+	minValidRate := flag.Float64("min-valid-rate", 0, "nonaktifkan sumber dengan tingkat valid di bawah nilai ini (0-1)")
+	cacheDir := flag.String("cache-dir", ".source_cache", "direktori cache daftar proxy dari sumber")
+	offline := flag.Bool("offline", false, "hanya pakai salinan sumber di cache, tanpa akses jaringan")
+	flag.Parse()
+
+	tracker, human := checkOpts.setupOutput(os.Stdout, os.Stderr)
+	logging.Info(logging.RunStart)
+
+	pipe, err := checkOpts.newPipeline(tracker, human)
+	if err != nil {
+		logging.Fatal(logging.ConfigInvalid, logging.KeyError, err)
+	}
+	checker := pipe.checker
+
+	store, err := health.Load(*healthFile)
+	if err != nil {
+		logging.Fatal(logging.HealthLoadFail, logging.KeyFile, *healthFile, logging.KeyError, err)
+	}
+	threshold := health.DefaultThreshold
+	threshold.MinValidRate = *minValidRate
 
-	checker := NewProxyChecker(10*time.Second, 100) // 10 detik timeout, 100 goroutines
-	
-	// Scrape proxies dari semua sumber
-	allProxies := scrapeAllProxies()
+	cache, err := sourcecache.New(*cacheDir)
+	if err != nil {
+		logging.Fatal(logging.CacheOpenFail, logging.KeyFile, *cacheDir, logging.KeyError, err)
+	}
+	scraper := sources.NewScraper(cache, *offline)
+
+	// Scrape proxies dari semua sumber yang masih aktif
+	active := activeSources(store, threshold, *allSources)
+	allProxies, sourceRuns := scrapeAll(scraper, active)
+	for _, source := range active {
+		if run, ok := sourceRuns[source.Name]; ok {
+			pipe.progress.Source(source.Name, sourceStatus(run))
+		}
+	}
 	if len(allProxies) == 0 {
-		log.Fatal("❌ Tidak ada proxy yang berhasil di-scrape")
+		logging.Fatal(logging.ScrapeNone)
 	}
 
-	fmt.Printf("📊 Total proxy yang ditemukan: %d\n", len(allProxies))
-	fmt.Println("🔍 Memulai pengecekan proxy...")
-	fmt.Println("=====================================")
+	allProxies = pipe.prepare(allProxies)
+	if len(allProxies) == 0 {
+		logging.Fatal(logging.NormalizeNone)
+	}
+
+	countSourceYield(allProxies, sourceRuns)
+	// Proxy dari sumber dengan skor tertinggi dicek lebih dulu
+	prioritizeProxies(allProxies, store)
 
-	// Validasi semua proxy
-	checker.validateProxies(allProxies)
+	logging.Info(logging.CheckStart, logging.KeyCount, len(allProxies))
+
+	exported := pipe.check(allProxies)
 
 	// Simpan hasil ke file
-	err := saveProxiesToFile(checker.validProxies, "valid_proxies.txt")
+	err = export.WriteFile("valid_proxies.txt", exported)
 	if err != nil {
-		log.Printf("❌ Error menyimpan proxy valid: %v", err)
+		logging.Error(logging.SaveFailed, logging.KeyFile, "valid_proxies.txt", logging.KeyError, err)
 	}
 
-	err = saveProxiesToFile(checker.invalidProxies, "invalid_proxies.txt")
+	err = export.WriteFile("invalid_proxies.txt", checker.invalidProxies)
 	if err != nil {
-		log.Printf("❌ Error menyimpan proxy invalid: %v", err)
+		logging.Error(logging.SaveFailed, logging.KeyFile, "invalid_proxies.txt", logging.KeyError, err)
 	}
 
-	// Tampilkan ringkasan
-	fmt.Println("\n=====================================")
-	fmt.Println("📊 RINGKASAN HASIL")
-	fmt.Println("=====================================")
-	fmt.Printf("✅ Proxy Valid: %d\n", len(checker.validProxies))
-	fmt.Printf("❌ Proxy Invalid: %d\n", len(checker.invalidProxies))
-	fmt.Printf("📁 Proxy valid disimpan di: valid_proxies.txt\n")
-	fmt.Printf("📁 Proxy invalid disimpan di: invalid_proxies.txt\n")
-}
-
-func scrapeAllProxies() []Proxy {
-	var allProxies []Proxy
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	for _, source := range proxySources {
-		wg.Add(1)
-		go func(src ProxySource) {
-			defer wg.Done()
-			
-			fmt.Printf("🌐 Scraping dari %s...\n", src.Name)
-			proxies, err := scrapeProxiesFromURL(client, src.URL)
-			if err != nil {
-				log.Printf("❌ Error scraping dari %s: %v", src.Name, err)
-				return
+	err = export.WriteResultsFile(checkResultsFile, checker.results)
+	if err != nil {
+		logging.Error(logging.SaveFailed, logging.KeyFile, checkResultsFile, logging.KeyError, err)
+	}
+
+	// Perbarui statistik sumber dan nonaktifkan yang terus-menerus gagal.
+	// Run offline tidak menyentuh sumber sehingga tidak dicatat.
+	countSourceValidity(checker.results, sourceRuns)
+	streaks := make(map[string]int)
+	if !*offline {
+		for name, run := range sourceRuns {
+			store.Record(name, *run)
+			streaks[name] = store.FailureStreak(name)
+		}
+		for _, name := range store.Evaluate(threshold) {
+			src := store.Sources[name]
+			if src.Disabled {
+				logging.Warn(logging.SourceDisabled, logging.KeySource, name, logging.KeyReason, src.DisabledReason.String())
+			} else {
+				logging.Info(logging.SourceEnabled, logging.KeySource, name)
 			}
+		}
+		if err := store.Save(); err != nil {
+			logging.Error(logging.HealthSaveFail, logging.KeyFile, *healthFile, logging.KeyError, err)
+		}
+	}
 
-			mu.Lock()
-			allProxies = append(allProxies, proxies...)
-			mu.Unlock()
-			
-			fmt.Printf("✅ Berhasil scrape %d proxy dari %s\n", len(proxies), src.Name)
-		}(source)
+	pipe.exportMetrics()
+	run, diff := pipe.writeReport(sourceRuns)
+	pipe.notify(streaks, run, diff)
+	pipe.publish()
+
+	// Tampilkan ringkasan
+	pipe.printSummary()
+	fmt.Fprintln(pipe.out, logging.T(logging.ReportSavedValid, "valid_proxies.txt"))
+	fmt.Fprintln(pipe.out, logging.T(logging.ReportSavedBad, "invalid_proxies.txt"))
+	fmt.Fprintln(pipe.out, logging.T(logging.ReportSavedDetail, checkResultsFile))
+}
+
+const defaultHealthFile = "source_health.json"
+
+// Metrik run. Dilayani lewat -metrics-addr selama run berjalan, atau
+// ditulis di akhir run lewat -metrics-file dan -metrics-push.
+var (
+	metricsRegistry = metrics.NewRegistry()
+
+	scrapedProxies = metricsRegistry.NewCounter("proxyscraper_scraped_proxies_total",
+		"Proxy mentah yang diambil per sumber atau input.", "source")
+	sourceFetches = metricsRegistry.NewCounter("proxyscraper_source_fetches_total",
+		"Pengambilan sumber per status: fresh, not_modified, stale, offline atau error.", "source", "status")
+	checksTotal = metricsRegistry.NewCounter("proxyscraper_checks_total",
+		"Proxy yang selesai dicek per tahap dan hasil.", "stage", "outcome")
+	checkFailures = metricsRegistry.NewCounter("proxyscraper_check_failures_total",
+		"Kegagalan pengecekan per tahap dan alasan.", "stage", "reason")
+	checkLatency = metricsRegistry.NewHistogram("proxyscraper_check_latency_seconds",
+		"Durasi pengecekan HTTP per proxy.", metrics.DefaultBuckets, "outcome")
+	poolSize = metricsRegistry.NewGauge("proxyscraper_pool_size",
+		"Proxy valid yang diekspor per protokol dan negara.", "protocol", "country")
+	rateLimitWait = metricsRegistry.NewHistogram("proxyscraper_ratelimit_wait_seconds",
+		"Waktu tunggu request karena batas laju per cakupan: proxy, host atau global.",
+		[]float64{0.01, 0.05, 0.1, 0.5, 1, 5, 30}, "scope")
+	gatewayUpstreamRequests = metricsRegistry.NewCounter("proxyscraper_gateway_upstream_requests_total",
+		"Request gateway per upstream yang dicoba, per hasil: success atau failure.", "outcome")
+)
+
+// loadRateLimits membuat pembatas laju dari file -rate-limits, atau nil
+// jika path kosong. Setiap waktu tunggu dicatat di rateLimitWait.
+func loadRateLimits(path string) (*ratelimit.Limiter, error) {
+	if path == "" {
+		return nil, nil
+	}
+	cfg, err := ratelimit.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca -rate-limits: %w", err)
 	}
+	cfg.OnWait = func(scope string, waited time.Duration) {
+		rateLimitWait.With(scope).Observe(waited.Seconds())
+	}
+	return ratelimit.New(cfg), nil
+}
 
-	wg.Wait()
-	
-	// Hapus duplikat
-	uniqueProxies := removeDuplicateProxies(allProxies)
-	fmt.Printf("🧹 Setelah menghapus duplikat: %d proxy\n", len(uniqueProxies))
-	
-	return uniqueProxies
+// serveMetrics melayani /metrics di addr selama proses berjalan.
+func serveMetrics(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("gagal membuka endpoint metrik: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsRegistry.Handler())
+	endpoint := fmt.Sprintf("http://%s/metrics", ln.Addr())
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			logging.Error(logging.MetricsFail, "url", endpoint, logging.KeyError, err)
+		}
+	}()
+	logging.Info(logging.MetricsServe, "url", endpoint)
+	return nil
 }
 
-func scrapeProxiesFromURL(client *http.Client, url string) ([]Proxy, error) {
-	// Retry mechanism dengan double check
-	var resp *http.Response
-	var err error
-	
-	for i := 0; i < 3; i++ { // 3 kali percobaan
-		resp, err = client.Get(url)
-		if err == nil && resp.StatusCode == 200 {
-			break
+// registerLimiterMetrics mengekspos saturasi worker dari statistik limiter.
+func registerLimiterMetrics(lim *limiter.Limiter) {
+	stat := func(field func(limiter.Stats) int) func() float64 {
+		return func() float64 { return float64(field(lim.Stats())) }
+	}
+	metricsRegistry.NewGaugeFunc("proxyscraper_worker_limit",
+		"Batas konkurensi pengecekan saat ini.", stat(func(s limiter.Stats) int { return s.Limit }))
+	metricsRegistry.NewGaugeFunc("proxyscraper_workers_in_flight",
+		"Pengecekan yang sedang berjalan.", stat(func(s limiter.Stats) int { return s.InFlight }))
+	metricsRegistry.NewCounterFunc("proxyscraper_worker_backoffs_total",
+		"Berapa kali limiter menurunkan konkurensi.", stat(func(s limiter.Stats) int { return s.Backoffs }))
+	metricsRegistry.NewCounterFunc("proxyscraper_worker_saturated_total",
+		"Pengecekan yang gagal karena sumber daya lokal jenuh (fd, port).", stat(func(s limiter.Stats) int { return s.Saturated }))
+}
+
+// checkFlags adalah flag pengecekan yang sama untuk run penuh dan subcommand check.
+type checkFlags struct {
+	minWorkers       *int
+	maxWorkers       *int
+	prescreenTimeout *time.Duration
+	prescreenWorkers *int
+	resolve          *bool
+	bogonFile        *string
+	noBogonFilter    *bool
+	allowFiles       stringList
+	denyFiles        stringList
+	asnDBFile        *string
+	rateLimits       *string
+	judge            *string
+	metricsAddr      *string
+	metricsFile      *string
+	metricsPush      *string
+	reportDir        *string
+	notifyWebhooks   stringList
+	notifySMTP       *string
+	notifyFrom       *string
+	notifyTo         *string
+	notifyCommand    *string
+	notifyMinPool    *int
+	notifyStreak     *int
+	notifyMinScore   *float64
+	publishRedis     *string
+	publishRedisKey  *string
+	publishRedisTTL  *time.Duration
+	publishDir       *string
+	publishName      *string
+	quiet            *bool
+	verbose          *bool
+	log              *logging.Flags
+}
+
+func addCheckFlags(fs *flag.FlagSet) *checkFlags {
+	f := &checkFlags{}
+	f.minWorkers = fs.Int("min-workers", 10, "jumlah pengecekan bersamaan minimum")
+	f.maxWorkers = fs.Int("max-workers", 500, "jumlah pengecekan bersamaan maksimum")
+	f.prescreenTimeout = fs.Duration("prescreen-timeout", 2*time.Second, "timeout connect TCP pada tahap pre-screen")
+	f.prescreenWorkers = fs.Int("prescreen-workers", 2000, "jumlah dial TCP bersamaan maksimum pada tahap pre-screen")
+	f.resolve = fs.Bool("resolve", false, "resolve hostname proxy ke IP dan hapus duplikat berdasarkan IP")
+	f.bogonFile = fs.String("bogon-file", "", "file deny-list CIDR pengganti daftar bogon bawaan")
+	f.noBogonFilter = fs.Bool("no-bogon-filter", false, "jangan buang alamat private/loopback/reserved")
+	fs.Var(&f.allowFiles, "allow", "file allow-list CIDR/ASN/negara (boleh diulang)")
+	fs.Var(&f.denyFiles, "deny", "file deny-list CIDR/ASN/negara, termasuk FireHOL dan Spamhaus DROP (boleh diulang)")
+	f.asnDBFile = fs.String("ip2asn", "", "database ip2asn TSV (boleh .gz) untuk aturan ASN dan negara")
+	f.rateLimits = fs.String("rate-limits", "", "file JSON batas laju request tes per proxy, per host URL tes dan global")
+	f.judge = fs.String("judge", check.DefaultJudge, "URL judge yang memantulkan header request untuk mendeteksi anonimitas proxy valid (kosong untuk mematikan)")
+	f.metricsAddr = fs.String("metrics-addr", "", "layani /metrics Prometheus di alamat ini selama run, misalnya :9108")
+	f.metricsFile = fs.String("metrics-file", "", "tulis metrik di akhir run ke file ini (textfile collector node_exporter)")
+	f.metricsPush = fs.String("metrics-push", "", "URL Pushgateway untuk mengirim metrik di akhir run")
+	f.reportDir = fs.String("report-dir", "", "direktori laporan run Markdown/HTML dan snapshot untuk diff antar run, misalnya reports (kosong untuk mematikan)")
+	fs.Var(&f.notifyWebhooks, "notify-webhook", "URL webhook yang menerima event notifikasi sebagai JSON (boleh diulang)")
+	f.notifySMTP = fs.String("notify-smtp", "", "server SMTP host:port untuk notifikasi email; kredensial dari PROXYSCRAPER_SMTP_USER dan PROXYSCRAPER_SMTP_PASSWORD")
+	f.notifyFrom = fs.String("notify-from", "proxyscraper@localhost", "alamat pengirim email notifikasi")
+	f.notifyTo = fs.String("notify-to", "", "alamat penerima email notifikasi, dipisah koma")
+	f.notifyCommand = fs.String("notify-command", "", "perintah shell yang dijalankan per event; event JSON dikirim lewat stdin")
+	f.notifyMinPool = fs.Int("notify-min-pool", 0, "kirim peringatan jika proxy valid kurang dari jumlah ini (0 untuk mematikan)")
+	f.notifyStreak = fs.Int("notify-failure-streak", 3, "peringatkan sumber yang gagal menghasilkan proxy valid sebanyak ini run berturut-turut (0 untuk mematikan)")
+	f.notifyMinScore = fs.Float64("notify-min-score", 0, "kirim event untuk proxy baru dengan skor kecepatan 0-1 minimal nilai ini; butuh -report-dir (0 untuk mematikan)")
+	f.publishRedis = fs.String("publish-redis", "", "terbitkan pool valid ke sorted set Redis, misalnya redis://:password@localhost:6379/0")
+	f.publishRedisKey = fs.String("publish-redis-key", "proxyscraper:pool", "key sorted set Redis untuk -publish-redis")
+	f.publishRedisTTL = fs.Duration("publish-redis-ttl", 2*time.Hour, "TTL key Redis agar pool yang tidak diperbarui kedaluwarsa (0 untuk tanpa TTL)")
+	f.publishDir = fs.String("publish-dir", "", "terbitkan pool valid sebagai <nama>-<waktu>.txt, <nama>.txt dan manifest <nama>.json di direktori ini")
+	f.publishName = fs.String("publish-name", "pool", "nama dasar file untuk -publish-dir")
+	f.quiet = fs.Bool("quiet", false, "jangan tampilkan progres pengecekan")
+	f.verbose = fs.Bool("verbose", false, "tampilkan juga satu baris per proxy yang dicek")
+	f.log = logging.AddFlags(fs)
+	return f
+}
+
+// setupOutput memasang logger yang menulis ke out dan mengembalikan
+// tampilan progres beserta writer untuk ringkasan yang dibaca manusia. Log
+// text ditulis lewat tampilan progres di out. Log json hanya menempati out
+// agar bisa di-parse: progres dan ringkasan pindah ke side, atau dibuang
+// jika side nil. Subcommand check memakai stderr sebagai out karena stdout
+// dicadangkan untuk hasil.
+func (f *checkFlags) setupOutput(out, side io.Writer) (*progress.Tracker, io.Writer) {
+	level := progress.LevelFromFlags(*f.quiet, *f.verbose)
+	logOut, human := out, out
+	if *f.log.Format == "json" {
+		human = side
+		if side == nil {
+			human, level = io.Discard, progress.Quiet
 		}
-		if resp != nil {
-			resp.Body.Close()
+	}
+	tracker := progress.New(human, level)
+	if logOut == human {
+		logOut = tracker
+	}
+	if err := f.log.Setup(logOut, *f.quiet, *f.verbose); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+	return tracker, human
+}
+
+// pipeline menjalankan normalisasi dan kedua tahap pengecekan dengan
+// konfigurasi yang sama, apa pun asal proxy-nya.
+type pipeline struct {
+	opts     *checkFlags
+	checker  *ProxyChecker
+	limiter  *limiter.Limiter
+	deny     *netfilter.List
+	policy   *netfilter.Policy
+	progress *progress.Tracker
+	// out menerima ringkasan untuk dibaca manusia
+	out io.Writer
+
+	notifier   *notify.Notifier
+	publishers []publish.Sink
+	started    time.Time
+
+	// Jumlah proxy per tahap untuk funnel di laporan run
+	scraped  int
+	prepared int
+	// pool berisi proxy yang diekspor beserta skornya
+	pool *pool.Pool
+}
+
+func (f *checkFlags) newPipeline(tracker *progress.Tracker, out io.Writer) (*pipeline, error) {
+	// Normalisasi alamat dan buang bogon sebelum pengecekan apa pun
+	deny := netfilter.Bogons()
+	if *f.bogonFile != "" {
+		var err error
+		deny, err = netfilter.LoadFile(*f.bogonFile, "deny-list")
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca deny-list: %w", err)
 		}
-		time.Sleep(time.Duration(i+1) * time.Second) // Backoff
 	}
-	
+	if *f.noBogonFilter {
+		deny = nil
+	}
+	policy, err := loadPolicy(f.allowFiles, f.denyFiles, *f.asnDBFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch after 3 attempts: %w", err)
+		return nil, fmt.Errorf("gagal memuat allow/deny list: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	// Konkurensi dimulai rendah lalu naik selama error dan sumber daya lokal sehat
+	lim := limiter.New(limiter.Config{Min: *f.minWorkers, Max: *f.maxWorkers})
+	registerLimiterMetrics(lim)
+
+	rateLimits, err := loadRateLimits(*f.rateLimits)
+	if err != nil {
+		return nil, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	if *f.metricsAddr != "" {
+		if err := serveMetrics(*f.metricsAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	publishers, err := f.newPublishers()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	return parseProxies(string(body)), nil
-}
-
-func parseProxies(content string) []Proxy {
-	var proxies []Proxy
-	
-	// Regex untuk menangkap format IP:Port
-	re := regexp.MustCompile(`(\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}):(\d{1,5})`)
-	matches := re.FindAllStringSubmatch(content, -1)
-	
-	for _, match := range matches {
-		if len(match) >= 3 {
-			ip := match[1]
-			port := match[2]
-			
-			// Validasi IP dan Port
-			if isValidIP(ip) && isValidPort(port) {
-				proxy := Proxy{
-					IP:   ip,
-					Port: port,
-					Full: fmt.Sprintf("%s:%s", ip, port),
-				}
-				proxies = append(proxies, proxy)
-			}
+		return nil, err
+	}
+
+	opts := check.Options{Timeout: 10 * time.Second, RateLimit: rateLimits} // 10 detik timeout
+	if *f.judge != "" {
+		// Anonimitas dinilai dengan membandingkan apa yang dilihat judge
+		// lewat proxy dengan IP publik kita sendiri
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		realIP, err := check.PublicIP(ctx, *f.judge)
+		cancel()
+		if err != nil {
+			logging.Warn(logging.JudgeFailed, "url", *f.judge, logging.KeyError, err)
+		} else {
+			opts.Judge, opts.RealIP = *f.judge, realIP
+		}
+	}
+	checker := NewProxyChecker(opts, lim)
+	checker.progress = tracker
+	return &pipeline{
+		opts:       f,
+		checker:    checker,
+		limiter:    lim,
+		deny:       deny,
+		policy:     policy,
+		progress:   tracker,
+		out:        out,
+		notifier:   f.newNotifier(),
+		publishers: publishers,
+		started:    time.Now(),
+	}, nil
+}
+
+// newNotifier membuat notifier dari flag -notify-*, atau nil jika tidak ada sink.
+func (f *checkFlags) newNotifier() *notify.Notifier {
+	var sinks []notify.Sink
+	for _, u := range f.notifyWebhooks {
+		sinks = append(sinks, &notify.Webhook{URL: u, Client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	if to := splitComma(*f.notifyTo); *f.notifySMTP != "" && len(to) > 0 {
+		sinks = append(sinks, &notify.SMTP{
+			Addr:     *f.notifySMTP,
+			From:     *f.notifyFrom,
+			To:       to,
+			Username: os.Getenv("PROXYSCRAPER_SMTP_USER"),
+			Password: os.Getenv("PROXYSCRAPER_SMTP_PASSWORD"),
+		})
+	}
+	if *f.notifyCommand != "" {
+		sinks = append(sinks, &notify.Command{Path: "/bin/sh", Args: []string{"-c", *f.notifyCommand}})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	return &notify.Notifier{Sinks: sinks}
+}
+
+// newPublishers membuat sink penerbitan dari flag -publish-*.
+func (f *checkFlags) newPublishers() ([]publish.Sink, error) {
+	var sinks []publish.Sink
+	if *f.publishRedis != "" {
+		redis, err := publish.ParseRedisURL(*f.publishRedis)
+		if err != nil {
+			return nil, fmt.Errorf("-publish-redis tidak valid: %w", err)
 		}
+		redis.Key = *f.publishRedisKey
+		redis.TTL = *f.publishRedisTTL
+		sinks = append(sinks, redis)
 	}
-	
+	if *f.publishDir != "" {
+		sinks = append(sinks, &publish.FileDrop{Dir: *f.publishDir, Base: *f.publishName})
+	}
+	return sinks, nil
+}
+
+// prepare me-resolve hostname (jika diminta), menormalisasi alamat, dan
+// membuang duplikat serta alamat yang ditolak deny-list atau policy.
+func (p *pipeline) prepare(proxies []parse.Proxy) []parse.Proxy {
+	p.scraped += len(proxies)
+	if *p.opts.resolve {
+		var failed int
+		proxies, failed = parse.Resolve(context.Background(), proxies, 50)
+		logging.Info(logging.Resolved, logging.KeyCount, len(proxies), "failed", failed)
+	}
+	proxies, dropped := parse.Normalize(proxies, p.deny, p.policy)
+	printDropped(p.out, logging.T(logging.StageNormalize), dropped)
+	p.prepared += len(proxies)
 	return proxies
 }
 
-func isValidIP(ip string) bool {
-	return net.ParseIP(ip) != nil
+// check menjalankan pre-screen TCP dan validasi HTTP, lalu mengembalikan
+// proxy valid yang boleh diekspor.
+func (p *pipeline) check(proxies []parse.Proxy) []parse.Proxy {
+	// Tahap 1: buang port yang tertutup dengan connect TCP singkat
+	survivors := p.checker.prescreenProxies(proxies, prescreen.Config{
+		Timeout:        *p.opts.prescreenTimeout,
+		MaxConcurrency: *p.opts.prescreenWorkers,
+	})
+	logging.Info(logging.StageDone, logging.KeyStage, stageTCP, "checked", len(proxies), "passed", len(survivors))
+
+	// Tahap 2: validasi HTTP penuh untuk proxy yang tersisa
+	p.progress.AddTotal(len(survivors))
+	p.progress.Start()
+	p.checker.validateProxies(survivors)
+	p.progress.Stop()
+
+	// Policy diterapkan lagi saat ekspor agar tidak ada proxy terlarang yang
+	// lolos ke file hasil
+	exported, exportDropped := parse.FilterPolicy(p.checker.validProxies, p.policy)
+	if len(exportDropped) > 0 {
+		printDropped(p.out, logging.T(logging.StageExportPolicy), exportDropped)
+	}
+	p.updatePoolSize(exported)
+	p.fillPool(exported)
+	return exported
 }
 
-func isValidPort(port string) bool {
-	// Port harus antara 1-65535
-	if len(port) == 0 || len(port) > 5 {
-		return false
+// fillPool mengisi pool dengan proxy yang diekspor, latency dari hasil
+// pengecekan HTTP, dan negara dari database ip2asn jika dimuat.
+func (p *pipeline) fillPool(exported []parse.Proxy) {
+	results := make(map[string]check.Result)
+	for _, result := range p.checker.results {
+		if result.Valid {
+			results[result.Proxy] = result
+		}
 	}
-	for _, char := range port {
-		if char < '0' || char > '9' {
-			return false
+	timeout := p.checker.checker.Timeout()
+	p.pool = pool.New(pool.Options{Timeout: timeout})
+	for _, proxy := range exported {
+		result := results[proxy.String()]
+		entry := pool.Entry{
+			Proxy:     proxy,
+			Score:     pool.Score(result.LatencyMs, timeout),
+			LatencyMs: result.LatencyMs,
+			Anonymity: result.Anonymity,
+			CheckedAt: result.CheckedAt,
+		}
+		if info, ok := p.lookupASN(proxy); ok {
+			entry.Country = info.Country
 		}
+		p.pool.Add(entry)
 	}
-	return true
 }
 
-func removeDuplicateProxies(proxies []Proxy) []Proxy {
-	seen := make(map[string]bool)
-	var unique []Proxy
-	
+// updatePoolSize mengisi ulang gauge ukuran pool. Negara hanya diketahui
+// jika database ip2asn dimuat.
+func (p *pipeline) updatePoolSize(proxies []parse.Proxy) {
+	poolSize.Reset()
 	for _, proxy := range proxies {
-		if !seen[proxy.Full] {
-			seen[proxy.Full] = true
-			unique = append(unique, proxy)
+		country := "unknown"
+		if info, ok := p.lookupASN(proxy); ok && info.Country != "" {
+			country = info.Country
 		}
+		poolSize.With(proxy.Protocol(), country).Inc()
 	}
-	
-	return unique
 }
 
-func (pc *ProxyChecker) validateProxies(proxies []Proxy) {
-	jobs := make(chan Proxy, len(proxies))
-	
-	// Start workers
-	for i := 0; i < pc.maxWorkers; i++ {
-		pc.wg.Add(1)
-		go pc.worker(jobs)
+// lookupASN mencari ASN dan negara proxy di database ip2asn, jika dimuat.
+func (p *pipeline) lookupASN(proxy parse.Proxy) (netfilter.ASNInfo, bool) {
+	if p.policy == nil || p.policy.DB == nil {
+		return netfilter.ASNInfo{}, false
 	}
-	
-	// Send jobs
-	for _, proxy := range proxies {
-		jobs <- proxy
+	ip, err := netfilter.ParseIP(proxy.IP)
+	if err != nil {
+		return netfilter.ASNInfo{}, false
 	}
-	close(jobs)
-	
-	// Wait for all workers to finish
-	pc.wg.Wait()
+	return p.policy.DB.Lookup(ip)
 }
 
-func (pc *ProxyChecker) worker(jobs <-chan Proxy) {
-	defer pc.wg.Done()
-	
-	for proxy := range jobs {
-		if pc.checkProxy(proxy) {
-			pc.mu.Lock()
-			pc.validProxies = append(pc.validProxies, proxy)
-			pc.mu.Unlock()
-			fmt.Printf("✅ VALID: %s\n", proxy.Full)
-		} else {
-			pc.mu.Lock()
-			pc.invalidProxies = append(pc.invalidProxies, proxy)
-			pc.mu.Unlock()
-			fmt.Printf("❌ INVALID: %s\n", proxy.Full)
+// liveProxies mengembalikan proxy di pool beserta latency, asal jaringan
+// dan anonimitasnya.
+func (p *pipeline) liveProxies() []report.Proxy {
+	var out []report.Proxy
+	for _, entry := range p.pool.Entries() {
+		live := report.Proxy{
+			Addr:      entry.Proxy.String(),
+			Sources:   entry.Proxy.Sources,
+			LatencyMs: entry.LatencyMs,
+			Country:   entry.Country,
+			Anonymity: entry.Anonymity,
+		}
+		if info, ok := p.lookupASN(entry.Proxy); ok {
+			live.ASN = fmt.Sprintf("AS%d %s", info.ASN, info.Name)
+		}
+		out = append(out, live)
+	}
+	return out
+}
+
+// writeReport menulis laporan run ke -report-dir. runs adalah statistik
+// per sumber yang sudah dihitung countSourceYield dan countSourceValidity.
+// Run dan hasil perbandingannya dikembalikan untuk notifikasi.
+func (p *pipeline) writeReport(runs map[string]*health.RunStats) (*report.Run, report.Diff) {
+	if *p.opts.reportDir == "" {
+		return nil, report.Diff{}
+	}
+	checker := p.checker
+	run := &report.Run{
+		Time: time.Now(),
+		Funnel: []report.Stage{
+			{Name: report.StageScraped, Count: p.scraped},
+			{Name: report.StageNormalized, Count: p.prepared},
+			{Name: report.StageTCP, Count: checker.prescreenStats.Total - checker.prescreenStats.Eliminated},
+			{Name: report.StageHTTP, Count: len(checker.validProxies)},
+			{Name: report.StageExported, Count: p.pool.Len()},
+		},
+		Reasons: make(map[string]int),
+	}
+	for _, e := range checker.reasons.Sorted() {
+		run.Reasons[string(e.Reason)] = e.Count
+	}
+
+	names := make([]string, 0, len(runs))
+	for name := range runs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stats := runs[name]
+		status := stats.Cache
+		if !stats.FetchOK && stats.FetchError != "" {
+			status = "error"
 		}
+		run.Sources = append(run.Sources, report.Source{
+			Name:       name,
+			Status:     status,
+			Raw:        stats.Raw,
+			Normalized: stats.Normalized,
+			Unique:     stats.Unique,
+			Checked:    stats.Checked,
+			Valid:      stats.Valid,
+		})
 	}
+
+	run.Live = p.liveProxies()
+
+	diff, paths, err := report.Write(*p.opts.reportDir, run)
+	if err != nil {
+		logging.Error(logging.ReportFailed, logging.KeyFile, *p.opts.reportDir, logging.KeyError, err)
+	}
+	for _, path := range paths {
+		logging.Info(logging.ReportWritten, logging.KeyFile, path)
+	}
+	return run, diff
 }
 
-func (pc *ProxyChecker) checkProxy(proxy Proxy) bool {
-	// Double check dengan 2 test URL berbeda
-	testURLs := []string{
-		"http://httpbin.org/ip",
-		"http://icanhazip.com",
+// notify mengirim event notifikasi untuk run ini. streaks adalah jumlah run
+// gagal berturut-turut per sumber; run dan diff berasal dari writeReport.
+func (p *pipeline) notify(streaks map[string]int, run *report.Run, diff report.Diff) {
+	if p.notifier == nil {
+		return
 	}
-	
-	successCount := 0
-	
-	for _, testURL := range testURLs {
-		if pc.testProxyConnection(proxy, testURL) {
-			successCount++
+	newProxies := make(map[string]float64)
+	if run != nil {
+		isNew := make(map[string]bool, len(diff.New))
+		for _, addr := range diff.New {
+			isNew[addr] = true
 		}
+		for _, entry := range p.pool.Entries() {
+			if addr := entry.Proxy.String(); isNew[addr] && entry.LatencyMs > 0 {
+				newProxies[addr] = entry.Score
+			}
+		}
+	}
+
+	rules := notify.Rules{
+		MinPoolSize:   *p.opts.notifyMinPool,
+		FailureStreak: *p.opts.notifyStreak,
+		MinProxyScore: *p.opts.notifyMinScore,
+	}
+	events := rules.Events(notify.Run{
+		Started:    p.started,
+		Checked:    p.prepared,
+		Valid:      p.pool.Len(),
+		Streaks:    streaks,
+		NewProxies: newProxies,
+	})
+	if err := p.notifier.Send(context.Background(), events); err != nil {
+		logging.Error(logging.NotifyFailed, logging.KeyError, err)
+		return
 	}
-	
-	// Proxy dianggap valid jika minimal 1 dari 2 test berhasil
-	return successCount >= 1
+	logging.Info(logging.NotifySent, logging.KeyCount, len(events))
 }
 
-func (pc *ProxyChecker) testProxyConnection(proxy Proxy, testURL string) bool {
-	// Context dengan timeout
-	ctx, cancel := context.WithTimeout(context.Background(), pc.timeout)
+// publish menerbitkan proxy yang diekspor ke sink -publish-*, terurut
+// menurut skor sehingga konsumen bisa langsung mengambil yang tercepat.
+func (p *pipeline) publish() {
+	if len(p.publishers) == 0 {
+		return
+	}
+	var entries []publish.Entry
+	for _, entry := range p.pool.Entries() {
+		entries = append(entries, publish.Entry{
+			Addr:      entry.Proxy.Export(),
+			Score:     entry.Score,
+			LatencyMs: entry.LatencyMs,
+			Country:   entry.Country,
+			Sources:   entry.Proxy.Sources,
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	
-	// Setup proxy client
-	proxyURL := fmt.Sprintf("http://%s", proxy.Full)
-	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy: func(*http.Request) (*url.URL, error) {
-				return url.Parse(proxyURL)
-			},
-			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-				d := net.Dialer{Timeout: 5 * time.Second}
-				return d.DialContext(ctx, network, addr)
-			},
-		},
-		Timeout: pc.timeout,
+	if err := publish.All(ctx, p.publishers, entries); err != nil {
+		logging.Error(logging.PublishFailed, logging.KeyError, err)
+		return
+	}
+	for _, sink := range p.publishers {
+		logging.Info(logging.PublishDone, "sink", sink.Name(), logging.KeyCount, len(entries))
+	}
+}
+
+// exportMetrics menulis dan mengirim metrik di akhir run jika diminta.
+func (p *pipeline) exportMetrics() {
+	if *p.opts.metricsFile != "" {
+		if err := metricsRegistry.WriteFile(*p.opts.metricsFile); err != nil {
+			logging.Error(logging.MetricsFail, logging.KeyFile, *p.opts.metricsFile, logging.KeyError, err)
+		}
+	}
+	if *p.opts.metricsPush != "" {
+		client := &http.Client{Timeout: 10 * time.Second}
+		if err := metricsRegistry.Push(context.Background(), client, *p.opts.metricsPush, "proxyscraper"); err != nil {
+			logging.Error(logging.MetricsFail, "url", *p.opts.metricsPush, logging.KeyError, err)
+		}
+	}
+}
+
+// printSummary mencetak ringkasan hasil per tahap, alasan, dan konkurensi.
+func (p *pipeline) printSummary() {
+	checker := p.checker
+	fmt.Fprintln(p.out, "\n=====================================")
+	fmt.Fprintln(p.out, logging.T(logging.ReportSummary))
+	fmt.Fprintln(p.out, "=====================================")
+	fmt.Fprintln(p.out, logging.T(logging.ReportValid, len(checker.validProxies)))
+	fmt.Fprintln(p.out, logging.T(logging.ReportInvalid, len(checker.invalidProxies)))
+	fmt.Fprintln(p.out, logging.T(logging.ReportStageTCP,
+		checker.prescreenStats.Total, checker.prescreenStats.Eliminated))
+	fmt.Fprintln(p.out, logging.T(logging.ReportStageHTTP,
+		checker.httpChecked, checker.httpEliminated))
+	if entries := checker.reasons.Sorted(); len(entries) > 0 {
+		fmt.Fprintln(p.out, logging.T(logging.ReportReasons))
+		for _, e := range entries {
+			fmt.Fprintf(p.out, "   %-20s %d\n", e.Reason, e.Count)
+		}
+	}
+	stats := p.limiter.Stats()
+	fmt.Fprintln(p.out, logging.T(logging.ReportConcurrency,
+		stats.Limit, stats.Peak, stats.Increases, stats.Backoffs))
+}
+
+// runCheckCommand menjalankan subcommand "check": mengecek proxy dari file,
+// glob, direktori, stdin ("-") atau URL dengan pipeline yang sama seperti
+// run penuh. Proxy valid ditulis ke stdout sehingga bisa dipakai sebagai
+// filter: cat list | proxyscraper check - > good.txt
+func runCheckCommand(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	checkOpts := addCheckFlags(fs)
+	output := fs.String("o", "-", "file tujuan proxy valid, \"-\" untuk stdout")
+	resultsFile := fs.String("results", "", "simpan detail hasil pengecekan (JSON per baris) ke file ini")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Penggunaan: proxyscraper check [flag] INPUT...")
+		fmt.Fprintln(fs.Output(), "INPUT boleh berupa file, glob, direktori, URL, atau \"-\" untuk stdin; file .gz/.zst didekompresi otomatis")
+		fmt.Fprintln(fs.Output(), "Input zstd memerlukan program zstd di PATH")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
 	}
-	
-	// Buat request dengan context
-	req, err := http.NewRequestWithContext(ctx, "GET", testURL, nil)
+
+	// Stdout dicadangkan untuk hasil; progres, log dan ringkasan ke stderr
+	tracker, human := checkOpts.setupOutput(os.Stderr, nil)
+	names, err := input.Expand(fs.Args())
 	if err != nil {
-		return false
-	}
-	
-	// Set header untuk menghindari deteksi bot
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	
-	// Kirim request
-	resp, err := client.Do(req)
+		logging.Fatal(logging.InputFailed, logging.KeyError, err)
+	}
+	pipe, err := checkOpts.newPipeline(tracker, human)
 	if err != nil {
-		return false
+		logging.Fatal(logging.ConfigInvalid, logging.KeyError, err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	var proxies []parse.Proxy
+	// Setiap input dicatat seperti sumber agar laporan run punya hasil per input
+	runs := make(map[string]*health.RunStats)
+	for _, name := range names {
+		item, err := input.Read(context.Background(), client, name, os.Stdin)
+		if err != nil {
+			logging.Error(logging.InputFailed, "input", name, logging.KeyError, err)
+			continue
+		}
+		label := item.Name
+		if label == input.Stdin {
+			label = "stdin"
+		}
+		parsed := parse.Text(string(item.Data))
+		for i := range parsed {
+			parsed[i].Sources = []string{label}
+		}
+		scrapedProxies.With(label).Add(float64(len(parsed)))
+		runs[label] = &health.RunStats{FetchOK: true, Raw: len(parsed)}
+		logging.Info(logging.InputRead, logging.KeySource, label, logging.KeyCount, len(parsed))
+		proxies = append(proxies, parsed...)
 	}
-	defer resp.Body.Close()
-	
-	// Check status code
-	if resp.StatusCode != 200 {
-		return false
+
+	proxies = pipe.prepare(proxies)
+	if len(proxies) == 0 {
+		logging.Fatal(logging.InputNone)
+	}
+	countSourceYield(proxies, runs)
+	exported := pipe.check(proxies)
+	countSourceValidity(pipe.checker.results, runs)
+
+	if *output == "-" {
+		err = export.Write(os.Stdout, exported)
+	} else {
+		err = export.WriteFile(*output, exported)
 	}
-	
-	// Baca response untuk memastikan proxy bekerja
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return false
+		logging.Error(logging.SaveFailed, logging.KeyFile, *output, logging.KeyError, err)
+	}
+	if *resultsFile != "" {
+		if err := export.WriteResultsFile(*resultsFile, pipe.checker.results); err != nil {
+			logging.Error(logging.SaveFailed, logging.KeyFile, *resultsFile, logging.KeyError, err)
+		}
 	}
-	
-	// Response harus mengandung IP address
-	return len(strings.TrimSpace(string(body))) > 0
+	pipe.exportMetrics()
+	run, diff := pipe.writeReport(runs)
+	pipe.notify(nil, run, diff)
+	pipe.publish()
+	pipe.printSummary()
 }
 
-func saveProxiesToFile(proxies []Proxy, filename string) error {
-	file, err := os.Create(filename)
+// runGatewayCommand menjalankan subcommand "gateway": proxy HTTP lokal yang
+// meneruskan setiap request lewat proxy dari INPUT, misalnya
+// valid_proxies.txt hasil run sebelumnya. Proxy yang terus gagal dibuang
+// dari pool selama gateway berjalan. Dengan -api-listen pool juga dilayani
+// lewat API untuk package client di proses lain.
+func runGatewayCommand(args []string) {
+	fs := flag.NewFlagSet("gateway", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:8080", "alamat proxy HTTP lokal")
+	attempts := fs.Int("max-attempts", 3, "jumlah proxy berbeda yang dicoba per request")
+	maxFailures := fs.Int("max-failures", 3, "buang proxy dari pool setelah gagal berturut-turut sebanyak ini")
+	strategyName := fs.String("strategy", "random", "strategi rotasi proxy: "+strings.Join(pool.Strategies, ", "))
+	affinityMode := fs.String("affinity", "", "ikat request ke upstream yang sama: session (header X-Proxy-Session atau username proxy) atau host (host tujuan)")
+	affinityTTL := fs.Duration("affinity-ttl", 10*time.Minute, "lama ikatan afinitas bertahan sejak terakhir dipakai")
+	apiListen := fs.String("api-listen", "", "layani API pool (/proxies, /random, /report) di alamat ini, misalnya 127.0.0.1:8081")
+	rateLimitsFile := fs.String("rate-limits", "", "file JSON batas laju request per upstream, per host tujuan dan global")
+	metricsAddr := fs.String("metrics-addr", "", "layani /metrics Prometheus di alamat ini, misalnya :9108")
+	reload := fs.Duration("reload", 5*time.Minute, "baca ulang INPUT sesering ini agar proxy yang sempat dibuang bisa kembali (0 = tidak pernah)")
+	logFlags := logging.AddFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Penggunaan: proxyscraper gateway [flag] INPUT...")
+		fmt.Fprintln(fs.Output(), "INPUT berisi daftar proxy dengan format yang sama seperti subcommand check")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := logFlags.Setup(os.Stderr, false, false); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+
+	strategy, err := pool.ParseStrategy(*strategyName)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filename, err)
+		logging.Fatal(logging.ConfigInvalid, logging.KeyError, err)
 	}
-	defer file.Close()
-	
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
-	
-	for _, proxy := range proxies {
-		_, err := writer.WriteString(proxy.Full + "\n")
+	// Ikatan yang sama dipakai gateway dan ?session= pada API pool
+	var affinity *pool.Affinity
+	affinityKey := gateway.SessionKey
+	switch *affinityMode {
+	case "":
+	case "session":
+		affinity = pool.NewAffinity(*affinityTTL)
+	case "host":
+		affinity = pool.NewAffinity(*affinityTTL)
+		affinityKey = gateway.HostKey
+	default:
+		logging.Fatal(logging.ConfigInvalid, logging.KeyError, fmt.Errorf("-affinity tidak dikenal: %q", *affinityMode))
+	}
+	rateLimits, err := loadRateLimits(*rateLimitsFile)
+	if err != nil {
+		logging.Fatal(logging.ConfigInvalid, logging.KeyError, err)
+	}
+	if *metricsAddr != "" {
+		if err := serveMetrics(*metricsAddr); err != nil {
+			logging.Fatal(logging.ConfigInvalid, logging.KeyError, err)
+		}
+	}
+	names, err := input.Expand(fs.Args())
+	if err != nil {
+		logging.Fatal(logging.InputFailed, logging.KeyError, err)
+	}
+	upstreams := pool.New(pool.Options{MaxFailures: *maxFailures})
+	client := &http.Client{Timeout: 30 * time.Second}
+	loadUpstreams(upstreams, client, names)
+	if upstreams.Len() == 0 {
+		logging.Fatal(logging.InputNone)
+	}
+	// Proxy yang dibuang setelah gagal berturut-turut bisa hidup lagi, dan
+	// file INPUT biasanya diperbarui oleh run check berikutnya. stdin hanya
+	// bisa dibaca sekali sehingga tidak ikut dibaca ulang.
+	reloadable := slices.DeleteFunc(slices.Clone(names), func(name string) bool { return name == input.Stdin })
+	if *reload > 0 && len(reloadable) > 0 {
+		go func() {
+			for range time.Tick(*reload) {
+				added := loadUpstreams(upstreams, client, reloadable)
+				logging.Info(logging.GatewayReload, "added", added, logging.KeyCount, upstreams.Len())
+			}
+		}()
+	}
+
+	srv := gateway.New(upstreams, gateway.Options{
+		MaxAttempts: *attempts,
+		Strategy:    strategy,
+		Affinity:    affinity,
+		AffinityKey: affinityKey,
+		RateLimit:   rateLimits,
+		OnUpstreamError: func(upstream parse.Proxy, err error) {
+			gatewayUpstreamRequests.With("failure").Inc()
+			logging.Debug(logging.GatewayUpstreamFail, logging.KeyProxy, upstream.String(), logging.KeyError, err)
+		},
+		OnUpstreamSuccess: func(parse.Proxy, time.Duration) {
+			gatewayUpstreamRequests.With("success").Inc()
+		},
+	})
+	if *apiListen != "" {
+		go func() {
+			if err := http.ListenAndServe(*apiListen, pool.Handler(upstreams, pool.APIOptions{Strategy: strategy, Affinity: affinity})); err != nil {
+				logging.Fatal(logging.GatewayFailed, logging.KeyError, err)
+			}
+		}()
+		logging.Info(logging.PoolAPIListen, "addr", *apiListen)
+	}
+	logging.Info(logging.GatewayListen, "addr", *listen, logging.KeyCount, upstreams.Len())
+	if err := http.ListenAndServe(*listen, srv); err != nil {
+		logging.Fatal(logging.GatewayFailed, logging.KeyError, err)
+	}
+}
+
+// loadUpstreams membaca daftar proxy dari names ke upstreams dan
+// mengembalikan jumlah proxy yang baru masuk. Proxy yang sudah ada di pool
+// tidak disentuh agar skor dan hitungan gagalnya tetap.
+func loadUpstreams(upstreams *pool.Pool, client *http.Client, names []string) int {
+	added := 0
+	for _, name := range names {
+		item, err := input.Read(context.Background(), client, name, os.Stdin)
 		if err != nil {
-			return fmt.Errorf("failed to write to file: %w", err)
+			logging.Error(logging.InputFailed, "input", name, logging.KeyError, err)
+			continue
+		}
+		proxies := parse.Dedup(parse.Text(string(item.Data)))
+		// Skor awal sama untuk semua proxy; laporan sukses dan gagal dari
+		// gateway yang kemudian membedakannya
+		for _, proxy := range proxies {
+			if _, ok := upstreams.Get(pool.KeyOf(proxy)); ok {
+				continue
+			}
+			upstreams.Add(pool.Entry{Proxy: proxy, Score: 1})
+			added++
 		}
+		logging.Info(logging.InputRead, logging.KeySource, item.Name, logging.KeyCount, len(proxies))
+	}
+	return added
+}
+
+// activeSources mengembalikan sumber yang perlu diambil pada run ini.
+func activeSources(store *health.Store, threshold health.Threshold, all bool) []sources.Source {
+	var active []sources.Source
+	for _, source := range sources.Default {
+		if all || store.ShouldFetch(source.Name, threshold) {
+			active = append(active, source)
+			continue
+		}
+		logging.Info(logging.SourceSkipped, logging.KeySource, source.Name, logging.KeyReason, store.Sources[source.Name].DisabledReason.String())
+	}
+	return active
+}
+
+// sourceStatus meringkas hasil pengambilan sumber untuk tampilan progres.
+func sourceStatus(run *health.RunStats) string {
+	switch {
+	case run.Cache == string(sourcecache.Stale):
+		return fmt.Sprintf("⚠️ %d", run.Raw)
+	case run.Cache == string(sourcecache.NotModified):
+		return fmt.Sprintf("♻️ %d", run.Raw)
+	case !run.FetchOK:
+		return "❌"
+	}
+	return fmt.Sprintf("✅ %d", run.Raw)
+}
+
+// countSourceYield menghitung jumlah proxy per sumber setelah normalisasi
+// dan berapa yang hanya disediakan oleh sumber itu.
+func countSourceYield(proxies []parse.Proxy, runs map[string]*health.RunStats) {
+	for _, proxy := range proxies {
+		for _, name := range proxy.Sources {
+			run, ok := runs[name]
+			if !ok {
+				continue
+			}
+			run.Normalized++
+			if len(proxy.Sources) == 1 {
+				run.Unique++
+			}
+		}
+	}
+}
+
+// countSourceValidity menghitung hasil pengecekan dan median latency proxy
+// valid per sumber.
+func countSourceValidity(results []check.Result, runs map[string]*health.RunStats) {
+	latencies := make(map[string][]float64)
+	for _, result := range results {
+		for _, name := range result.Sources {
+			run, ok := runs[name]
+			if !ok {
+				continue
+			}
+			run.Checked++
+			if result.Valid {
+				run.Valid++
+				latencies[name] = append(latencies[name], result.LatencyMs)
+			}
+		}
+	}
+	for name, values := range latencies {
+		runs[name].MedianLatencyMs = health.Median(values)
+	}
+}
+
+// prioritizeProxies mengurutkan proxy berdasarkan skor terbaik sumbernya
+// sehingga sumber yang jarang menghasilkan proxy valid dicek paling akhir.
+func prioritizeProxies(proxies []parse.Proxy, store *health.Store) {
+	scores := make(map[string]float64)
+	for _, summary := range store.Ranked() {
+		scores[summary.Name] = summary.Score
+	}
+	best := func(p parse.Proxy) float64 {
+		score := 0.0
+		for _, name := range p.Sources {
+			score = max(score, scores[name])
+		}
+		return score
+	}
+	sort.SliceStable(proxies, func(i, j int) bool {
+		return best(proxies[i]) > best(proxies[j])
+	})
+}
+
+// runSourcesCommand menjalankan subcommand "sources".
+func runSourcesCommand(args []string) {
+	fs := flag.NewFlagSet("sources", flag.ExitOnError)
+	healthFile := fs.String("health-file", defaultHealthFile, "file statistik kesehatan sumber")
+	logFlags := logging.AddFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Penggunaan: proxyscraper sources report [-health-file FILE] [-lang id|en]")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "report" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	fs.Parse(args[1:])
+	if err := logFlags.Setup(os.Stderr, false, false); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+
+	store, err := health.Load(*healthFile)
+	if err != nil {
+		logging.Fatal(logging.HealthLoadFail, logging.KeyFile, *healthFile, logging.KeyError, err)
+	}
+	printSourcesReport(store.Ranked())
+}
+
+// printSourcesReport mencetak peringkat sumber berdasarkan skor.
+func printSourcesReport(summaries []health.Summary) {
+	if len(summaries) == 0 {
+		fmt.Println(logging.T(logging.SourcesEmpty))
+		return
 	}
-	
+
+	upper := func(id logging.Msg) string { return strings.ToUpper(logging.T(id)) }
+	fmt.Printf("%-4s %-16s %-8s %5s %7s %7s %10s %7s %9s %s\n",
+		"#", upper(logging.RptColSource), upper(logging.RptColStatus), "RUN", "FETCH%",
+		upper(logging.RptColRaw), upper(logging.RptColNormalized), upper(logging.RptColUnique), "VALID%", "LATENCY")
+	for i, s := range summaries {
+		status := logging.T(logging.SourcesActive)
+		if s.Disabled {
+			status = logging.T(logging.SourcesInactive)
+		}
+		latency := "-"
+		if s.MedianLatencyMs > 0 {
+			latency = fmt.Sprintf("%.0fms", s.MedianLatencyMs)
+		}
+		fmt.Printf("%-4d %-16s %-8s %5d %6.0f%% %7.0f %10.0f %7.0f %8.2f%% %s\n",
+			i+1, s.Name, status, s.Runs, 100*s.FetchRate, s.AvgRaw, s.AvgNormalized,
+			s.AvgUnique, 100*s.ValidRate, latency)
+		if s.Disabled {
+			fmt.Printf("     ↳ %s\n", s.DisabledReason)
+		}
+	}
+}
+
+// printDropped mencetak jumlah proxy yang dibuang per alasan, terurut.
+func printDropped(w io.Writer, stage string, dropped map[string]int) {
+	total := 0
+	keys := make([]string, 0, len(dropped))
+	for key, n := range dropped {
+		keys = append(keys, key)
+		total += n
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintln(w, logging.T(logging.ReportDropped, stage, total))
+	for _, key := range keys {
+		fmt.Fprintf(w, "   %-28s %d\n", key, dropped[key])
+	}
+}
+
+// scrapeAll mengambil proxy dari semua sumber dan mengembalikan statistik
+// run per sumber untuk dicatat di health store.
+func scrapeAll(scraper *sources.Scraper, active []sources.Source) ([]parse.Proxy, map[string]*health.RunStats) {
+	var allProxies []parse.Proxy
+	runs := make(map[string]*health.RunStats)
+	started := time.Now()
+
+	for _, src := range active {
+		logging.Info(logging.ScrapeStart, logging.KeySource, src.Name)
+	}
+	for _, fetch := range scraper.ScrapeAll(context.Background(), active) {
+		src, result := fetch.Source, fetch.Result
+		run := &health.RunStats{Time: started}
+		runs[src.Name] = run
+		if fetch.Err != nil {
+			logging.Warn(logging.ScrapeFailed, logging.KeySource, src.Name, logging.KeyError, fetch.Err)
+			sourceFetches.With(src.Name, "error").Inc()
+			run.FetchError = fetch.Err.Error()
+			continue
+		}
+		sourceFetches.With(src.Name, string(result.Status)).Inc()
+		scrapedProxies.With(src.Name).Add(float64(len(fetch.Proxies)))
+
+		allProxies = append(allProxies, fetch.Proxies...)
+		run.FetchOK = result.Status != sourcecache.Stale
+		run.Cache = string(result.Status)
+		if result.Err != nil {
+			run.FetchError = result.Err.Error()
+		}
+		run.Raw = len(fetch.Proxies)
+
+		switch result.Status {
+		case sourcecache.Stale:
+			logging.Warn(logging.ScrapeStale, logging.KeySource, src.Name, logging.KeyError, result.Err,
+				"cached_at", result.FetchedAt.Format(time.RFC3339))
+		case sourcecache.NotModified:
+			logging.Info(logging.ScrapeCached, logging.KeySource, src.Name)
+		}
+		logging.Info(logging.ScrapeDone, logging.KeySource, src.Name, logging.KeyCount, len(fetch.Proxies))
+	}
+
+	// Hapus duplikat
+	uniqueProxies := parse.Dedup(allProxies)
+	logging.Info(logging.Deduplicated, logging.KeyCount, len(uniqueProxies))
+
+	return uniqueProxies, runs
+}
+
+// stringList adalah flag yang boleh diberikan berulang kali.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
 	return nil
-}
\ No newline at end of file
+}
+
+// splitComma memecah daftar dipisah koma, membuang spasi dan entri kosong
+// sehingga "a@x, b@y," menjadi dua alamat.
+func splitComma(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// loadPolicy memuat allow-list, deny-list dan database ASN dari file.
+func loadPolicy(allowFiles, denyFiles []string, asnDBFile string) (*netfilter.Policy, error) {
+	policy := &netfilter.Policy{}
+	var err error
+	if len(allowFiles) > 0 {
+		if policy.Allow, err = netfilter.LoadRules(allowFiles...); err != nil {
+			return nil, err
+		}
+	}
+	if len(denyFiles) > 0 {
+		if policy.Deny, err = netfilter.LoadRules(denyFiles...); err != nil {
+			return nil, err
+		}
+	}
+	if asnDBFile != "" {
+		if policy.DB, err = netfilter.LoadASNDB(asnDBFile); err != nil {
+			return nil, err
+		}
+	}
+	return policy, policy.Validate()
+}
+
+// prescreenProxies menjalankan tahap pre-screen TCP. Proxy yang portnya tidak
+// bisa dihubungi langsung dicatat sebagai invalid, sisanya dikembalikan untuk
+// pengecekan HTTP.
+func (pc *ProxyChecker) prescreenProxies(proxies []parse.Proxy, cfg prescreen.Config) []parse.Proxy {
+	results, stats := check.Prescreen(context.Background(), proxies, cfg)
+
+	var survivors []parse.Proxy
+	for i, proxy := range proxies {
+		if results[i].Valid {
+			survivors = append(survivors, proxy)
+		} else {
+			pc.recordResult(proxy, results[i], 0)
+			logging.Debug(logging.CheckInvalid, logging.KeyProxy, proxy.String(), logging.KeyStage, stageTCP,
+				logging.KeyReason, string(results[i].Reason))
+		}
+	}
+	pc.mu.Lock()
+	pc.prescreenStats = stats
+	pc.mu.Unlock()
+
+	return survivors
+}
+
+// recordResult mencatat hasil pengecekan dan menghitung alasan kegagalannya.
+func (pc *ProxyChecker) recordResult(proxy parse.Proxy, result check.Result, latency time.Duration) {
+	stage := result.Stage
+	if !result.Valid {
+		pc.reasons.Add(result.Reason)
+		checkFailures.With(stage, string(result.Reason)).Inc()
+	}
+	outcome := "valid"
+	if !result.Valid {
+		outcome = "invalid"
+	}
+	checksTotal.With(stage, outcome).Inc()
+	if stage == stageHTTP {
+		checkLatency.With(outcome).Observe(latency.Seconds())
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.results = append(pc.results, result)
+	if result.Valid {
+		pc.validProxies = append(pc.validProxies, proxy)
+	} else {
+		pc.invalidProxies = append(pc.invalidProxies, proxy)
+	}
+	if stage == stageHTTP {
+		pc.httpChecked++
+		if !result.Valid {
+			pc.httpEliminated++
+		}
+	}
+}
+
+func (pc *ProxyChecker) validateProxies(proxies []parse.Proxy) {
+	ctx := context.Background()
+
+	for _, proxy := range proxies {
+		// Tunggu slot dari limiter adaptif sebelum memulai pengecekan baru
+		if err := pc.limiter.Acquire(ctx); err != nil {
+			break
+		}
+		pc.wg.Add(1)
+		go pc.worker(proxy)
+	}
+
+	// Wait for all workers to finish
+	pc.wg.Wait()
+}
+
+// worker mengecek satu proxy. Error pengecekan dipakai limiter untuk
+// membedakan proxy mati dengan mesin kita sendiri yang kehabisan sumber daya.
+func (pc *ProxyChecker) worker(proxy parse.Proxy) {
+	defer pc.wg.Done()
+
+	start := time.Now()
+	result := pc.checker.Check(context.Background(), proxy)
+	pc.limiter.Release(limiter.Classify(result.Err))
+	latency := time.Since(start)
+	pc.recordResult(proxy, result, latency)
+
+	if result.Valid {
+		pc.progress.Done(true, "", latency)
+		logging.Debug(logging.CheckValid, logging.KeyProxy, proxy.String(), logging.KeyStage, stageHTTP,
+			logging.KeyLatency, latency.Milliseconds())
+	} else {
+		r := result.Reason
+		pc.progress.Done(false, string(r), latency)
+		logging.Debug(logging.CheckInvalid, logging.KeyProxy, proxy.String(), logging.KeyStage, stageHTTP,
+			logging.KeyReason, string(r), logging.KeyLatency, latency.Milliseconds())
+	}
+}
@@ -0,0 +1,220 @@
+// Package client memakai proxy dari pool tanpa perlu menulis ulang logika
+// "pilih proxy, coba, tandai rusak, ulangi". Transport adalah
+// http.RoundTripper dan Dialer adalah proxy.Dialer dari golang.org/x/net;
+// keduanya memilih proxy per request dengan pool.Strategy, mencoba proxy
+// lain jika gagal, dan melaporkan hasilnya ke skor kesehatan pool lewat
+// Source, baik pool lokal maupun API pool di proses lain.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+
+	"deep.go/gateway"
+	"deep.go/parse"
+	"deep.go/pool"
+)
+
+// ErrNoProxy dikembalikan jika Source tidak punya proxy yang belum dicoba.
+var ErrNoProxy = errors.New("tidak ada proxy yang tersedia")
+
+// Options mengatur Transport dan Dialer. Source wajib diisi; nilai nol
+// lainnya memakai default.
+type Options struct {
+	Source Source
+	// Strategy memilih proxy untuk setiap request. Default pool.Random.
+	Strategy pool.Strategy
+	// MaxAttempts adalah jumlah proxy berbeda yang dicoba sebelum menyerah.
+	// Default 3.
+	MaxAttempts int
+	// DialTimeout untuk membuka koneksi lewat proxy. Default 10 detik.
+	DialTimeout time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.Strategy == nil {
+		o.Strategy = pool.Random
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 3
+	}
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = 10 * time.Second
+	}
+	return o
+}
+
+// pick memilih proxy yang belum ada di tried.
+func (o Options) pick(ctx context.Context, tried map[string]bool) (parse.Proxy, error) {
+	entries, err := o.Source.Entries(ctx)
+	if err != nil {
+		return parse.Proxy{}, err
+	}
+	var candidates []pool.Entry
+	for _, e := range entries {
+		if !tried[e.Key()] {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return parse.Proxy{}, ErrNoProxy
+	}
+	return o.Strategy.Pick(candidates).Proxy, nil
+}
+
+// Transport adalah http.RoundTripper yang mengirim setiap request lewat
+// proxy dari Source.
+type Transport struct {
+	opts      Options
+	transport *http.Transport
+}
+
+type upstreamKey struct{}
+
+// NewTransport membuat Transport dengan opts.
+func NewTransport(opts Options) *Transport {
+	opts = opts.withDefaults()
+	return &Transport{
+		opts: opts,
+		// Satu transport untuk semua proxy; koneksi dipakai ulang per proxy
+		transport: &http.Transport{
+			Proxy: func(r *http.Request) (*url.URL, error) {
+				return r.Context().Value(upstreamKey{}).(*url.URL), nil
+			},
+			DialContext:         (&net.Dialer{Timeout: opts.DialTimeout}).DialContext,
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// CloseIdleConnections menutup koneksi idle ke semua proxy.
+func (t *Transport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+// RoundTrip mengirim req lewat proxy yang dipilih. Jika proxy gagal
+// (error koneksi atau 407), request idempoten yang body-nya bisa dibaca
+// ulang dicoba lagi lewat proxy lain. Response dari server tujuan,
+// termasuk status 4xx/5xx, tidak dianggap kegagalan proxy.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := t.opts.MaxAttempts
+	if !replayable(req) {
+		attempts = 1
+	}
+	ctx := req.Context()
+	tried := make(map[string]bool)
+	var lastErr error
+	for i := range attempts {
+		proxy, err := t.opts.pick(ctx, tried)
+		if err != nil {
+			if lastErr == nil {
+				lastErr = err
+			}
+			break
+		}
+		tried[pool.KeyOf(proxy)] = true
+
+		out := req.Clone(context.WithValue(ctx, upstreamKey{}, proxy.URL()))
+		if i > 0 && req.GetBody != nil {
+			if out.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		start := time.Now()
+		resp, err := t.transport.RoundTrip(out)
+		if err == nil && resp.StatusCode == http.StatusProxyAuthRequired {
+			resp.Body.Close()
+			err = fmt.Errorf("proxy %s: %s", proxy, resp.Status)
+		}
+		if err == nil {
+			t.opts.Source.Report(ctx, proxy, nil, time.Since(start))
+			return resp, nil
+		}
+		lastErr = err
+		// Pemakai yang membatalkan atau kehabisan deadline bukan kesalahan
+		// proxy; melaporkannya akan menguras pool yang sehat
+		if ctx.Err() != nil {
+			break
+		}
+		t.opts.Source.Report(ctx, proxy, err, time.Since(start))
+	}
+	return nil, lastErr
+}
+
+// replayable melaporkan apakah req aman dikirim ulang lewat proxy lain:
+// method idempoten (atau ada header Idempotency-Key) dan body bisa dibaca ulang.
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" && req.Header.Get("X-Idempotency-Key") == "" {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// Dialer membuka koneksi TCP lewat proxy dari Source. Dialer memenuhi
+// proxy.Dialer dan proxy.ContextDialer dari golang.org/x/net/proxy.
+type Dialer struct {
+	opts Options
+}
+
+var (
+	_ proxy.Dialer        = (*Dialer)(nil)
+	_ proxy.ContextDialer = (*Dialer)(nil)
+)
+
+// NewDialer membuat Dialer dengan opts.
+func NewDialer(opts Options) *Dialer {
+	return &Dialer{opts: opts.withDefaults()}
+}
+
+// Dial memanggil DialContext tanpa batas waktu selain DialTimeout.
+func (d *Dialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext membuka koneksi ke addr lewat proxy yang dipilih. Membuka
+// koneksi selalu aman diulang sehingga proxy lain dicoba sampai
+// MaxAttempts jika proxy gagal.
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("network tidak didukung lewat proxy: %s", network)
+	}
+	tried := make(map[string]bool)
+	var lastErr error
+	for range d.opts.MaxAttempts {
+		proxy, err := d.opts.pick(ctx, tried)
+		if err != nil {
+			if lastErr == nil {
+				lastErr = err
+			}
+			break
+		}
+		tried[pool.KeyOf(proxy)] = true
+
+		start := time.Now()
+		conn, err := gateway.Dial(ctx, proxy, addr, d.opts.DialTimeout)
+		if err == nil {
+			d.opts.Source.Report(ctx, proxy, nil, time.Since(start))
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+		d.opts.Source.Report(ctx, proxy, err, time.Since(start))
+	}
+	return nil, lastErr
+}
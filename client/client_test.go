@@ -0,0 +1,266 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"deep.go/parse"
+	"deep.go/pool"
+)
+
+// forwardProxy adalah proxy HTTP minimal untuk request absolut dan CONNECT.
+func forwardProxy(t *testing.T) parse.Proxy {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			target, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer target.Close()
+			conn, buf, _ := w.(http.Hijacker).Hijack()
+			defer conn.Close()
+			conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+			go io.Copy(target, buf)
+			io.Copy(conn, target)
+			return
+		}
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(srv.Close)
+	p, _ := parse.Token(srv.Listener.Addr().String())
+	return p
+}
+
+func deadProxy(t *testing.T) parse.Proxy {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	p, _ := parse.Token(ln.Addr().String())
+	return p
+}
+
+// testPool berisi proxy mati dengan skor lebih tinggi sehingga pool.Best
+// selalu mencobanya lebih dulu.
+func testPool(t *testing.T) (p *pool.Pool, good, dead parse.Proxy) {
+	good, dead = forwardProxy(t), deadProxy(t)
+	p = pool.New(pool.Options{MaxFailures: 1})
+	p.Add(pool.Entry{Proxy: good, Score: 0.5}, pool.Entry{Proxy: dead, Score: 0.9})
+	return p, good, dead
+}
+
+func echoBody(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+		io.WriteString(w, r.Method)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTransportLocal(t *testing.T) {
+	p, good, dead := testPool(t)
+	target := echoBody(t)
+	client := &http.Client{Transport: NewTransport(Options{Source: Local(p), Strategy: pool.Best, DialTimeout: time.Second})}
+
+	// POST tidak idempoten sehingga tidak diulang lewat proxy lain
+	if _, err := client.Post(target.URL, "text/plain", strings.NewReader("x")); err == nil {
+		t.Fatal("POST lewat proxy mati seharusnya gagal")
+	}
+	if _, ok := p.Get(pool.KeyOf(dead)); ok {
+		t.Error("proxy mati tidak dibuang dari pool")
+	}
+
+	p.Add(pool.Entry{Proxy: dead, Score: 0.9})
+	req, _ := http.NewRequest(http.MethodPut, target.URL, strings.NewReader("isi-"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "isi-PUT" {
+		t.Errorf("body = %q, want body diulang lewat proxy kedua", body)
+	}
+	if e, ok := p.Get(pool.KeyOf(good)); !ok || e.Failures != 0 {
+		t.Errorf("proxy hidup = %+v, %v", e, ok)
+	}
+}
+
+func TestTransportRemote(t *testing.T) {
+	p, _, dead := testPool(t)
+	api := httptest.NewServer(pool.Handler(p, pool.APIOptions{}))
+	defer api.Close()
+	target := echoBody(t)
+
+	remote := &Remote{BaseURL: api.URL}
+	client := &http.Client{Transport: NewTransport(Options{Source: remote, Strategy: pool.Best, DialTimeout: time.Second})}
+	resp, err := client.Get(target.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Kegagalan dilaporkan ke API sehingga pool di server ikut berubah
+	if err := remote.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Get(pool.KeyOf(dead)); ok {
+		t.Error("laporan kegagalan tidak sampai ke API pool")
+	}
+	entries, err := remote.Entries(context.Background())
+	if err != nil || len(entries) != 1 {
+		t.Errorf("entries = %v, %v", entries, err)
+	}
+}
+
+func TestRemoteReportAsync(t *testing.T) {
+	p, _, dead := testPool(t)
+	handler := pool.Handler(p, pool.APIOptions{})
+	var reports atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/report" {
+			reports.Add(1)
+			time.Sleep(300 * time.Millisecond)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer api.Close()
+	target := echoBody(t)
+
+	// API yang lambat menerima laporan tidak memperlambat request pemakai
+	remote := &Remote{BaseURL: api.URL}
+	client := &http.Client{Transport: NewTransport(Options{Source: remote, Strategy: pool.Best, DialTimeout: time.Second})}
+	start := time.Now()
+	for range 3 {
+		resp, err := client.Get(target.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("3 request butuh %v, request menunggu laporan terkirim", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := remote.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Empat laporan (1 gagal, 3 sukses) terkirim dalam batch
+	if n := reports.Load(); n < 1 || n > 3 {
+		t.Errorf("POST /report = %d kali, ingin laporan digabung", n)
+	}
+	if _, ok := p.Get(pool.KeyOf(dead)); ok {
+		t.Error("laporan kegagalan tidak sampai ke API pool")
+	}
+}
+
+func TestDialer(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		conn, err := echo.Accept()
+		if err == nil {
+			io.Copy(conn, conn)
+			conn.Close()
+		}
+	}()
+
+	p, _, _ := testPool(t)
+	dialer := NewDialer(Options{Source: Local(p), Strategy: pool.Best, DialTimeout: time.Second})
+	conn, err := dialer.Dial("tcp", echo.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "ping\n")
+	if line, err := bufio.NewReader(conn).ReadString('\n'); line != "ping\n" {
+		t.Errorf("echo = %q, %v", line, err)
+	}
+
+	empty := NewDialer(Options{Source: Local(pool.New(pool.Options{}))})
+	if _, err := empty.Dial("tcp", echo.Addr().String()); err != ErrNoProxy {
+		t.Errorf("err = %v, want ErrNoProxy", err)
+	}
+}
+
+// slowProxy menerima koneksi tapi tidak pernah menjawab.
+func slowProxy(t *testing.T) parse.Proxy {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		ln.Close()
+	})
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				<-done
+				conn.Close()
+			}()
+		}
+	}()
+	p, _ := parse.Token(ln.Addr().String())
+	return p
+}
+
+func TestCancelNotReported(t *testing.T) {
+	slow := slowProxy(t)
+	p := pool.New(pool.Options{MaxFailures: 1})
+	p.Add(pool.Entry{Proxy: slow, Score: 0.9})
+	target := echoBody(t)
+
+	// Deadline milik pemakai habis di tengah request; proxy tidak boleh
+	// dianggap gagal
+	client := &http.Client{Transport: NewTransport(Options{Source: Local(p), DialTimeout: 5 * time.Second})}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, target.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("request lewat proxy yang diam seharusnya gagal")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	dialer := NewDialer(Options{Source: Local(p), DialTimeout: 5 * time.Second})
+	if _, err := dialer.DialContext(ctx, "tcp", "127.0.0.1:1"); err == nil {
+		t.Fatal("dial lewat proxy yang diam seharusnya gagal")
+	}
+
+	if e, ok := p.Get(pool.KeyOf(slow)); !ok || e.Failures != 0 {
+		t.Errorf("proxy = %+v, %v; pembatalan dilaporkan sebagai kegagalan", e, ok)
+	}
+}
@@ -0,0 +1,27 @@
+package client_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"deep.go/client"
+	"deep.go/pool"
+)
+
+// Request dirotasi lewat proxy dari API pool yang dilayani "proxyscraper
+// gateway -api-listen"; proxy yang gagal dilaporkan kembali ke API.
+func ExampleNewTransport() {
+	transport := client.NewTransport(client.Options{
+		Source:   &client.Remote{BaseURL: "http://127.0.0.1:8081"},
+		Strategy: pool.Weighted,
+	})
+	httpClient := &http.Client{Transport: transport}
+
+	resp, err := httpClient.Get("http://example.com/")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer resp.Body.Close()
+	fmt.Println(resp.Status)
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"deep.go/parse"
+	"deep.go/pool"
+)
+
+// Source adalah asal proxy untuk Transport dan Dialer.
+type Source interface {
+	// Entries mengembalikan proxy yang bisa dipakai, skor tertinggi dulu.
+	Entries(ctx context.Context) ([]pool.Entry, error)
+	// Report melaporkan hasil pemakaian proxy; err nil berarti berhasil.
+	Report(ctx context.Context, proxy parse.Proxy, err error, latency time.Duration)
+}
+
+// Local memakai pool di proses yang sama; laporan langsung mengubah skornya.
+func Local(p *pool.Pool) Source {
+	return localSource{p}
+}
+
+type localSource struct {
+	pool *pool.Pool
+}
+
+func (s localSource) Entries(context.Context) ([]pool.Entry, error) {
+	return s.pool.Entries(), nil
+}
+
+func (s localSource) Report(_ context.Context, proxy parse.Proxy, err error, latency time.Duration) {
+	if err != nil {
+		s.pool.ReportFailure(pool.KeyOf(proxy))
+	} else {
+		s.pool.ReportSuccess(pool.KeyOf(proxy), latency)
+	}
+}
+
+// Remote mengambil proxy dari API pool (lihat pool.Handler) di baseURL.
+// Daftar proxy disimpan selama Refresh agar API tidak dipanggil setiap
+// request; proxy yang gagal tidak dipakai lagi sampai daftar diperbarui.
+// Laporan dikirim di latar belakang secara batch sehingga request pemakai
+// tidak menunggu API.
+type Remote struct {
+	BaseURL string
+	// Client untuk memanggil API; nil berarti client dengan timeout 10 detik.
+	Client *http.Client
+	// Refresh adalah umur daftar proxy sebelum diambil ulang. Default 30 detik.
+	Refresh time.Duration
+
+	mu       sync.Mutex
+	entries  []pool.Entry
+	fetched  time.Time
+	fetching bool
+	failed   map[string]bool
+
+	// Laporan yang belum terkirim dan pengirim yang sedang berjalan
+	pending []pool.Report
+	sending chan struct{} // ditutup saat pengirim selesai; nil jika tidak ada
+}
+
+func (r *Remote) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// Entries mengembalikan daftar proxy dari cache. Daftar diambil ulang tanpa
+// menahan lock sehingga request lain tetap memakai daftar lama selama API
+// lambat; hanya pemanggil pertama, saat cache masih kosong, yang menunggu.
+func (r *Remote) Entries(ctx context.Context) ([]pool.Entry, error) {
+	refresh := r.Refresh
+	if refresh <= 0 {
+		refresh = 30 * time.Second
+	}
+	r.mu.Lock()
+	stale := r.entries == nil || time.Since(r.fetched) >= refresh
+	if stale && (!r.fetching || r.entries == nil) {
+		r.fetching = true
+		r.mu.Unlock()
+		entries, err := r.fetch(ctx)
+		r.mu.Lock()
+		r.fetching = false
+		if err != nil {
+			// Daftar lama tetap dipakai selama API tidak bisa dihubungi
+			if r.entries == nil {
+				r.mu.Unlock()
+				return nil, err
+			}
+		} else {
+			r.entries, r.fetched, r.failed = entries, time.Now(), nil
+		}
+	}
+	defer r.mu.Unlock()
+	var out []pool.Entry
+	for _, e := range r.entries {
+		if !r.failed[e.Key()] {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *Remote) fetch(ctx context.Context) ([]pool.Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(r.BaseURL, "/")+"/proxies", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API pool: HTTP %d", resp.StatusCode)
+	}
+	var list []pool.APIEntry
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("API pool: %w", err)
+	}
+	entries := make([]pool.Entry, 0, len(list))
+	for _, item := range list {
+		if e, ok := item.Entry(); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// Report mencatat laporan untuk dikirim ke API di latar belakang. Proxy
+// yang gagal langsung tidak dipakai lagi oleh Remote ini.
+func (r *Remote) Report(_ context.Context, proxy parse.Proxy, err error, latency time.Duration) {
+	key := pool.KeyOf(proxy)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if r.failed == nil {
+			r.failed = make(map[string]bool)
+		}
+		r.failed[key] = true
+	}
+	r.pending = append(r.pending, pool.Report{
+		Proxy:     key,
+		OK:        err == nil,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	})
+	if r.sending == nil {
+		r.sending = make(chan struct{})
+		go r.sendReports(r.sending)
+	}
+}
+
+// sendReports mengirim laporan yang tertunda sebagai batch sampai antrean
+// kosong. Laporan yang tiba selama satu batch terkirim ikut batch berikutnya.
+func (r *Remote) sendReports(done chan struct{}) {
+	for {
+		r.mu.Lock()
+		batch := r.pending
+		r.pending = nil
+		if len(batch) == 0 {
+			r.sending = nil
+			r.mu.Unlock()
+			close(done)
+			return
+		}
+		r.mu.Unlock()
+		r.post(batch)
+	}
+}
+
+// post mengirim satu batch laporan. Kegagalan mengirim diabaikan karena
+// laporan hanya memengaruhi skor, bukan request pemakai.
+func (r *Remote) post(batch []pool.Report) {
+	body, _ := json.Marshal(batch)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(r.BaseURL, "/")+"/report", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := r.client().Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// Flush menunggu sampai laporan yang tertunda terkirim atau ctx berakhir,
+// misalnya sebelum program selesai.
+func (r *Remote) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	done := r.sending
+	r.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,147 +1,354 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+
+	"deep.go/adapter"
+	"deep.go/check"
+	"deep.go/limiter"
+	"deep.go/logging"
+	"deep.go/pager"
+	"deep.go/parse"
+	"deep.go/prescreen"
+	"deep.go/progress"
+	"deep.go/ratelimit"
+	"deep.go/reason"
 )
 
 const (
+	// maxConcurrentChecks adalah batas atas; limiter adaptif mulai dari nilai
+	// yang lebih kecil dan naik selama sumber daya lokal masih sehat
 	maxConcurrentChecks = 200
-	checkTimeout        = 10 * time.Second
+	checkTimeout        = 5 * time.Second
 	scrapeTimeout       = 30 * time.Second
 	goodProxiesFile     = "good_proxies.txt"
+	goodProxiesMetaFile = "good_proxies.jsonl"
+
+	// Tahap pre-screen TCP: connect singkat dengan paralelisme tinggi
+	prescreenTimeout   = 2 * time.Second
+	maxConcurrentDials = 2000
 )
 
-// Sumber proxy gratis dengan parser khusus
-var proxySources = []struct {
-	URL    string
-	Parser func(*goquery.Document) []string
-}{
+// Berapa proxy dari pool yang dicoba untuk satu sumber sebelum fallback ke koneksi langsung
+const maxScrapeProxyAttempts = 3
+
+// proxySource adalah satu sumber beserta parsernya. ViaProxy menandai sumber
+// yang memblokir IP kita setelah beberapa run sehingga diambil lewat proxy
+// yang sudah tervalidasi. Params menjabarkan satu sumber menjadi beberapa
+// endpoint, dan Pagination mengambil halaman berikutnya sampai habis.
+// Setiap sumber memakai tepat satu adapter: Table untuk halaman HTML
+// atau JSON untuk API.
+type proxySource struct {
+	URL        string
+	Table      *adapter.Table
+	JSON       *adapter.JSON
+	ViaProxy   bool
+	Params     map[string][]string
+	Pagination *pager.Pagination
+}
+
+// Sumber proxy gratis beserta adapternya
+var proxySources = []proxySource{
 	{
-		URL: "https://free-proxy-list.net/",
-		Parser: func(doc *goquery.Document) []string {
-			var proxies []string
-			doc.Find("table#proxylisttable tbody tr").Each(func(i int, s *goquery.Selection) {
-				ip := s.Find("td:nth-child(1)").Text()
-				port := s.Find("td:nth-child(2)").Text()
-				if ip != "" && port != "" {
-					proxies = append(proxies, ip+":"+port)
-				}
-			})
-			return proxies
-		},
+		URL:      "https://free-proxy-list.net/",
+		ViaProxy: true,
+		Table:    &adapter.Table{Selector: "table#proxylisttable"},
 	},
 	{
-		URL: "https://www.sslproxies.org/",
-		Parser: func(doc *goquery.Document) []string {
-			var proxies []string
-			doc.Find("table#proxylisttable tbody tr").Each(func(i int, s *goquery.Selection) {
-				ip := s.Find("td:nth-child(1)").Text()
-				port := s.Find("td:nth-child(2)").Text()
-				if ip != "" && port != "" {
-					proxies = append(proxies, ip+":"+port)
-				}
-			})
-			return proxies
-		},
+		URL:      "https://www.sslproxies.org/",
+		ViaProxy: true,
+		Table:    &adapter.Table{Selector: "table#proxylisttable"},
 	},
 	{
-		URL: "https://www.us-proxy.org/",
-		Parser: func(doc *goquery.Document) []string {
-			var proxies []string
-			doc.Find("table#proxylisttable tbody tr").Each(func(i int, s *goquery.Selection) {
-				ip := s.Find("td:nth-child(1)").Text()
-				port := s.Find("td:nth-child(2)").Text()
-				if ip != "" && port != "" {
-					proxies = append(proxies, ip+":"+port)
-				}
-			})
-			return proxies
-		},
+		URL:      "https://www.us-proxy.org/",
+		ViaProxy: true,
+		Table:    &adapter.Table{Selector: "table#proxylisttable"},
 	},
 	{
-		URL: "https://proxyscrape.com/free-proxy-list",
-		Parser: func(doc *goquery.Document) []string {
-			var proxies []string
-			doc.Find("table.table tbody tr").Each(func(i int, s *goquery.Selection) {
-				ip := s.Find("td:nth-child(1)").Text()
-				port := s.Find("td:nth-child(2)").Text()
-				if ip != "" && port != "" {
-					proxies = append(proxies, ip+":"+port)
-				}
-			})
-			return proxies
+		// Alamat IP ditulis lewat document.write(Base64.decode(...))
+		URL:      "http://free-proxy.cz/en/",
+		ViaProxy: true,
+		Table: &adapter.Table{
+			Selector: "table#proxy_list",
+			Decoders: adapter.AllDecoders,
 		},
 	},
 	{
-		URL: "https://proxylist.geonode.com/api/proxy-list?limit=500&page=1&sort_by=lastChecked&sort_type=desc",
-		Parser: func(doc *goquery.Document) []string {
-			// Situs ini mengembalikan JSON, kita akan tangani secara khusus
-			return []string{}
+		URL:   "https://proxyscrape.com/free-proxy-list",
+		Table: &adapter.Table{Selector: "table.table"},
+	},
+	{
+		URL: "https://proxylist.geonode.com/api/proxy-list?sort_by=lastChecked&sort_type=desc",
+		Pagination: &pager.Pagination{
+			Mode:      pager.Page,
+			Param:     "page",
+			Size:      500,
+			SizeParam: "limit",
+			MaxPages:  10,
+		},
+		JSON: &adapter.JSON{
+			Items:     "$.data[*]",
+			IP:        "ip",
+			Port:      "port",
+			Protocols: "protocols",
+			Country:   "country",
+			Anonymity: "anonymityLevel",
+			Total:     "total",
 		},
 	},
 }
 
 func main() {
-	log.Println("Memulai proses scraping proxy...")
-	allProxies := scrapeAllProxies()
-	log.Printf("Berhasil mengumpulkan %d proxy\n", len(allProxies))
+	direct := flag.Bool("direct", false, "scrape semua sumber langsung tanpa proxy dari run sebelumnya")
+	quiet := flag.Bool("quiet", false, "jangan tampilkan progres pengecekan")
+	verbose := flag.Bool("verbose", false, "tampilkan juga satu baris per proxy yang dicek")
+	rateLimits := flag.String("rate-limits", "", "file JSON batas laju request tes per proxy, per host URL tes dan global")
+	logFlags := logging.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	// Log ditulis lewat tampilan progres agar tidak menimpa baris status.
+	// Dengan -log-format json stdout hanya berisi log JSON, sedangkan
+	// progres dan ringkasannya pindah ke stderr.
+	level := progress.LevelFromFlags(*quiet, *verbose)
+	tracker := progress.New(os.Stdout, level)
+	var logOut io.Writer = tracker
+	if *logFlags.Format == "json" {
+		tracker = progress.New(os.Stderr, level)
+		logOut = os.Stdout
+	}
+	if err := logFlags.Setup(logOut, *quiet, *verbose); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Batas laju dibaca sebelum scraping agar file yang salah langsung ketahuan
+	rl, err := loadRateLimits(*rateLimits)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Proxy baik dari run sebelumnya dipakai untuk sumber yang memblokir IP kita.
+	// Run pertama belum punya pool sehingga semua sumber diambil langsung.
+	var pool *scrapePool
+	if !*direct {
+		pool = loadScrapePool(goodProxiesFile)
+		if pool != nil {
+			logging.Info(logging.ScrapePoolLoaded, logging.KeyFile, goodProxiesFile, logging.KeyCount, len(pool.proxies))
+		}
+	}
+
+	logging.Info(logging.ScrapeAllStart, logging.KeyCount, len(proxySources))
+	allProxies := scrapeAllProxies(pool)
+	logging.Info(logging.ScrapeAllDone, logging.KeyCount, len(allProxies))
 
 	if len(allProxies) == 0 {
-		log.Println("Tidak ada proxy yang ditemukan, keluar...")
+		logging.Warn(logging.ScrapeNone)
 		return
 	}
 
-	log.Println("Memulai pengecekan proxy...")
-	goodProxies := checkProxiesConcurrently(allProxies)
+	logging.Info(logging.PrescreenStart, logging.KeyCount, len(allProxies))
+	proxies := make([]parse.Proxy, len(allProxies))
+	for i, p := range allProxies {
+		proxies[i] = toParse(p)
+	}
+	results, stats := check.Prescreen(context.Background(), proxies, prescreen.Config{
+		Timeout:        prescreenTimeout,
+		MaxConcurrency: maxConcurrentDials,
+	})
+	var survivors []adapter.Proxy
+	for i, p := range allProxies {
+		if results[i].Valid {
+			survivors = append(survivors, p)
+		} else {
+			failureReasons.Add(results[i].Reason)
+			logging.Debug(logging.CheckInvalid, logging.KeyProxy, p.Addr(), logging.KeyStage, check.StageTCP,
+				logging.KeyReason, string(results[i].Reason))
+		}
+	}
+	logging.Info(logging.StageDone, logging.KeyStage, check.StageTCP, "checked", stats.Total, "eliminated", stats.Eliminated)
+
+	logging.Info(logging.CheckStart, logging.KeyCount, len(survivors))
+	goodProxies := checkProxiesConcurrently(survivors, tracker, rl)
+	logging.Info(logging.StageDone, logging.KeyStage, check.StageHTTP, "checked", len(survivors), "eliminated", len(survivors)-len(goodProxies))
 
-	log.Printf("\nSelesai! Proxy baik: %d, Proxy mati: %d\n", len(goodProxies), len(allProxies)-len(goodProxies))
+	logging.Info(logging.RunDone, "valid", len(goodProxies), "invalid", len(allProxies)-len(goodProxies))
+	for _, e := range failureReasons.Sorted() {
+		logging.Info(logging.ReasonCount, logging.KeyReason, string(e.Reason), logging.KeyCount, e.Count)
+	}
 	saveProxiesToFile(goodProxies, goodProxiesFile)
-	log.Printf("Proxy yang baik disimpan di %s\n", goodProxiesFile)
+	saveProxyMetadata(goodProxies, goodProxiesMetaFile)
+	logging.Info(logging.Saved, logging.KeyFile, goodProxiesFile, "metadata", goodProxiesMetaFile)
 }
 
-func scrapeAllProxies() []string {
-	var allProxies []string
+// loadRateLimits membaca -rate-limits; path kosong berarti tanpa batas.
+func loadRateLimits(path string) (*ratelimit.Limiter, error) {
+	if path == "" {
+		return nil, nil
+	}
+	cfg, err := ratelimit.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca -rate-limits: %w", err)
+	}
+	return ratelimit.New(cfg), nil
+}
+
+func scrapeAllProxies(pool *scrapePool) []adapter.Proxy {
+	var allProxies []adapter.Proxy
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	for _, source := range proxySources {
 		wg.Add(1)
-		go func(s struct {
-			URL    string
-			Parser func(*goquery.Document) []string
-		}) {
+		go func(s proxySource) {
 			defer wg.Done()
-			proxies, err := scrapeProxies(s.URL, s.Parser)
+			proxies, err := scrapeSource(s, pool)
 			if err != nil {
-				log.Printf("Gagal scrape %s: %v", s.URL, err)
+				logging.Warn(logging.ScrapeFailed, logging.KeySource, s.URL, logging.KeyError, err)
 				return
 			}
 			
 			mu.Lock()
 			allProxies = append(allProxies, proxies...)
 			mu.Unlock()
-			log.Printf("Scrape %s: %d proxy", s.URL, len(proxies))
+			logging.Info(logging.ScrapeDone, logging.KeySource, s.URL, logging.KeyCount, len(proxies))
 		}(source)
 	}
 	wg.Wait()
 	return deduplicateProxies(allProxies)
 }
 
-func scrapeProxies(url string, parser func(*goquery.Document) []string) ([]string, error) {
-	client := &http.Client{Timeout: scrapeTimeout}
-	req, err := http.NewRequest("GET", url, nil)
+// scrapePool merotasi proxy tervalidasi untuk mengambil sumber. Proxy yang
+// gagal dilewati pada pengambilan berikutnya.
+type scrapePool struct {
+	proxies []parse.Proxy
+	next    atomic.Uint64
+
+	mu  sync.Mutex
+	bad map[string]bool
+}
+
+// loadScrapePool membaca proxy baik dari run sebelumnya, nil jika belum ada
+func loadScrapePool(filename string) *scrapePool {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	// Baris diurai dengan parse.Token sehingga skema, kredensial dan IPv6
+	// dari file yang ditulis tool lain ikut dikenali
+	pool := &scrapePool{bad: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if proxy, ok := parse.Token(strings.TrimSpace(scanner.Text())); ok {
+			pool.proxies = append(pool.proxies, proxy)
+		}
+	}
+	if len(pool.proxies) == 0 {
+		return nil
+	}
+	return pool
+}
+
+// pick mengembalikan proxy berikutnya yang belum ditandai gagal
+func (p *scrapePool) pick() (parse.Proxy, bool) {
+	for range p.proxies {
+		proxy := p.proxies[p.next.Add(1)%uint64(len(p.proxies))]
+		p.mu.Lock()
+		bad := p.bad[proxy.Key()]
+		p.mu.Unlock()
+		if !bad {
+			return proxy, true
+		}
+	}
+	return parse.Proxy{}, false
+}
+
+func (p *scrapePool) markBad(proxy parse.Proxy) {
+	p.mu.Lock()
+	p.bad[proxy.Key()] = true
+	p.mu.Unlock()
+}
+
+// scrapeSource menjabarkan sumber ke semua endpoint dan halamannya. Halaman
+// yang gagal di tengah jalan tidak membuang hasil halaman sebelumnya.
+func scrapeSource(s proxySource, pool *scrapePool) ([]adapter.Proxy, error) {
+	urls, err := pager.Expand(s.URL, s.Params)
 	if err != nil {
 		return nil, err
 	}
+
+	var all []adapter.Proxy
+	var lastErr error
+	for _, u := range urls {
+		pages, err := pager.Walk(u, s.Pagination, func(pageURL string) (pager.Result, error) {
+			// Count adalah item mentah agar halaman yang isinya tidak terbaca
+			// semua tidak menghentikan paginasi terlalu awal
+			page, err := fetchSource(s, pageURL, pool)
+			all = append(all, page.Proxies...)
+			return pager.Result{Count: page.Items, Total: page.Total}, err
+		})
+		if err != nil {
+			logging.Warn(logging.ScrapeStopped, logging.KeySource, u, "pages", pages, logging.KeyError, err)
+			lastErr = err
+		}
+	}
+	if len(all) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return all, nil
+}
+
+// fetchSource mengambil satu halaman lewat proxy dari pool jika sumber memerlukannya,
+// lalu fallback ke koneksi langsung bila semua percobaan lewat proxy gagal
+func fetchSource(s proxySource, pageURL string, pool *scrapePool) (adapter.Page, error) {
+	if s.ViaProxy && pool != nil {
+		for i := 0; i < maxScrapeProxyAttempts; i++ {
+			proxy, ok := pool.pick()
+			if !ok {
+				break
+			}
+			client := &http.Client{
+				Transport: &http.Transport{
+					Proxy:             http.ProxyURL(proxy.URL()),
+					DisableKeepAlives: true,
+				},
+				Timeout: scrapeTimeout,
+			}
+			page, err := scrapeProxies(client, pageURL, s)
+			// Halaman yang terambil tapi kosong biasanya halaman blokir atau captcha
+			if err == nil && len(page.Proxies) > 0 {
+				return page, nil
+			}
+			pool.markBad(proxy)
+			logging.Debug(logging.ScrapeProxyFail, logging.KeySource, pageURL, logging.KeyProxy, proxy.String())
+		}
+		logging.Info(logging.ScrapeDirect, logging.KeySource, pageURL)
+	}
+	return scrapeProxies(&http.Client{Timeout: scrapeTimeout}, pageURL, s)
+}
+
+// scrapeProxies mengambil satu halaman dan mengembalikan proxy beserta jumlah
+// item mentah dan total yang dilaporkan sumber (0 jika tidak diketahui)
+func scrapeProxies(client *http.Client, url string, s proxySource) (adapter.Page, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return adapter.Page{}, err
+	}
 	
 	// Set header untuk menyerupai browser
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/101.0.4951.64 Safari/537.36")
@@ -150,159 +357,169 @@ func scrapeProxies(url string, parser func(*goquery.Document) []string) ([]strin
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return adapter.Page{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+		return adapter.Page{}, fmt.Errorf("status code %d", resp.StatusCode)
 	}
 
-	// Handle khusus untuk API JSON (GeoNode)
-	if strings.Contains(url, "geonode.com") {
-		return scrapeGeoNodeProxies(resp)
+	// Sumber API JSON dipetakan lewat selector field
+	if s.JSON != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return adapter.Page{}, err
+		}
+		return s.JSON.ParsePage(body)
 	}
 
-	// Parsing HTML untuk situs lainnya
+	// Parsing tabel HTML untuk situs lainnya
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		return nil, err
+		return adapter.Page{}, err
 	}
-
-	return parser(doc), nil
+	return s.Table.ParsePage(doc)
 }
 
-func scrapeGeoNodeProxies(resp *http.Response) ([]string, error) {
-	type geoNodeProxy struct {
-		IP   string `json:"ip"`
-		Port string `json:"port"`
-	}
-
-	type geoNodeResponse struct {
-		Data []geoNodeProxy `json:"data"`
-	}
-
-	var result geoNodeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	var proxies []string
-	for _, p := range result.Data {
-		if p.IP != "" && p.Port != "" {
-			proxies = append(proxies, p.IP+":"+p.Port)
-		}
-	}
-	return proxies, nil
-}
-
-func deduplicateProxies(proxies []string) []string {
-	unique := make(map[string]bool)
-	var result []string
+// deduplicateProxies menggabungkan proxy dengan alamat sama dari beberapa
+// sumber; metadata yang kosong dilengkapi dari sumber lain
+func deduplicateProxies(proxies []adapter.Proxy) []adapter.Proxy {
+	unique := make(map[string]int)
+	var result []adapter.Proxy
 	for _, proxy := range proxies {
-		if _, exists := unique[proxy]; !exists {
-			unique[proxy] = true
-			result = append(result, proxy)
+		if i, exists := unique[proxy.Addr()]; exists {
+			result[i].Merge(proxy)
+			continue
 		}
+		unique[proxy.Addr()] = len(result)
+		result = append(result, proxy)
 	}
 	return result
 }
 
-func checkProxiesConcurrently(proxies []string) []string {
-	sem := make(chan struct{}, maxConcurrentChecks)
-	results := make(chan string)
+// failureReasons menghitung alasan kegagalan dari kedua tahap pengecekan.
+var failureReasons reason.Counter
+
+func checkProxiesConcurrently(proxies []adapter.Proxy, tracker *progress.Tracker, rl *ratelimit.Limiter) []adapter.Proxy {
+	lim := limiter.New(limiter.Config{Max: maxConcurrentChecks})
+	checker := newChecker(rl)
+	ctx := context.Background()
 	var wg sync.WaitGroup
-	var goodProxies []string
+	var goodProxies []adapter.Proxy
 	var mu sync.Mutex
 
+	tracker.AddTotal(len(proxies))
+	tracker.Start()
+
 	// Worker
 	for _, proxy := range proxies {
+		// Goroutine baru hanya dibuat setelah slot tersedia
+		if err := lim.Acquire(ctx); err != nil {
+			break
+		}
 		wg.Add(1)
-		go func(p string) {
+		go func(p adapter.Proxy) {
 			defer wg.Done()
-			sem <- struct{}{}
-			alive := checkProxy(p)
-			<-sem
+			start := time.Now()
+			result := checker.Check(ctx, toParse(p))
+			lim.Release(limiter.Classify(result.Err))
 
-			if alive {
+			latency := time.Since(start)
+			if result.Valid {
 				mu.Lock()
 				goodProxies = append(goodProxies, p)
 				mu.Unlock()
-				fmt.Printf("[✓] %s\n", p)
+				tracker.Done(true, "", latency)
+				logging.Debug(logging.CheckValid, logging.KeyProxy, p.Addr(), logging.KeyStage, check.StageHTTP,
+					logging.KeyLatency, latency.Milliseconds(), "country", p.Country)
 			} else {
-				fmt.Printf("[✗] %s\n", p)
+				failureReasons.Add(result.Reason)
+				tracker.Done(false, string(result.Reason), latency)
+				logging.Debug(logging.CheckInvalid, logging.KeyProxy, p.Addr(), logging.KeyStage, check.StageHTTP,
+					logging.KeyReason, string(result.Reason), logging.KeyLatency, latency.Milliseconds())
 			}
 		}(proxy)
 	}
 
 	wg.Wait()
+	tracker.Stop()
+	stats := lim.Stats()
+	logging.Info(logging.LimiterStats, "limit", stats.Limit, "peak", stats.Peak, "backoffs", stats.Backoffs)
 	return goodProxies
 }
 
-func checkProxy(proxy string) bool {
-	proxyURL, err := url.Parse("http://" + proxy)
-	if err != nil {
-		return false
-	}
-
-	client := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
-			// Disable keep-alive untuk koneksi lebih cepat
-			DisableKeepAlives: true,
-		},
+// newChecker membuat checker dengan kebijakan tool ini: proxy valid jika
+// berhasil di 2 dari 3 target. Target seperti example.com tidak
+// mengembalikan IP sehingga body cukup tidak kosong dan bukan captcha.
+func newChecker(rl *ratelimit.Limiter) *check.Checker {
+	return check.New(check.Options{
 		Timeout: checkTimeout,
-	}
+		TestURLs: []string{
+			"http://example.com",
+			"http://httpbin.org/ip",
+			"http://google.com",
+		},
+		Required:  2,
+		ValidBody: func([]byte) bool { return true },
+		RateLimit: rl,
+	})
+}
 
-	// Coba dengan beberapa target berbeda
-	targets := []string{
-		"http://example.com",
-		"http://httpbin.org/ip",
-		"http://google.com",
-	}
+// toParse mengubah proxy hasil adapter ke parse.Proxy untuk package check.
+func toParse(p adapter.Proxy) parse.Proxy {
+	return parse.New(proxyScheme(p), p.IP, p.Port, "", "")
+}
 
-	successCount := 0
-	for _, target := range targets {
-		if checkWithClient(client, target) {
-			successCount++
+// proxyScheme memilih skema dari protokol yang dipublikasikan sumber.
+// SOCKS4 tidak didukung net/http sehingga tetap dicoba sebagai http.
+func proxyScheme(p adapter.Proxy) string {
+	socks5 := false
+	for _, proto := range p.Protocols {
+		switch proto {
+		case "http", "https":
+			return "http"
+		case "socks5":
+			socks5 = true
 		}
-		// Beri jeda singkat antara pengecekan
-		time.Sleep(100 * time.Millisecond)
 	}
-
-	// Dianggap valid jika berhasil di 2 dari 3 target
-	return successCount >= 2
+	if socks5 {
+		return "socks5"
+	}
+	return "http"
 }
 
-func checkWithClient(client *http.Client, url string) bool {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return false
+func saveProxiesToFile(proxies []adapter.Proxy, filename string) {
+	if len(proxies) == 0 {
+		logging.Warn(logging.SaveEmpty)
+		return
 	}
 
-	// Set timeout khusus untuk request
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	req = req.WithContext(ctx)
-
-	resp, err := client.Do(req)
+	addrs := make([]string, len(proxies))
+	for i, p := range proxies {
+		addrs[i] = p.Addr()
+	}
+	content := strings.Join(addrs, "\n")
+	err := os.WriteFile(filename, []byte(content), 0644)
 	if err != nil {
-		return false
+		logging.Error(logging.SaveFailed, logging.KeyFile, filename, logging.KeyError, err)
 	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode >= 200 && resp.StatusCode < 400
 }
 
-func saveProxiesToFile(proxies []string, filename string) {
+// saveProxyMetadata menyimpan proxy baik beserta metadata dari sumber, satu JSON per baris
+func saveProxyMetadata(proxies []adapter.Proxy, filename string) {
 	if len(proxies) == 0 {
-		log.Println("Tidak ada proxy yang valid untuk disimpan")
 		return
 	}
 
-	content := strings.Join(proxies, "\n")
-	err := os.WriteFile(filename, []byte(content), 0644)
-	if err != nil {
-		log.Printf("Gagal menyimpan file: %v", err)
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	for _, p := range proxies {
+		if err := enc.Encode(p); err != nil {
+			logging.Error(logging.SaveFailed, logging.KeyFile, filename, logging.KeyProxy, p.Addr(), logging.KeyError, err)
+		}
+	}
+	if err := os.WriteFile(filename, []byte(buf.String()), 0644); err != nil {
+		logging.Error(logging.SaveFailed, logging.KeyFile, filename, logging.KeyError, err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,61 @@
+// Package export menulis hasil pengecekan: daftar proxy satu per baris
+// dalam bentuk parse.Proxy.Export, dan detail hasil sebagai JSON per baris
+// agar alasan kegagalan bisa diolah oleh tool lain.
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"deep.go/check"
+	"deep.go/parse"
+)
+
+// Write menulis satu proxy per baris dalam format ekspor.
+func Write(w io.Writer, proxies []parse.Proxy) error {
+	writer := bufio.NewWriter(w)
+	for _, proxy := range proxies {
+		_, err := writer.WriteString(proxy.Export() + "\n")
+		if err != nil {
+			return fmt.Errorf("failed to write to file: %w", err)
+		}
+	}
+	return writer.Flush()
+}
+
+// WriteFile menulis proxies ke filename dengan Write.
+func WriteFile(filename string, proxies []parse.Proxy) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	return Write(file, proxies)
+}
+
+// WriteResults menulis satu check.Result per baris sebagai JSON.
+func WriteResults(w io.Writer, results []check.Result) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	for _, result := range results {
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to write to file: %w", err)
+		}
+	}
+	return writer.Flush()
+}
+
+// WriteResultsFile menulis results ke filename dengan WriteResults.
+func WriteResultsFile(filename string, results []check.Result) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	return WriteResults(file, results)
+}
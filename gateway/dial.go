@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/proxy"
+
+	"deep.go/parse"
+)
+
+// Dial membuka koneksi ke addr (host:port) lewat upstream p. Proxy HTTP dan
+// HTTPS memakai tunnel CONNECT; SOCKS5 memakai autentikasi RFC 1929 jika p
+// punya kredensial. Untuk socks5 hostname di-resolve secara lokal, untuk
+// socks5h diserahkan ke proxy.
+func Dial(ctx context.Context, p parse.Proxy, addr string, timeout time.Duration) (net.Conn, error) {
+	d := &net.Dialer{Timeout: timeout}
+	switch p.Protocol() {
+	case "http", "https":
+		return dialConnect(ctx, d, p, addr)
+	case "socks5", "socks5h":
+		if p.Protocol() == "socks5" {
+			resolved, err := resolve(ctx, addr)
+			if err != nil {
+				return nil, err
+			}
+			addr = resolved
+		}
+		var auth *proxy.Auth
+		if p.HasAuth() {
+			auth = &proxy.Auth{User: p.Username, Password: p.Password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", p.Addr(), auth, d)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	}
+	return nil, fmt.Errorf("skema proxy tidak didukung: %q", p.Protocol())
+}
+
+// dialConnect membuka tunnel CONNECT lewat proxy HTTP atau HTTPS.
+func dialConnect(ctx context.Context, d *net.Dialer, p parse.Proxy, addr string) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, "tcp", p.Addr())
+	if err != nil {
+		return nil, err
+	}
+	if p.Protocol() == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.Host})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	// Handshake CONNECT juga dibatasi oleh context, termasuk pembatalan
+	// tanpa deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if d.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(d.Timeout))
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+	req := "CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n"
+	if p.HasAuth() {
+		token := base64.StdEncoding.EncodeToString([]byte(p.Username + ":" + p.Password))
+		req += "Proxy-Authorization: Basic " + token + "\r\n"
+	}
+	if _, err := conn.Write([]byte(req + "\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy menolak CONNECT: %s", resp.Status)
+	}
+	if !stop() {
+		// Context berakhir tepat setelah handshake selesai
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	conn.SetDeadline(time.Time{})
+	if br.Buffered() > 0 {
+		// Data tunnel yang ikut terbaca bersama balasan CONNECT
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// resolve mengganti hostname di addr dengan alamat IP, mengutamakan IPv4.
+func resolve(ctx context.Context, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) != nil {
+		return addr, nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return "", err
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("%s tidak punya alamat IP", host)
+	}
+	ip := ips[0]
+	for _, candidate := range ips {
+		if candidate.Is4() || candidate.Is4In6() {
+			ip = candidate
+			break
+		}
+	}
+	return net.JoinHostPort(ip.Unmap().String(), port), nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+func (c *bufferedConn) CloseWrite() error {
+	closeWrite(c.Conn)
+	return nil
+}
@@ -0,0 +1,313 @@
+// Package gateway menjalankan proxy HTTP lokal yang meneruskan setiap
+// request lewat proxy dari pool.Pool. Request biasa diteruskan dengan
+// http.Transport, sedangkan CONNECT (HTTPS) dibuat tunnel lewat Dial.
+// Upstream yang gagal dilaporkan ke pool dan request dicoba lagi lewat
+// proxy lain jika aman diulang.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"deep.go/parse"
+	"deep.go/pool"
+	"deep.go/ratelimit"
+)
+
+// Options mengatur Server. Nilai nol memakai default.
+type Options struct {
+	// MaxAttempts adalah jumlah proxy berbeda yang dicoba per request.
+	// Default 3.
+	MaxAttempts int
+	// DialTimeout untuk membuka koneksi lewat upstream. Default 10 detik.
+	DialTimeout time.Duration
+	// Strategy memilih upstream per request. Default pool.Random.
+	Strategy pool.Strategy
+	// Affinity mengikat request dengan kunci afinitas yang sama ke upstream
+	// yang sama; nil berarti setiap request dipilih dengan Strategy.
+	Affinity *pool.Affinity
+	// AffinityKey mengambil kunci afinitas dari request; kunci kosong
+	// berarti Strategy. Default SessionKey.
+	AffinityKey func(*http.Request) string
+	// RateLimit membatasi request per upstream, per host tujuan dan global;
+	// nil berarti tanpa batas.
+	RateLimit *ratelimit.Limiter
+	// OnUpstreamError dipanggil setiap kali upstream gagal, misalnya untuk log.
+	OnUpstreamError func(upstream parse.Proxy, err error)
+	// OnUpstreamSuccess dipanggil setiap kali upstream berhasil meneruskan
+	// request atau membuka tunnel, misalnya untuk metrik.
+	OnUpstreamSuccess func(upstream parse.Proxy, latency time.Duration)
+}
+
+// Server adalah http.Handler untuk dipasang pada http.Server.
+type Server struct {
+	pool      *pool.Pool
+	opts      Options
+	transport *http.Transport
+}
+
+type upstreamKey struct{}
+
+// New membuat Server yang memilih upstream dari p.
+func New(p *pool.Pool, opts Options) *Server {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 10 * time.Second
+	}
+	if opts.AffinityKey == nil {
+		opts.AffinityKey = SessionKey
+	}
+	s := &Server{pool: p, opts: opts}
+	// Satu transport untuk semua upstream; koneksi dipakai ulang per upstream
+	s.transport = &http.Transport{
+		Proxy: func(r *http.Request) (*url.URL, error) {
+			return r.Context().Value(upstreamKey{}).(*url.URL), nil
+		},
+		DialContext:         (&net.Dialer{Timeout: opts.DialTimeout}).DialContext,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return s
+}
+
+// Close menutup koneksi idle ke upstream.
+func (s *Server) Close() {
+	s.transport.CloseIdleConnections()
+}
+
+// errNoUpstream dikembalikan jika pool tidak punya proxy yang belum dicoba.
+var errNoUpstream = errors.New("tidak ada proxy upstream yang tersedia")
+
+// SessionHeader adalah header berisi ID sesi untuk SessionKey. Header ini
+// tidak diteruskan ke upstream.
+const SessionHeader = "X-Proxy-Session"
+
+// SessionKey mengambil ID sesi dari SessionHeader, atau dari username
+// Proxy-Authorization untuk klien yang tidak bisa menambah header pada
+// CONNECT, misalnya http://sesi-42@127.0.0.1:8080 sebagai alamat proxy.
+func SessionKey(r *http.Request) string {
+	if session := r.Header.Get(SessionHeader); session != "" {
+		return session
+	}
+	auth := &http.Request{Header: http.Header{"Authorization": r.Header["Proxy-Authorization"]}}
+	user, _, _ := auth.BasicAuth()
+	return user
+}
+
+// HostKey memakai host tujuan sebagai kunci afinitas sehingga semua request
+// ke satu situs keluar lewat IP yang sama.
+func HostKey(r *http.Request) string {
+	if r.Method == http.MethodConnect {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			return r.Host
+		}
+		return host
+	}
+	return r.URL.Hostname()
+}
+
+// pick memilih proxy yang belum dicoba pada request ini, lewat Affinity
+// jika request punya kunci afinitas.
+func (s *Server) pick(r *http.Request, tried map[string]bool) (pool.Entry, error) {
+	var e pool.Entry
+	var ok bool
+	if key := s.opts.AffinityKey(r); key != "" && s.opts.Affinity != nil {
+		e, ok = s.pool.PickSticky(s.opts.Affinity, key, tried)
+	} else {
+		e, ok = s.pool.Pick(s.opts.Strategy, tried)
+	}
+	if !ok {
+		return pool.Entry{}, errNoUpstream
+	}
+	return e, nil
+}
+
+// wait menunggu batas laju untuk request r lewat e. Error berarti klien
+// sudah pergi selama menunggu.
+func (s *Server) wait(r *http.Request, e pool.Entry) error {
+	_, err := s.opts.RateLimit.Wait(r.Context(), e.Key(), HostKey(r))
+	return err
+}
+
+func (s *Server) succeeded(e pool.Entry, latency time.Duration) {
+	s.pool.ReportSuccess(e.Key(), latency)
+	if s.opts.OnUpstreamSuccess != nil {
+		s.opts.OnUpstreamSuccess(e.Proxy, latency)
+	}
+}
+
+func (s *Server) failed(e pool.Entry, err error) {
+	s.pool.ReportFailure(e.Key())
+	if s.opts.OnUpstreamError != nil {
+		s.opts.OnUpstreamError(e.Proxy, err)
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		s.serveConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "gateway hanya menerima request proxy dengan URL absolut", http.StatusBadRequest)
+		return
+	}
+	s.serveForward(w, r)
+}
+
+// serveForward meneruskan request biasa. Request dengan body atau method
+// yang tidak idempoten hanya dicoba sekali karena body tidak bisa diulang.
+func (s *Server) serveForward(w http.ResponseWriter, r *http.Request) {
+	attempts := s.opts.MaxAttempts
+	if !retryable(r) {
+		attempts = 1
+	}
+	tried := make(map[string]bool)
+	var lastErr error
+	for range attempts {
+		entry, err := s.pick(r, tried)
+		if err != nil {
+			break
+		}
+		tried[entry.Key()] = true
+		if err := s.wait(r, entry); err != nil {
+			return
+		}
+
+		out := r.Clone(context.WithValue(r.Context(), upstreamKey{}, entry.Proxy.URL()))
+		out.RequestURI = ""
+		removeHopHeaders(out.Header)
+		out.Header.Del(SessionHeader)
+		start := time.Now()
+		resp, err := s.transport.RoundTrip(out)
+		if err != nil {
+			lastErr = err
+			s.failed(entry, err)
+			continue
+		}
+		s.succeeded(entry, time.Since(start))
+		defer resp.Body.Close()
+		removeHopHeaders(resp.Header)
+		for k, values := range resp.Header {
+			for _, v := range values {
+				w.Header().Add(k, v)
+			}
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+		return
+	}
+	writeUpstreamError(w, lastErr)
+}
+
+// serveConnect membuat tunnel ke r.Host lewat upstream lalu menyalin data
+// dua arah sampai salah satu sisi menutup koneksi.
+func (s *Server) serveConnect(w http.ResponseWriter, r *http.Request) {
+	tried := make(map[string]bool)
+	var upstream net.Conn
+	var lastErr error
+	for range s.opts.MaxAttempts {
+		entry, err := s.pick(r, tried)
+		if err != nil {
+			break
+		}
+		tried[entry.Key()] = true
+		if err := s.wait(r, entry); err != nil {
+			return
+		}
+
+		start := time.Now()
+		conn, err := Dial(r.Context(), entry.Proxy, r.Host, s.opts.DialTimeout)
+		if err != nil {
+			lastErr = err
+			s.failed(entry, err)
+			continue
+		}
+		s.succeeded(entry, time.Since(start))
+		upstream = conn
+		break
+	}
+	if upstream == nil {
+		writeUpstreamError(w, lastErr)
+		return
+	}
+	defer upstream.Close()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijack tidak didukung", http.StatusInternalServerError)
+		return
+	}
+	client, buf, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer client.Close()
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Data yang sudah ada di buffer server ikut diteruskan
+		io.Copy(upstream, buf)
+		closeWrite(upstream)
+	}()
+	io.Copy(client, upstream)
+	closeWrite(client)
+	wg.Wait()
+}
+
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	if err == nil {
+		http.Error(w, errNoUpstream.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, "upstream gagal: "+err.Error(), http.StatusBadGateway)
+}
+
+// retryable melaporkan apakah r aman dikirim ulang lewat proxy lain.
+func retryable(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0
+	}
+	return false
+}
+
+// Header hop-by-hop tidak boleh diteruskan oleh proxy (RFC 9110 7.6.1).
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, value := range h.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			h.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+}
+
+func closeWrite(conn net.Conn) {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		cw.CloseWrite()
+		return
+	}
+	conn.Close()
+}
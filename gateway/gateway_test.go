@@ -0,0 +1,281 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"deep.go/parse"
+	"deep.go/pool"
+	"deep.go/ratelimit"
+)
+
+// fakeUpstream adalah proxy HTTP minimal: request absolut diteruskan dengan
+// header X-Upstream berisi alamatnya dan CONNECT dibuat tunnel. Jika user
+// tidak kosong, Proxy-Authorization wajib.
+func fakeUpstream(t *testing.T, user, pass string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user != "" {
+			req := &http.Request{Header: http.Header{"Authorization": r.Header["Proxy-Authorization"]}}
+			if u, p, ok := req.BasicAuth(); !ok || u != user || p != pass {
+				w.WriteHeader(http.StatusProxyAuthRequired)
+				return
+			}
+		}
+		if r.Method == http.MethodConnect {
+			target, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer target.Close()
+			client, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer client.Close()
+			client.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+			go io.Copy(target, buf)
+			io.Copy(client, target)
+			return
+		}
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		if r.Header.Get(SessionHeader) != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-Upstream", srv.Listener.Addr().String())
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func proxyFor(t *testing.T, rawURL string) parse.Proxy {
+	t.Helper()
+	p, ok := parse.Token(rawURL)
+	if !ok {
+		t.Fatalf("proxy tidak valid: %s", rawURL)
+	}
+	return p
+}
+
+// deadProxy mengembalikan alamat yang tidak menerima koneksi.
+func deadProxy(t *testing.T) parse.Proxy {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return proxyFor(t, addr)
+}
+
+func TestServeForward(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "halo")
+	}))
+	defer target.Close()
+	upstream := fakeUpstream(t, "", "")
+
+	good, dead := proxyFor(t, upstream.Listener.Addr().String()), deadProxy(t)
+	p := pool.New(pool.Options{MaxFailures: 1})
+	p.Add(pool.Entry{Proxy: good, Score: 1}, pool.Entry{Proxy: dead, Score: 1})
+	var successes, failures atomic.Int32
+	gw := httptest.NewServer(New(p, Options{
+		DialTimeout:       time.Second,
+		OnUpstreamSuccess: func(parse.Proxy, time.Duration) { successes.Add(1) },
+		OnUpstreamError:   func(parse.Proxy, error) { failures.Add(1) },
+	}))
+	defer gw.Close()
+
+	gwURL, _ := url.Parse(gw.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(gwURL)}}
+	// Upstream mati dicoba paling banyak sekali lalu dibuang; request tetap
+	// berhasil lewat upstream lain
+	for range 5 {
+		resp, err := client.Get(target.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "halo" || resp.Header.Get("X-Upstream") != upstream.Listener.Addr().String() {
+			t.Fatalf("response = %d %q, header %v", resp.StatusCode, body, resp.Header)
+		}
+	}
+	if e, ok := p.Get(pool.KeyOf(good)); !ok || e.Failures != 0 {
+		t.Errorf("upstream hidup = %+v, %v", e, ok)
+	}
+	if successes.Load() != 5 || failures.Load() > 1 {
+		t.Errorf("hook: %d sukses %d gagal, ingin 5 sukses dan paling banyak 1 gagal", successes.Load(), failures.Load())
+	}
+
+	// Tanpa upstream hidup gateway menjawab 502, lalu 503 setelah pool kosong
+	p.Replace([]pool.Entry{{Proxy: dead, Score: 1}})
+	for _, want := range []int{http.StatusBadGateway, http.StatusServiceUnavailable} {
+		resp, err := client.Get(target.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("status = %d, want %d", resp.StatusCode, want)
+		}
+	}
+}
+
+func TestServeConnect(t *testing.T) {
+	// Server echo sebagai tujuan tunnel
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() { io.Copy(conn, conn); conn.Close() }()
+		}
+	}()
+
+	upstream := fakeUpstream(t, "user", "rahasia")
+	p := pool.New(pool.Options{})
+	p.Add(pool.Entry{Proxy: proxyFor(t, "user:rahasia@"+upstream.Listener.Addr().String()), Score: 1})
+	gw := httptest.NewServer(New(p, Options{}))
+	defer gw.Close()
+
+	conn, err := net.Dial("tcp", gw.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	addr := echo.Addr().String()
+	io.WriteString(conn, "CONNECT "+addr+" HTTP/1.1\r\nHost: "+addr+"\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("CONNECT = %v, %v", resp, err)
+	}
+	io.WriteString(conn, "ping\n")
+	line, err := br.ReadString('\n')
+	if err != nil || line != "ping\n" {
+		t.Errorf("echo = %q, %v", line, err)
+	}
+}
+
+func TestDialRejected(t *testing.T) {
+	upstream := fakeUpstream(t, "user", "rahasia")
+	wrong := proxyFor(t, "user:salah@"+upstream.Listener.Addr().String())
+	_, err := Dial(context.Background(), wrong, "127.0.0.1:1", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "407") {
+		t.Errorf("err = %v, want 407", err)
+	}
+}
+
+func TestServeSticky(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	p := pool.New(pool.Options{MaxFailures: 1})
+	for range 4 {
+		p.Add(pool.Entry{Proxy: proxyFor(t, fakeUpstream(t, "", "").Listener.Addr().String()), Score: 1})
+	}
+	gw := httptest.NewServer(New(p, Options{Affinity: pool.NewAffinity(time.Minute)}))
+	defer gw.Close()
+
+	get := func(session string) string {
+		t.Helper()
+		gwURL, _ := url.Parse(gw.URL)
+		if session != "" {
+			gwURL.User = url.User(session)
+		}
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(gwURL)}}
+		resp, err := client.Get(target.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.Header.Get("X-Upstream")
+	}
+
+	// Sesi yang sama selalu keluar lewat upstream yang sama
+	first := get("sesi-1")
+	for range 10 {
+		if got := get("sesi-1"); got != first {
+			t.Fatalf("sesi-1 pindah dari %s ke %s", first, got)
+		}
+	}
+
+	// Upstream dibuang: sesi pindah ke satu pengganti lalu menetap di sana
+	bound, _ := parse.Token(first)
+	p.Remove(pool.KeyOf(bound))
+	second := get("sesi-1")
+	if second == first {
+		t.Fatal("sesi-1 masih memakai upstream yang sudah dibuang")
+	}
+	for range 5 {
+		if got := get("sesi-1"); got != second {
+			t.Fatalf("pengganti sesi-1 berubah dari %s ke %s", second, got)
+		}
+	}
+}
+
+func TestServeRateLimit(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "halo")
+	}))
+	defer target.Close()
+	upstream := fakeUpstream(t, "", "")
+
+	p := pool.New(pool.Options{})
+	p.Add(pool.Entry{Proxy: proxyFor(t, upstream.Listener.Addr().String()), Score: 1})
+	var waits []string
+	gw := httptest.NewServer(New(p, Options{RateLimit: ratelimit.New(ratelimit.Config{
+		PerHost: ratelimit.Rate{PerSecond: 20, Burst: 1},
+		OnWait: func(scope string, _ time.Duration) {
+			waits = append(waits, scope)
+		},
+	})}))
+	defer gw.Close()
+
+	gwURL, _ := url.Parse(gw.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(gwURL)}}
+	// Tiga request berurutan ke satu host pada 20/detik butuh minimal 100ms
+	start := time.Now()
+	for range 3 {
+		resp, err := client.Get(target.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 request selesai dalam %v, batas per host tidak dipakai", elapsed)
+	}
+	if len(waits) == 0 || waits[0] != ratelimit.ScopeHost {
+		t.Errorf("OnWait = %v, ingin host", waits)
+	}
+}
@@ -2,21 +2,34 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"sync"
 	"time"
+
+	"deep.go/check"
+	"deep.go/limiter"
+	"deep.go/logging"
+	"deep.go/pager"
+	"deep.go/parse"
+	"deep.go/progress"
+	"deep.go/ratelimit"
+	"deep.go/reason"
 )
 
 // --- KONFIGURASI ---
 
 // workerCount adalah jumlah goroutine yang akan memeriksa proxy secara bersamaan.
-// Nilai yang lebih tinggi akan mempercepat proses tetapi membutuhkan lebih banyak sumber daya.
-// Sesuaikan dengan kemampuan jaringan dan CPU Anda.
+// Nilai ini sekarang menjadi batas atas: limiter adaptif memulai dengan jumlah
+// pengecekan yang lebih kecil dan menaikkannya selama tingkat error, file descriptor,
+// dan port lokal masih sehat, lalu mundur saat mesin mulai jenuh.
 const workerCount = 150
 
 // checkTimeout adalah batas waktu untuk setiap permintaan pengecekan proxy.
@@ -31,39 +44,81 @@ const outputFile = "gemini_live_proxies.txt"
 // memastikan proxy tidak hanya terhubung tetapi juga berfungsi dengan benar (tidak transparan).
 const checkURL = "https://api.ipify.org"
 
+// proxySource adalah satu sumber proxy. Params menjabarkan satu definisi
+// menjadi beberapa endpoint, misalnya satu per protokol, dan Pagination
+// dipakai untuk sumber yang membagi hasilnya ke beberapa halaman.
+type proxySource struct {
+	URL        string
+	Params     map[string][]string
+	Pagination *pager.Pagination
+}
+
 // proxySources adalah daftar sumber proxy gratis.
 // Sumber-sumber ini sering berubah atau tidak dapat diandalkan.
 // Anda mungkin perlu mencari dan memperbarui daftar ini secara berkala untuk hasil terbaik.
-var proxySources = []string{
-	"https://api.proxyscrape.com/v2/?request=getproxies&protocol=http&timeout=10000&country=all&ssl=all&anonymity=all",
-	"https://raw.githubusercontent.com/TheSpeedX/PROXY-List/master/http.txt",
-	"https://raw.githubusercontent.com/monosans/proxy-list/main/proxies/http.txt",
-	"https://raw.githubusercontent.com/jetkai/proxy-list/main/online-proxies/txt/proxies-http.txt",
-	"https://raw.githubusercontent.com/clarketm/proxy-list/master/proxy-list-raw.txt",
-	"https://raw.githubusercontent.com/officialputuid/KangProxy/KangProxy/http/http.txt",
-	"https://raw.githubusercontent.com/UptimerBot/proxy-list/main/proxies/http.txt",
+var proxySources = []proxySource{
+	{
+		URL: "https://api.proxyscrape.com/v2/?request=getproxies&timeout=10000&country=all&ssl=all&anonymity=all",
+		// SOCKS4 tidak didukung net/http, jadi hanya http dan socks5 yang diambil
+		Params: map[string][]string{"protocol": {"http", "socks5"}},
+	},
+	{URL: "https://raw.githubusercontent.com/TheSpeedX/PROXY-List/master/http.txt"},
+	{URL: "https://raw.githubusercontent.com/monosans/proxy-list/main/proxies/http.txt"},
+	{URL: "https://raw.githubusercontent.com/jetkai/proxy-list/main/online-proxies/txt/proxies-http.txt"},
+	{URL: "https://raw.githubusercontent.com/clarketm/proxy-list/master/proxy-list-raw.txt"},
+	{URL: "https://raw.githubusercontent.com/officialputuid/KangProxy/KangProxy/http/http.txt"},
+	{URL: "https://raw.githubusercontent.com/UptimerBot/proxy-list/main/proxies/http.txt"},
 }
 
+// failureReasons menghitung alasan kegagalan pengecekan untuk ringkasan akhir.
+var failureReasons reason.Counter
+
+// tracker menampilkan progres scraping dan pengecekan yang berjalan bersamaan.
+var tracker *progress.Tracker
+
 // --- UTAMA ---
 
 func main() {
-	// Mengatur log untuk tampilan yang lebih bersih tanpa timestamp default.
-	log.SetFlags(0)
+	quiet := flag.Bool("quiet", false, "jangan tampilkan progres pengecekan")
+	verbose := flag.Bool("verbose", false, "tampilkan juga setiap proxy yang dicek")
+	rateLimits := flag.String("rate-limits", "", "file JSON batas laju request tes per proxy, per host URL tes dan global")
+	logFlags := logging.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	// Progres ditulis ke stderr, dan log ditulis lewat progres agar tidak
+	// menimpa baris status. Dengan -log-format json log JSON ditulis
+	// langsung ke stdout agar tidak bercampur dengan ringkasan progres.
+	tracker = progress.New(os.Stderr, progress.LevelFromFlags(*quiet, *verbose))
+	var logOut io.Writer = tracker
+	if *logFlags.Format == "json" {
+		logOut = os.Stdout
+	}
+	if err := logFlags.Setup(logOut, *quiet, *verbose); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Batas laju dibaca sebelum scraping agar file yang salah langsung ketahuan
+	rl, err := loadRateLimits(*rateLimits)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	// Membersihkan file output lama jika ada.
 	_ = os.Remove(outputFile)
 
 	// Channel untuk menampung proxy yang di-scrape dari sumber.
-	scrapedProxiesChan := make(chan string, workerCount*10)
+	scrapedProxiesChan := make(chan parse.Proxy, workerCount*10)
 	// Channel untuk menampung proxy yang lolos pengecekan.
-	liveProxiesChan := make(chan string, workerCount)
+	liveProxiesChan := make(chan parse.Proxy, workerCount)
 
 	// WaitGroup untuk sinkronisasi goroutine.
 	var wgScrapers sync.WaitGroup
 	var wgCheckers sync.WaitGroup
 
-	log.Println("🚀 Memulai proses scraping dan pengecekan proxy...")
-	log.Printf("🔩 Konfigurasi: %d workers, %s timeout\n\n", workerCount, checkTimeout)
+	logging.Info(logging.RunStart)
+	logging.Info(logging.RunConfig, "workers", workerCount, "timeout", checkTimeout.String())
 
 	// 1. Jalankan goroutine untuk mengumpulkan proxy yang aktif dan menyimpannya ke file.
 	// Goroutine ini berjalan di latar belakang, menunggu proxy aktif masuk.
@@ -73,16 +128,36 @@ func main() {
 
 	// 2. Jalankan goroutine pekerja (checker) sebanyak workerCount.
 	// Mereka akan mengambil proxy dari scrapedProxiesChan dan memeriksanya.
+	// Limiter dibagi oleh semua pekerja sehingga jumlah pengecekan aktif
+	// menyesuaikan diri dengan kapasitas mesin.
+	// Pengecekan memakai package check yang sama dengan CLI utama; body
+	// dari checkURL harus tepat satu alamat IP.
+	lim := limiter.New(limiter.Config{Max: workerCount})
+	checker := check.New(check.Options{
+		Timeout:   checkTimeout,
+		TestURLs:  []string{checkURL},
+		ValidBody: isIPBody,
+		RateLimit: rl,
+	})
 	wgCheckers.Add(workerCount)
 	for i := 1; i <= workerCount; i++ {
-		go checkProxyWorker(i, scrapedProxiesChan, liveProxiesChan, &wgCheckers)
+		go checkProxyWorker(i, checker, lim, scrapedProxiesChan, liveProxiesChan, &wgCheckers)
 	}
 
 	// 3. Scrape proxy dari semua sumber secara bersamaan.
-	log.Printf("🔍 Scraping proxy dari %d sumber...\n", len(proxySources))
-	for _, sourceURL := range proxySources {
-		wgScrapers.Add(1)
-		go scrapeProxies(sourceURL, scrapedProxiesChan, &wgScrapers)
+	// Setiap sumber dijabarkan dulu menjadi semua endpoint-nya.
+	logging.Info(logging.ScrapeAllStart, logging.KeyCount, len(proxySources))
+	tracker.Start()
+	for _, source := range proxySources {
+		urls, err := pager.Expand(source.URL, source.Params)
+		if err != nil {
+			logging.Warn(logging.ScrapeFailed, logging.KeySource, source.URL, logging.KeyError, err)
+			continue
+		}
+		for _, sourceURL := range urls {
+			wgScrapers.Add(1)
+			go scrapeSource(sourceURL, source.Pagination, scrapedProxiesChan, &wgScrapers)
+		}
 	}
 
 	// 4. Tunggu semua proses scraping selesai.
@@ -90,108 +165,174 @@ func main() {
 	// Setelah scraping selesai, tutup scrapedProxiesChan.
 	// Ini akan memberitahu para checker bahwa tidak ada lagi proxy yang akan datang.
 	close(scrapedProxiesChan)
-	log.Println("\n✅ Semua sumber telah selesai di-scrape.")
+	logging.Info(logging.ScrapeAllDone)
 
 	// 5. Tunggu semua checker selesai bekerja.
 	wgCheckers.Wait()
+	tracker.Stop()
 	// Setelah checker selesai, tutup liveProxiesChan.
 	// Ini akan memberitahu kolektor untuk berhenti dan menyelesaikan penulisan file.
 	close(liveProxiesChan)
-	log.Println("✅ Semua proxy telah selesai dicek.")
+	logging.Info(logging.CheckAllDone)
+	stats := lim.Stats()
+	logging.Info(logging.LimiterStats, "limit", stats.Limit, "peak", stats.Peak, "backoffs", stats.Backoffs)
+	for _, e := range failureReasons.Sorted() {
+		logging.Info(logging.ReasonCount, logging.KeyReason, string(e.Reason), logging.KeyCount, e.Count)
+	}
 
 	// 6. Tunggu goroutine kolektor selesai menulis file.
 	collectorWg.Wait()
 
-	log.Printf("\n🎉 Selesai! Proxy yang aktif disimpan di file: %s\n", outputFile)
+	logging.Info(logging.RunDone, logging.KeyFile, outputFile)
+}
+
+// loadRateLimits membaca -rate-limits; path kosong berarti tanpa batas.
+func loadRateLimits(path string) (*ratelimit.Limiter, error) {
+	if path == "" {
+		return nil, nil
+	}
+	cfg, err := ratelimit.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca -rate-limits: %w", err)
+	}
+	return ratelimit.New(cfg), nil
 }
 
 // --- FUNGSI-FUNGSI ---
 
-// scrapeProxies mengambil daftar proxy dari satu URL sumber dan mengirimkannya ke channel.
-func scrapeProxies(sourceURL string, proxiesChan chan<- string, wg *sync.WaitGroup) {
+// scrapeSource mengambil semua halaman dari satu endpoint sumber.
+func scrapeSource(sourceURL string, pagination *pager.Pagination, proxiesChan chan<- parse.Proxy, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	resp, err := http.Get(sourceURL)
+	total := 0
+	_, err := pager.Walk(sourceURL, pagination, func(pageURL string) (pager.Result, error) {
+		count, err := scrapeProxies(pageURL, proxiesChan)
+		total += count
+		return pager.Result{Count: count}, err
+	})
 	if err != nil {
-		log.Printf("   [SCRAPE GAGAL] %s: %v\n", sourceURL, err)
+		tracker.Source(sourceLabel(sourceURL), "❌")
+		logging.Warn(logging.ScrapeFailed, logging.KeySource, sourceURL, logging.KeyError, err)
 		return
 	}
+	tracker.Source(sourceLabel(sourceURL), fmt.Sprintf("✅ %d", total))
+	logging.Info(logging.ScrapeDone, logging.KeySource, sourceURL, logging.KeyCount, total)
+}
+
+// scrapeProxies mengambil daftar proxy dari satu URL dan mengirimkannya ke channel.
+// Untuk endpoint dengan parameter protocol non-http, skema ikut ditulis di depan
+// alamat agar checker memakai protokol yang benar. Setiap baris diurai dengan
+// parse.Token sehingga kredensial dan IPv6 dikenali; baris yang tidak bisa
+// diurai dihitung sebagai gagal.
+func scrapeProxies(sourceURL string, proxiesChan chan<- parse.Proxy) (int, error) {
+	resp, err := http.Get(sourceURL)
+	if err != nil {
+		return 0, err
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("   [SCRAPE GAGAL] %s: Status code %d\n", sourceURL, resp.StatusCode)
-		return
+		return 0, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	prefix := ""
+	if u, err := url.Parse(sourceURL); err == nil {
+		if scheme := u.Query().Get("protocol"); scheme != "" && scheme != "http" {
+			prefix = scheme + "://"
+		}
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
 	count := 0
 	for scanner.Scan() {
-		proxy := strings.TrimSpace(scanner.Text())
-		if proxy != "" {
-			proxiesChan <- proxy
-			count++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		tracker.AddTotal(1)
+		count++
+		if !strings.Contains(line, "://") {
+			line = prefix + line
 		}
+		proxy, ok := parse.Token(line)
+		if !ok {
+			fail(line, reason.Other, time.Now())
+			continue
+		}
+		proxiesChan <- proxy
 	}
+	return count, scanner.Err()
+}
 
-	if err := scanner.Err(); err != nil {
-		log.Printf("   [SCRAPE ERROR] %s: %v\n", sourceURL, err)
-	} else {
-		log.Printf("   [SCRAPE SUKSES] %d proxy dari %s\n", count, sourceURL)
+// sourceLabel memendekkan URL sumber untuk tampilan progres: host dan,
+// untuk endpoint hasil ekspansi, nilai protocol-nya.
+func sourceLabel(sourceURL string) string {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return sourceURL
+	}
+	label := u.Host
+	if u.Host == "raw.githubusercontent.com" {
+		if parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2); parts[0] != "" {
+			label = parts[0]
+		}
+	}
+	if proto := u.Query().Get("protocol"); proto != "" {
+		label += "/" + proto
 	}
+	return label
 }
 
 // checkProxyWorker adalah pekerja yang mengambil proxy dari channel, memeriksanya,
 // dan mengirimkan yang aktif ke channel liveProxies.
-func checkProxyWorker(id int, proxiesChan <-chan string, liveProxiesChan chan<- string, wg *sync.WaitGroup) {
+func checkProxyWorker(id int, checker *check.Checker, lim *limiter.Limiter, proxiesChan <-chan parse.Proxy, liveProxiesChan chan<- parse.Proxy, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	// Terus bekerja selama channel 'proxiesChan' masih terbuka dan berisi data.
-	for proxyAddr := range proxiesChan {
-		// Log awal untuk menunjukkan proxy sedang diproses.
-		// fmt.Printf("🤔 [Worker %d] Mengecek -> %s\n", id, proxyAddr)
-
-		proxyURL, err := url.Parse("http://" + proxyAddr)
-		if err != nil {
-			// Jika format proxy tidak valid, lewati.
+	for proxy := range proxiesChan {
+		// Tunggu slot dari limiter sebelum membuka koneksi.
+		if err := lim.Acquire(context.Background()); err != nil {
 			continue
 		}
 
-		// Konfigurasi transport HTTP dengan proxy dan timeout
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
-		}
-
-		// Konfigurasi client HTTP
-		client := &http.Client{
-			Transport: transport,
-			Timeout:   checkTimeout,
-		}
-
 		// Lakukan permintaan GET ke URL target untuk validasi.
 		// Ini adalah "double check" kita: tidak hanya terhubung, tapi juga bisa mengambil konten.
-		resp, err := client.Get(checkURL)
-		if err != nil {
-			// Jika ada error (timeout, koneksi ditolak, dll), proxy dianggap mati.
-			// log.Printf("   ☠️ [MATI] %s: %v\n", proxyAddr, err)
+		start := time.Now()
+		result := checker.Check(context.Background(), proxy)
+		lim.Release(limiter.Classify(result.Err))
+		if !result.Valid {
+			// Alasannya dihitung, bukan dicetak, agar log tidak dibanjiri.
+			fail(proxy.String(), result.Reason, start)
 			continue
 		}
-		resp.Body.Close() // Pastikan untuk selalu menutup body.
-
-		// Pastikan status code adalah 200 OK.
-		if resp.StatusCode == http.StatusOK {
-			log.Printf("   ✔️ [AKTIF] %s\n", proxyAddr)
-			liveProxiesChan <- proxyAddr
-		}
+		latency := time.Since(start)
+		tracker.Done(true, "", latency)
+		logging.Debug(logging.CheckValid, logging.KeyProxy, proxy.String(), logging.KeyLatency, latency.Milliseconds())
+		liveProxiesChan <- proxy
 	}
 }
 
+// fail mencatat satu proxy yang gagal dicek untuk ringkasan dan progres.
+func fail(proxyAddr string, r reason.Reason, start time.Time) {
+	failureReasons.Add(r)
+	latency := time.Since(start)
+	tracker.Done(false, string(r), latency)
+	logging.Debug(logging.CheckInvalid, logging.KeyProxy, proxyAddr, logging.KeyReason, string(r),
+		logging.KeyLatency, latency.Milliseconds())
+}
+
+// isIPBody memastikan body dari checkURL hanya berisi satu alamat IP.
+func isIPBody(body []byte) bool {
+	return net.ParseIP(strings.TrimSpace(string(body))) != nil
+}
+
 // collectAndSaveLiveProxies mengambil proxy aktif dari channel dan menyimpannya ke file.
-func collectAndSaveLiveProxies(liveProxiesChan <-chan string, wg *sync.WaitGroup) {
+func collectAndSaveLiveProxies(liveProxiesChan <-chan parse.Proxy, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	file, err := os.Create(outputFile)
 	if err != nil {
-		log.Fatalf("❌ FATAL: Gagal membuat file output '%s': %v", outputFile, err)
+		logging.Fatal(logging.SaveFailed, logging.KeyFile, outputFile, logging.KeyError, err)
 	}
 	defer file.Close()
 
@@ -201,12 +342,13 @@ func collectAndSaveLiveProxies(liveProxiesChan <-chan string, wg *sync.WaitGroup
 
 	// Terus bekerja selama channel 'liveProxiesChan' masih terbuka.
 	for proxy := range liveProxiesChan {
-		// Pastikan tidak ada duplikat.
-		if _, exists := uniqueProxies[proxy]; !exists {
-			uniqueProxies[proxy] = true
-			_, err := fmt.Fprintln(writer, proxy)
+		// Pastikan tidak ada duplikat. Kredensial ikut ditulis agar proxy
+		// privat tetap bisa dipakai dari file.
+		if _, exists := uniqueProxies[proxy.Key()]; !exists {
+			uniqueProxies[proxy.Key()] = true
+			_, err := fmt.Fprintln(writer, proxy.Export())
 			if err != nil {
-				log.Printf("❌ Gagal menulis proxy ke file: %v", err)
+				logging.Error(logging.SaveFailed, logging.KeyFile, outputFile, logging.KeyProxy, proxy.String(), logging.KeyError, err)
 			} else {
 				count++
 			}
@@ -215,5 +357,5 @@ func collectAndSaveLiveProxies(liveProxiesChan <-chan string, wg *sync.WaitGroup
 
 	// Pastikan semua buffer tertulis ke file.
 	writer.Flush()
-	log.Printf("\n💾 Sebanyak %d proxy unik yang aktif berhasil disimpan.", count)
+	logging.Info(logging.Saved, logging.KeyFile, outputFile, logging.KeyCount, count)
 }
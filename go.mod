@@ -2,9 +2,9 @@ module deep.go
 
 go 1.24.1
 
-require github.com/PuerkitoBio/goquery v1.10.3
-
 require (
-	github.com/andybalholm/cascadia v1.3.3 // indirect
-	golang.org/x/net v0.39.0 // indirect
+	github.com/PuerkitoBio/goquery v1.10.3
+	golang.org/x/net v0.39.0
 )
+
+require github.com/andybalholm/cascadia v1.3.3 // indirect
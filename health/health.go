@@ -0,0 +1,343 @@
+// Package health menyimpan statistik hasil setiap sumber proxy dari run ke
+// run, sehingga sumber yang terus-menerus tidak menghasilkan proxy valid
+// bisa dikenali, diturunkan prioritasnya, atau dinonaktifkan otomatis.
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"deep.go/logging"
+)
+
+// maxRuns adalah jumlah run terakhir yang disimpan per sumber.
+const maxRuns = 30
+
+// RunStats adalah hasil satu sumber pada satu run.
+type RunStats struct {
+	Time       time.Time `json:"time"`
+	FetchOK    bool      `json:"fetch_ok"`
+	FetchError string    `json:"fetch_error,omitempty"`
+	// Cache adalah status cache sumber: fresh, not_modified atau stale
+	Cache string `json:"cache,omitempty"`
+	// Raw adalah jumlah proxy hasil parsing sebelum normalisasi
+	Raw int `json:"raw"`
+	// Normalized adalah jumlah proxy yang tersisa setelah normalisasi dan filter
+	Normalized int `json:"normalized"`
+	// Unique adalah jumlah proxy yang hanya disediakan oleh sumber ini
+	Unique  int `json:"unique"`
+	Checked int `json:"checked"`
+	Valid   int `json:"valid"`
+	// MedianLatencyMs adalah median latency proxy valid dari sumber ini
+	MedianLatencyMs float64 `json:"median_latency_ms,omitempty"`
+}
+
+// Source adalah riwayat kesehatan satu sumber.
+type Source struct {
+	Name           string     `json:"name"`
+	Runs           []RunStats `json:"runs"`
+	Disabled       bool       `json:"disabled,omitempty"`
+	DisabledReason Reason     `json:"disabled_reason,omitzero"`
+	DisabledAt     time.Time  `json:"disabled_at,omitempty"`
+}
+
+// Kode alasan sumber dinonaktifkan.
+const (
+	ReasonFetchFailed  = "fetch_failed"
+	ReasonNoValid      = "no_valid"
+	ReasonLowValidRate = "low_valid_rate"
+)
+
+// Reason adalah alasan sumber dinonaktifkan. Yang disimpan adalah kode dan
+// angkanya, bukan teks, agar alasan ditampilkan dalam bahasa -lang.
+type Reason struct {
+	Code         string  `json:"code"`
+	Runs         int     `json:"runs,omitempty"`
+	ValidRate    float64 `json:"valid_rate,omitempty"`
+	MinValidRate float64 `json:"min_valid_rate,omitempty"`
+	// Text adalah alasan berbentuk teks dari file kesehatan versi lama.
+	Text string `json:"-"`
+}
+
+// String mengembalikan alasan dalam bahasa aktif.
+func (r Reason) String() string {
+	switch r.Code {
+	case ReasonFetchFailed:
+		return logging.T(logging.DisabledFetchFailed, r.Runs)
+	case ReasonNoValid:
+		return logging.T(logging.DisabledNoValid, r.Runs)
+	case ReasonLowValidRate:
+		return logging.T(logging.DisabledLowValidRate, 100*r.ValidRate, 100*r.MinValidRate)
+	}
+	return r.Text
+}
+
+// UnmarshalJSON juga menerima alasan berbentuk string dari versi lama.
+func (r *Reason) UnmarshalJSON(data []byte) error {
+	*r = Reason{}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &r.Text)
+	}
+	type plain Reason
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// Summary adalah ringkasan statistik satu sumber atas run yang tersimpan.
+type Summary struct {
+	Name            string
+	Runs            int
+	FetchRate       float64 // rasio fetch yang berhasil
+	AvgRaw          float64
+	AvgNormalized   float64
+	AvgUnique       float64
+	ValidRate       float64 // valid / checked
+	TotalValid      int
+	MedianLatencyMs float64
+	LastValid       time.Time
+	Score           float64
+	Disabled        bool
+	DisabledReason  Reason
+}
+
+// Threshold mengatur kapan sumber dinonaktifkan.
+type Threshold struct {
+	// MinRuns adalah jumlah run minimum sebelum sumber boleh dinonaktifkan.
+	MinRuns int
+	// MinValidRate adalah tingkat validitas minimum atas MinRuns run terakhir.
+	// Sumber yang tidak menghasilkan proxy valid sama sekali selalu dinonaktifkan.
+	MinValidRate float64
+	// RetryAfter adalah jeda sebelum sumber nonaktif dicoba lagi sekali.
+	RetryAfter time.Duration
+}
+
+// DefaultThreshold menonaktifkan sumber yang tidak menghasilkan proxy valid
+// dalam 5 run terakhir dan mencobanya lagi setelah seminggu.
+var DefaultThreshold = Threshold{
+	MinRuns:    5,
+	RetryAfter: 7 * 24 * time.Hour,
+}
+
+// Store adalah kumpulan riwayat sumber yang disimpan di file JSON.
+type Store struct {
+	path    string
+	Sources map[string]*Source `json:"sources"`
+}
+
+// Load membaca store dari path. File yang belum ada menghasilkan store kosong.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, Sources: make(map[string]*Source)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Sources == nil {
+		s.Sources = make(map[string]*Source)
+	}
+	return s, nil
+}
+
+// Save menulis store ke file secara atomik.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".health-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *Store) source(name string) *Source {
+	src, ok := s.Sources[name]
+	if !ok {
+		src = &Source{Name: name}
+		s.Sources[name] = src
+	}
+	return src
+}
+
+// Record menambahkan hasil run untuk sumber name.
+func (s *Store) Record(name string, run RunStats) {
+	src := s.source(name)
+	src.Runs = append(src.Runs, run)
+	if len(src.Runs) > maxRuns {
+		src.Runs = src.Runs[len(src.Runs)-maxRuns:]
+	}
+}
+
+// ShouldFetch melaporkan apakah sumber perlu diambil pada run ini. Sumber
+// nonaktif tetap dicoba sekali setelah RetryAfter agar bisa pulih.
+func (s *Store) ShouldFetch(name string, t Threshold) bool {
+	src, ok := s.Sources[name]
+	if !ok || !src.Disabled {
+		return true
+	}
+	last := src.DisabledAt
+	if n := len(src.Runs); n > 0 && src.Runs[n-1].Time.After(last) {
+		last = src.Runs[n-1].Time
+	}
+	return t.RetryAfter > 0 && time.Since(last) >= t.RetryAfter
+}
+
+// Evaluate menonaktifkan sumber yang berada di bawah ambang dan mengaktifkan
+// kembali sumber nonaktif yang run terakhirnya menghasilkan proxy valid.
+// Nama sumber yang berubah status dikembalikan.
+func (s *Store) Evaluate(t Threshold) []string {
+	var changed []string
+	for name, src := range s.Sources {
+		if src.Disabled {
+			if n := len(src.Runs); n > 0 && src.Runs[n-1].Time.After(src.DisabledAt) && src.Runs[n-1].Valid > 0 {
+				src.Disabled, src.DisabledReason, src.DisabledAt = false, Reason{}, time.Time{}
+				changed = append(changed, name)
+			}
+			continue
+		}
+		if len(src.Runs) < t.MinRuns || t.MinRuns <= 0 {
+			continue
+		}
+
+		recent := src.Runs[len(src.Runs)-t.MinRuns:]
+		fetched, checked, valid := 0, 0, 0
+		for _, run := range recent {
+			if run.FetchOK {
+				fetched++
+			}
+			checked += run.Checked
+			valid += run.Valid
+		}
+
+		reason := Reason{Runs: t.MinRuns}
+		switch {
+		case fetched == 0:
+			reason.Code = ReasonFetchFailed
+		case valid == 0:
+			reason.Code = ReasonNoValid
+		case checked > 0 && float64(valid)/float64(checked) < t.MinValidRate:
+			reason.Code = ReasonLowValidRate
+			reason.ValidRate = float64(valid) / float64(checked)
+			reason.MinValidRate = t.MinValidRate
+		}
+		if reason.Code != "" {
+			src.Disabled, src.DisabledReason, src.DisabledAt = true, reason, time.Now()
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// FailureStreak mengembalikan jumlah run terakhir berturut-turut di mana
+// sumber gagal diambil atau tidak menghasilkan proxy valid.
+func (s *Store) FailureStreak(name string) int {
+	src, ok := s.Sources[name]
+	if !ok {
+		return 0
+	}
+	streak := 0
+	for i := len(src.Runs) - 1; i >= 0; i-- {
+		if src.Runs[i].FetchOK && src.Runs[i].Valid > 0 {
+			break
+		}
+		streak++
+	}
+	return streak
+}
+
+// Summarize menghitung ringkasan statistik satu sumber.
+func (s *Store) Summarize(name string) Summary {
+	sum := Summary{Name: name}
+	src, ok := s.Sources[name]
+	if !ok {
+		return sum
+	}
+	sum.Disabled, sum.DisabledReason = src.Disabled, src.DisabledReason
+	sum.Runs = len(src.Runs)
+	if sum.Runs == 0 {
+		return sum
+	}
+
+	fetched, checked := 0, 0
+	var latencies []float64
+	for _, run := range src.Runs {
+		if run.FetchOK {
+			fetched++
+		}
+		sum.AvgRaw += float64(run.Raw)
+		sum.AvgNormalized += float64(run.Normalized)
+		sum.AvgUnique += float64(run.Unique)
+		checked += run.Checked
+		sum.TotalValid += run.Valid
+		if run.Valid > 0 {
+			sum.LastValid = run.Time
+			latencies = append(latencies, run.MedianLatencyMs)
+		}
+	}
+	n := float64(sum.Runs)
+	sum.FetchRate = float64(fetched) / n
+	sum.AvgRaw /= n
+	sum.AvgNormalized /= n
+	sum.AvgUnique /= n
+	if checked > 0 {
+		sum.ValidRate = float64(sum.TotalValid) / float64(checked)
+	}
+	sum.MedianLatencyMs = Median(latencies)
+
+	// Skor mengutamakan sumber yang andal dan menyumbang proxy valid yang
+	// tidak didapat dari sumber lain
+	sum.Score = sum.FetchRate * sum.ValidRate * (1 + sum.AvgUnique/(1+sum.AvgNormalized))
+	return sum
+}
+
+// Ranked mengembalikan ringkasan semua sumber, terurut dari skor tertinggi.
+func (s *Store) Ranked() []Summary {
+	summaries := make([]Summary, 0, len(s.Sources))
+	for name := range s.Sources {
+		summaries = append(summaries, s.Summarize(name))
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Disabled != summaries[j].Disabled {
+			return !summaries[i].Disabled
+		}
+		if summaries[i].Score != summaries[j].Score {
+			return summaries[i].Score > summaries[j].Score
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// Median mengembalikan nilai tengah values, atau 0 jika kosong.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,190 @@
+package health
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"deep.go/logging"
+)
+
+func record(s *Store, name string, runs ...RunStats) {
+	start := time.Now().Add(-time.Duration(len(runs)) * time.Hour)
+	for i, run := range runs {
+		run.Time = start.Add(time.Duration(i) * time.Hour)
+		s.Record(name, run)
+	}
+}
+
+func repeat(run RunStats, n int) []RunStats {
+	runs := make([]RunStats, n)
+	for i := range runs {
+		runs[i] = run
+	}
+	return runs
+}
+
+func TestEvaluate(t *testing.T) {
+	good := RunStats{FetchOK: true, Checked: 100, Valid: 10}
+	tests := []struct {
+		name      string
+		runs      []RunStats
+		threshold Threshold
+		// wantCode kosong berarti sumber tetap aktif
+		wantCode string
+	}{
+		{"sehat", repeat(good, 5), DefaultThreshold, ""},
+		{"run belum cukup", repeat(RunStats{FetchOK: true, Checked: 100}, 4), DefaultThreshold, ""},
+		{"gagal fetch", repeat(RunStats{}, 5), DefaultThreshold, ReasonFetchFailed},
+		{"nol valid", repeat(RunStats{FetchOK: true, Checked: 100}, 5), DefaultThreshold, ReasonNoValid},
+		{"valid lama tidak dihitung", append(repeat(good, 3), repeat(RunStats{FetchOK: true, Checked: 100}, 5)...), DefaultThreshold, ReasonNoValid},
+		{"di bawah MinValidRate", repeat(good, 5), Threshold{MinRuns: 5, MinValidRate: 0.2}, ReasonLowValidRate},
+		{"MinRuns nol", repeat(RunStats{}, 5), Threshold{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Store{Sources: make(map[string]*Source)}
+			record(s, "src", tt.runs...)
+			changed := s.Evaluate(tt.threshold)
+			src := s.Sources["src"]
+			wantDisabled := tt.wantCode != ""
+			if src.Disabled != wantDisabled || src.DisabledReason.Code != tt.wantCode {
+				t.Fatalf("Disabled = %v (%s), ingin %q", src.Disabled, src.DisabledReason.Code, tt.wantCode)
+			}
+			if wantDisabled != (len(changed) == 1) {
+				t.Errorf("changed = %v", changed)
+			}
+		})
+	}
+}
+
+func TestRetryAndRecover(t *testing.T) {
+	s := &Store{Sources: make(map[string]*Source)}
+	record(s, "src", repeat(RunStats{FetchOK: true, Checked: 10}, 5)...)
+	s.Evaluate(DefaultThreshold)
+	if s.ShouldFetch("src", DefaultThreshold) {
+		t.Fatal("sumber nonaktif langsung diambil lagi")
+	}
+	if !s.ShouldFetch("lain", DefaultThreshold) {
+		t.Fatal("sumber tanpa riwayat tidak diambil")
+	}
+
+	// Setelah RetryAfter sumber dicoba sekali, dan aktif lagi jika menghasilkan proxy valid
+	src := s.Sources["src"]
+	src.DisabledAt = src.DisabledAt.Add(-8 * 24 * time.Hour)
+	src.Runs[len(src.Runs)-1].Time = src.DisabledAt
+	if !s.ShouldFetch("src", DefaultThreshold) {
+		t.Fatal("sumber tidak dicoba lagi setelah RetryAfter")
+	}
+	s.Record("src", RunStats{Time: time.Now(), FetchOK: true, Checked: 10, Valid: 1})
+	if changed := s.Evaluate(DefaultThreshold); !slices.Equal(changed, []string{"src"}) || src.Disabled {
+		t.Errorf("sumber tidak pulih: changed %v disabled %v", changed, src.Disabled)
+	}
+}
+
+func TestReason(t *testing.T) {
+	defer logging.SetLang(logging.ID)
+	r := Reason{Code: ReasonLowValidRate, Runs: 5, ValidRate: 0.1, MinValidRate: 0.2}
+	if got, want := r.String(), "tingkat valid 10.00% di bawah ambang 20.00%"; got != want {
+		t.Errorf("String = %q, ingin %q", got, want)
+	}
+	logging.SetLang(logging.EN)
+	if got, want := r.String(), "valid rate 10.00% below threshold 20.00%"; got != want {
+		t.Errorf("String EN = %q, ingin %q", got, want)
+	}
+
+	// File kesehatan lama menyimpan alasan sebagai teks
+	var src Source
+	if err := json.Unmarshal([]byte(`{"name": "a", "disabled": true, "disabled_reason": "gagal fetch 5 run berturut-turut"}`), &src); err != nil {
+		t.Fatal(err)
+	}
+	if got := src.DisabledReason.String(); got != "gagal fetch 5 run berturut-turut" {
+		t.Errorf("alasan lama = %q", got)
+	}
+	data, err := json.Marshal(Source{Name: "b", Disabled: true, DisabledReason: Reason{Code: ReasonNoValid, Runs: 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &src); err != nil || src.DisabledReason != (Reason{Code: ReasonNoValid, Runs: 5}) {
+		t.Errorf("round trip = %+v %v", src.DisabledReason, err)
+	}
+}
+
+func TestFailureStreak(t *testing.T) {
+	s := &Store{Sources: make(map[string]*Source)}
+	good := RunStats{FetchOK: true, Valid: 1}
+	record(s, "src", good, RunStats{}, good, RunStats{FetchOK: true}, RunStats{})
+	if got := s.FailureStreak("src"); got != 2 {
+		t.Errorf("FailureStreak = %d, ingin 2", got)
+	}
+	if got := s.FailureStreak("tidak-ada"); got != 0 {
+		t.Errorf("FailureStreak sumber baru = %d, ingin 0", got)
+	}
+}
+
+func TestSummarizeAndRanked(t *testing.T) {
+	s := &Store{Sources: make(map[string]*Source)}
+	record(s, "a",
+		RunStats{FetchOK: true, Normalized: 10, Unique: 10, Checked: 10, Valid: 5, MedianLatencyMs: 100},
+		RunStats{FetchOK: false},
+	)
+	record(s, "b", RunStats{FetchOK: true, Normalized: 10, Checked: 10, Valid: 5, MedianLatencyMs: 300})
+	record(s, "c", RunStats{FetchOK: true, Checked: 10, Valid: 10})
+	s.Sources["c"].Disabled = true
+
+	sum := s.Summarize("a")
+	if sum.Runs != 2 || sum.FetchRate != 0.5 || sum.ValidRate != 0.5 || sum.AvgUnique != 5 || sum.MedianLatencyMs != 100 {
+		t.Errorf("Summarize(a) = %+v", sum)
+	}
+	// a: 0.5 * 0.5 * (1 + 5/6), b: 1 * 0.5 * 1
+	if want := 0.25 * (1 + 5.0/6); math.Abs(sum.Score-want) > 1e-9 {
+		t.Errorf("Score(a) = %v, ingin %v", sum.Score, want)
+	}
+
+	var names []string
+	for _, r := range s.Ranked() {
+		names = append(names, r.Name)
+	}
+	// Sumber nonaktif selalu di akhir walaupun skornya tertinggi
+	if want := []string{"b", "a", "c"}; !slices.Equal(names, want) {
+		t.Errorf("Ranked = %v, ingin %v", names, want)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.json")
+	s, err := Load(path)
+	if err != nil || len(s.Sources) != 0 {
+		t.Fatalf("Load file baru = %v %v", s, err)
+	}
+	record(s, "src", repeat(RunStats{FetchOK: true, Valid: 1}, maxRuns+5)...)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(loaded.Sources["src"].Runs); n != maxRuns {
+		t.Errorf("run tersimpan = %d, ingin %d", n, maxRuns)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	for _, tt := range []struct {
+		in   []float64
+		want float64
+	}{
+		{nil, 0},
+		{[]float64{3}, 3},
+		{[]float64{5, 1, 3}, 3},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	} {
+		if got := Median(tt.in); got != tt.want {
+			t.Errorf("Median(%v) = %v, ingin %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package input membaca daftar proxy lokal untuk subcommand check: file,
+// glob, direktori, stdin ("-") dan URL. Input terkompresi gzip atau zstd
+// dikenali dari magic byte sehingga stdin terkompresi juga bisa dibaca.
+package input
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Stdin adalah argumen yang berarti baca dari standard input.
+const Stdin = "-"
+
+// Item adalah satu input yang sudah dibaca dan didekompresi.
+type Item struct {
+	Name string
+	Data []byte
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Expand menjabarkan argumen menjadi daftar input: glob dicocokkan,
+// direktori dibaca rekursif (file dan direktori tersembunyi dilewati),
+// sedangkan "-" dan URL dibiarkan apa adanya.
+func Expand(args []string) ([]string, error) {
+	var names []string
+	for _, arg := range args {
+		if arg == Stdin || isURL(arg) {
+			names = append(names, arg)
+			continue
+		}
+
+		info, err := os.Stat(arg)
+		if err != nil {
+			if !os.IsNotExist(err) || !hasGlobMeta(arg) {
+				return nil, err
+			}
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s: tidak ada file yang cocok", arg)
+			}
+			expanded, err := Expand(matches)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, expanded...)
+			continue
+		}
+
+		if !info.IsDir() {
+			names = append(names, arg)
+			continue
+		}
+		var files []string
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != arg && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		names = append(names, files...)
+	}
+	return names, nil
+}
+
+// Read membaca satu input hasil Expand dan mendekompresinya jika perlu.
+func Read(ctx context.Context, client *http.Client, name string, stdin io.Reader) (Item, error) {
+	var data []byte
+	var err error
+	switch {
+	case name == Stdin:
+		data, err = io.ReadAll(stdin)
+	case isURL(name):
+		data, err = fetch(ctx, client, name)
+	default:
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return Item{Name: name}, err
+	}
+
+	data, err = decompress(ctx, data)
+	if err != nil {
+		return Item{Name: name}, fmt.Errorf("%s: %w", name, err)
+	}
+	return Item{Name: name, Data: data}, nil
+}
+
+func fetch(ctx context.Context, client *http.Client, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ErrNoZstd dikembalikan untuk input zstd jika program zstd tidak ada di PATH.
+var ErrNoZstd = errors.New("input terkompresi zstd memerlukan program zstd di PATH (misalnya paket zstd)")
+
+// zstdCommand adalah program yang dipakai untuk dekompresi zstd.
+var zstdCommand = "zstd"
+
+// decompress membuka gzip dengan pustaka standar dan zstd lewat perintah
+// zstd eksternal, karena pustaka standar Go belum menyediakan decoder zstd.
+func decompress(ctx context.Context, data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case bytes.HasPrefix(data, zstdMagic):
+		path, err := exec.LookPath(zstdCommand)
+		if err != nil {
+			return nil, ErrNoZstd
+		}
+		cmd := exec.CommandContext(ctx, path, "-d", "-c", "-q")
+		cmd.Stdin = bytes.NewReader(data)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("zstd: %v: %s", err, msg)
+			}
+			return nil, fmt.Errorf("zstd: %w", err)
+		}
+		return out, nil
+	}
+	return data, nil
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
@@ -0,0 +1,151 @@
+package input
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles membuat file kosong di dir, termasuk subdirektorinya.
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.txt", "b.txt", "c.csv", "sub/d.txt", "sub/.hidden", ".git/config")
+	p := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"file", []string{p("c.csv")}, []string{p("c.csv")}, false},
+		{"glob", []string{p("*.txt")}, []string{p("a.txt"), p("b.txt")}, false},
+		// File dan direktori tersembunyi dilewati, hasil terurut
+		{"direktori", []string{dir}, []string{p("a.txt"), p("b.txt"), p("c.csv"), p("sub/d.txt")}, false},
+		{"stdin dan URL", []string{Stdin, "https://x.example/list.txt"}, []string{Stdin, "https://x.example/list.txt"}, false},
+		{"glob tanpa hasil", []string{p("*.json")}, nil, true},
+		{"file tidak ada", []string{p("tidak-ada.txt")}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand error = %v, ingin error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expand = %v, ingin %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstded(t *testing.T, s string) []byte {
+	t.Helper()
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("program zstd tidak tersedia")
+	}
+	cmd := exec.Command("zstd", "-c", "-q")
+	cmd.Stdin = strings.NewReader(s)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRead(t *testing.T) {
+	const list = "1.2.3.4:80\n5.6.7.8:3128\n"
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "list.txt")
+	os.WriteFile(plain, []byte(list), 0o644)
+	gz := filepath.Join(dir, "list.txt.gz")
+	os.WriteFile(gz, gzipped(t, list), 0o644)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/list.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(gzipped(t, list))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name  string
+		input string
+		stdin []byte
+	}{
+		{"file", plain, nil},
+		// Kompresi dikenali dari magic byte, bukan ekstensi
+		{"gzip", gz, nil},
+		{"stdin gzip", Stdin, gzipped(t, list)},
+		{"URL gzip", srv.URL + "/list.gz", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := Read(context.Background(), srv.Client(), tt.input, bytes.NewReader(tt.stdin))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Name != tt.input || string(item.Data) != list {
+				t.Errorf("Read = %s %q", item.Name, item.Data)
+			}
+		})
+	}
+
+	if _, err := Read(context.Background(), srv.Client(), srv.URL+"/hilang", nil); err == nil {
+		t.Error("HTTP 404 diterima")
+	}
+}
+
+func TestReadZstd(t *testing.T) {
+	const list = "1.2.3.4:80\n"
+	data := zstded(t, list)
+	item, err := Read(context.Background(), http.DefaultClient, Stdin, bytes.NewReader(data))
+	if err != nil || string(item.Data) != list {
+		t.Fatalf("Read zstd = %q %v", item.Data, err)
+	}
+}
+
+func TestReadZstdMissing(t *testing.T) {
+	// Tanpa program zstd errornya menjelaskan apa yang kurang
+	defer func(cmd string) { zstdCommand = cmd }(zstdCommand)
+	zstdCommand = "zstd-tidak-ada"
+	data := append(slices.Clone(zstdMagic), 0)
+	if _, err := Read(context.Background(), http.DefaultClient, Stdin, bytes.NewReader(data)); !errors.Is(err, ErrNoZstd) {
+		t.Errorf("Read tanpa zstd = %v, ingin ErrNoZstd", err)
+	}
+}
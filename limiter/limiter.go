@@ -0,0 +1,323 @@
+// Package limiter menyediakan pembatas konkurensi adaptif untuk proses
+// pengecekan proxy. Limiter dimulai dari nilai konservatif lalu menaikkan
+// jumlah slot selama tingkat error dan pemakaian sumber daya lokal (file
+// descriptor, port ephemeral, goroutine) masih sehat, dan mundur ketika
+// muncul tanda-tanda mesin kita sendiri yang jenuh.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Outcome adalah hasil satu pengecekan dari sudut pandang limiter.
+type Outcome int
+
+const (
+	// Success berarti pengecekan selesai dan proxy merespons.
+	Success Outcome = iota
+	// Failure berarti proxy mati atau menolak; ini bukan tanda kejenuhan lokal.
+	Failure
+	// Timeout berarti koneksi atau pembacaan melewati batas waktu.
+	Timeout
+	// Saturated berarti error berasal dari sisi kita (EMFILE, EADDRNOTAVAIL, dll).
+	Saturated
+)
+
+// Config mengatur perilaku limiter adaptif.
+type Config struct {
+	Min     int // batas bawah slot konkurensi
+	Max     int // batas atas slot konkurensi
+	Initial int // nilai awal, default sama dengan Min
+
+	// Step adalah jumlah slot yang ditambahkan setiap kali kondisi sehat.
+	Step int
+	// Window adalah jumlah pengecekan yang dievaluasi sebelum limit diubah.
+	Window int
+	// Backoff adalah faktor pengali saat mundur (misalnya 0.5).
+	Backoff float64
+	// Cooldown adalah jeda minimum antar penyesuaian limit.
+	Cooldown time.Duration
+
+	// MaxFDRatio adalah batas pemakaian file descriptor terhadap RLIMIT_NOFILE.
+	MaxFDRatio float64
+	// MaxPortRatio adalah batas pemakaian port ephemeral terhadap rentang yang tersedia.
+	MaxPortRatio float64
+	// MaxGoroutines adalah batas jumlah goroutine proses.
+	MaxGoroutines int
+}
+
+// DefaultConfig mengembalikan konfigurasi yang aman untuk laptop dan tetap
+// bisa naik sampai max di server.
+func DefaultConfig(max int) Config {
+	return Config{
+		Min:           10,
+		Max:           max,
+		Initial:       25,
+		Step:          10,
+		Window:        50,
+		Backoff:       0.5,
+		Cooldown:      2 * time.Second,
+		MaxFDRatio:    0.7,
+		MaxPortRatio:  0.6,
+		MaxGoroutines: 20000,
+	}
+}
+
+// Stats adalah ringkasan perilaku limiter selama proses berjalan.
+type Stats struct {
+	Limit     int
+	InFlight  int
+	Peak      int
+	Increases int
+	Backoffs  int
+	Saturated int
+	Timeouts  int
+}
+
+// Limiter adalah semaphore dengan kapasitas yang berubah secara adaptif.
+type Limiter struct {
+	cfg Config
+
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	notify   chan struct{}
+
+	// statistik jendela berjalan
+	winTotal     int
+	winTimeouts  int
+	winSaturated int
+	lastAdjust   time.Time
+	// baselineTimeout adalah rata-rata bergerak rasio timeout pada kondisi
+	// stabil, dipakai untuk membedakan proxy mati dengan badai timeout.
+	baselineTimeout float64
+	haveBaseline    bool
+
+	stats Stats
+
+	// sampler dapat diganti untuk pengujian atau platform lain.
+	sampler func() Resources
+}
+
+// New membuat limiter adaptif baru. Nilai nol pada cfg diisi dari DefaultConfig.
+func New(cfg Config) *Limiter {
+	def := DefaultConfig(cfg.Max)
+	if cfg.Max <= 0 {
+		cfg.Max = 200
+		def = DefaultConfig(cfg.Max)
+	}
+	if cfg.Min <= 0 {
+		cfg.Min = min(def.Min, cfg.Max)
+	}
+	if cfg.Initial <= 0 {
+		cfg.Initial = max(cfg.Min, min(def.Initial, cfg.Max))
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = def.Step
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.Backoff <= 0 || cfg.Backoff >= 1 {
+		cfg.Backoff = def.Backoff
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = def.Cooldown
+	}
+	if cfg.MaxFDRatio <= 0 {
+		cfg.MaxFDRatio = def.MaxFDRatio
+	}
+	if cfg.MaxPortRatio <= 0 {
+		cfg.MaxPortRatio = def.MaxPortRatio
+	}
+	if cfg.MaxGoroutines <= 0 {
+		cfg.MaxGoroutines = def.MaxGoroutines
+	}
+	cfg.Initial = min(max(cfg.Initial, cfg.Min), cfg.Max)
+
+	l := &Limiter{
+		cfg:     cfg,
+		limit:   cfg.Initial,
+		notify:  make(chan struct{}),
+		sampler: SampleResources,
+	}
+	l.stats.Limit = l.limit
+	return l
+}
+
+// Acquire menunggu sampai ada slot kosong atau ctx dibatalkan.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.stats.Peak = max(l.stats.Peak, l.inFlight)
+			l.mu.Unlock()
+			return nil
+		}
+		wait := l.notify
+		l.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release mengembalikan slot dan mencatat hasil pengecekan.
+func (l *Limiter) Release(o Outcome) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.winTotal++
+	switch o {
+	case Timeout:
+		l.winTimeouts++
+		l.stats.Timeouts++
+	case Saturated:
+		l.winSaturated++
+		l.stats.Saturated++
+	}
+
+	// Error kejenuhan lokal langsung ditangani tanpa menunggu jendela penuh.
+	if l.winSaturated > 0 || l.winTotal >= l.cfg.Window {
+		l.adjustLocked()
+	}
+	l.wakeLocked()
+}
+
+// Limit mengembalikan jumlah slot yang diizinkan saat ini.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Stats mengembalikan salinan statistik limiter.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stats
+	s.Limit = l.limit
+	s.InFlight = l.inFlight
+	return s
+}
+
+func (l *Limiter) adjustLocked() {
+	now := time.Now()
+	if now.Sub(l.lastAdjust) < l.cfg.Cooldown {
+		// Jendela tetap berjalan, keputusan ditunda sampai cooldown lewat.
+		return
+	}
+
+	total := l.winTotal
+	timeoutRate := 0.0
+	if total > 0 {
+		timeoutRate = float64(l.winTimeouts) / float64(total)
+	}
+	saturated := l.winSaturated > 0
+	l.winTotal, l.winTimeouts, l.winSaturated = 0, 0, 0
+	l.lastAdjust = now
+
+	res := l.sampler()
+	overloaded := saturated || !l.healthy(res)
+
+	// Badai timeout: rasio timeout melonjak jauh di atas baseline. Ini lebih
+	// mungkin karena jaringan lokal penuh daripada proxy mendadak mati semua.
+	storm := l.haveBaseline && total >= l.cfg.Window/2 &&
+		timeoutRate > l.baselineTimeout+0.2 && timeoutRate > l.baselineTimeout*1.5
+
+	if overloaded || storm {
+		next := max(l.cfg.Min, int(float64(l.limit)*l.cfg.Backoff))
+		if next < l.limit {
+			l.limit = next
+			l.stats.Backoffs++
+		}
+		return
+	}
+
+	if total > 0 {
+		if l.haveBaseline {
+			l.baselineTimeout = 0.8*l.baselineTimeout + 0.2*timeoutRate
+		} else {
+			l.baselineTimeout = timeoutRate
+			l.haveBaseline = true
+		}
+	}
+
+	// Hanya naik jika slot yang ada memang terpakai; kalau tidak, menaikkan
+	// limit tidak memberi informasi apa pun tentang kapasitas.
+	if l.inFlight >= l.limit-l.cfg.Step && l.limit < l.cfg.Max {
+		l.limit = min(l.cfg.Max, l.limit+l.cfg.Step)
+		l.stats.Increases++
+	}
+}
+
+func (l *Limiter) healthy(r Resources) bool {
+	if r.FDLimit > 0 && float64(r.OpenFDs) > l.cfg.MaxFDRatio*float64(r.FDLimit) {
+		return false
+	}
+	if r.PortRange > 0 && float64(r.PortsInUse) > l.cfg.MaxPortRatio*float64(r.PortRange) {
+		return false
+	}
+	return r.Goroutines <= l.cfg.MaxGoroutines
+}
+
+func (l *Limiter) wakeLocked() {
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// Classify memetakan error pengecekan ke Outcome. Error yang menandakan
+// kehabisan sumber daya lokal dibedakan dari proxy yang sekadar mati.
+func Classify(err error) Outcome {
+	if err == nil {
+		return Success
+	}
+	if IsSaturation(err) {
+		return Saturated
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return Timeout
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return Timeout
+	}
+	return Failure
+}
+
+// IsSaturation melaporkan apakah err berasal dari habisnya sumber daya lokal.
+func IsSaturation(err error) bool {
+	return errors.Is(err, syscall.EMFILE) ||
+		errors.Is(err, syscall.ENFILE) ||
+		errors.Is(err, syscall.EADDRNOTAVAIL) ||
+		errors.Is(err, syscall.ENOBUFS)
+}
+
+// Resources adalah cuplikan pemakaian sumber daya lokal proses.
+type Resources struct {
+	OpenFDs    int
+	FDLimit    int
+	PortsInUse int
+	PortRange  int
+	Goroutines int
+}
+
+// SampleResources membaca pemakaian sumber daya saat ini. Nilai yang tidak
+// bisa dibaca di platform ini dibiarkan nol dan diabaikan oleh limiter.
+func SampleResources() Resources {
+	r := Resources{Goroutines: runtime.NumGoroutine()}
+	sampleSystem(&r)
+	return r
+}
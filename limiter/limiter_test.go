@@ -0,0 +1,119 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newTestLimiter membuat limiter tanpa cooldown dengan sampler tetap.
+func newTestLimiter(cfg Config, res Resources) *Limiter {
+	cfg.Cooldown = time.Nanosecond
+	l := New(cfg)
+	l.sampler = func() Resources { return res }
+	return l
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Outcome
+	}{
+		{"nil", nil, Success},
+		{"EMFILE", &net.OpError{Op: "dial", Err: os.NewSyscallError("socket", syscall.EMFILE)}, Saturated},
+		{"EADDRNOTAVAIL", fmt.Errorf("dial: %w", syscall.EADDRNOTAVAIL), Saturated},
+		{"ENOBUFS", syscall.ENOBUFS, Saturated},
+		{"deadline ctx", fmt.Errorf("Get x: %w", context.DeadlineExceeded), Timeout},
+		{"deadline socket", os.ErrDeadlineExceeded, Timeout},
+		{"refused", syscall.ECONNREFUSED, Failure},
+		{"lain", errors.New("EOF"), Failure},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("%s: Classify = %v, ingin %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAcquireBlocks(t *testing.T) {
+	l := newTestLimiter(Config{Min: 1, Max: 1}, Resources{})
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Slot penuh: Acquire berikutnya menunggu sampai ctx berakhir
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire saat penuh = %v, ingin DeadlineExceeded", err)
+	}
+
+	// Release membangunkan yang menunggu
+	done := make(chan error, 1)
+	go func() { done <- l.Acquire(context.Background()) }()
+	l.Release(Success)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Acquire tidak dibangunkan oleh Release")
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		name      string
+		res       Resources
+		outcome   Outcome
+		wantLimit int
+	}{
+		// Slot hampir semua terpakai dan sehat: naik satu Step
+		{"naik", Resources{}, Success, 6},
+		// Error jenuh lokal langsung memotong limit dengan Backoff
+		{"jenuh", Resources{}, Saturated, 2},
+		// File descriptor melewati MaxFDRatio
+		{"fd penuh", Resources{OpenFDs: 90, FDLimit: 100}, Success, 2},
+		// Port ephemeral melewati MaxPortRatio
+		{"port penuh", Resources{PortsInUse: 70, PortRange: 100}, Success, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLimiter(Config{Min: 1, Max: 10, Initial: 4, Step: 2, Window: 1, Backoff: 0.5}, tt.res)
+			for range 4 {
+				if err := l.Acquire(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			l.Release(tt.outcome)
+			if got := l.Limit(); got != tt.wantLimit {
+				t.Errorf("Limit = %d, ingin %d (%+v)", got, tt.wantLimit, l.Stats())
+			}
+		})
+	}
+}
+
+func TestAdjustBounds(t *testing.T) {
+	l := newTestLimiter(Config{Min: 3, Max: 5, Initial: 4, Step: 10, Window: 1, Backoff: 0.1}, Resources{})
+	l.Acquire(context.Background())
+	l.Release(Success)
+	if got := l.Limit(); got != 5 {
+		t.Errorf("Limit setelah naik = %d, ingin Max 5", got)
+	}
+	for range 3 {
+		l.Acquire(context.Background())
+		l.Release(Saturated)
+	}
+	if got := l.Limit(); got != 3 {
+		t.Errorf("Limit setelah mundur = %d, ingin Min 3", got)
+	}
+	if s := l.Stats(); s.Saturated != 3 || s.Backoffs != 1 {
+		t.Errorf("Stats = %+v, ingin 3 jenuh dan 1 backoff", s)
+	}
+}
@@ -0,0 +1,46 @@
+package limiter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func sampleSystem(r *Resources) {
+	if entries, err := os.ReadDir("/proc/self/fd"); err == nil {
+		r.OpenFDs = len(entries)
+	}
+
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err == nil {
+		r.FDLimit = int(rl.Cur)
+	}
+
+	if b, err := os.ReadFile("/proc/sys/net/ipv4/ip_local_port_range"); err == nil {
+		var lo, hi int
+		if _, err := fmt.Sscan(string(b), &lo, &hi); err == nil && hi > lo {
+			r.PortRange = hi - lo + 1
+		}
+	}
+
+	// Jumlah socket TCP di network namespace ini adalah perkiraan kasar
+	// pemakaian port ephemeral; cukup untuk mendeteksi mendekati habis.
+	r.PortsInUse = countLines("/proc/net/tcp") + countLines("/proc/net/tcp6")
+}
+
+func countLines(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		n++
+	}
+	// Baris pertama adalah header kolom.
+	return max(n-1, 0)
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package limiter
+
+// sampleSystem belum membaca FD dan port di luar Linux; limiter tetap bekerja
+// dengan sinyal error dan jumlah goroutine saja.
+func sampleSystem(r *Resources) {}
@@ -0,0 +1,667 @@
+package logging
+
+// Msg adalah ID pesan yang stabil; teksnya diambil dari katalog sesuai bahasa.
+type Msg string
+
+// Event run.
+const (
+	RunStart       Msg = "run.start"
+	RunConfig      Msg = "run.config"
+	RunDone        Msg = "run.done"
+	ConfigInvalid  Msg = "config.invalid"
+	HealthLoadFail Msg = "health.load_failed"
+	HealthSaveFail Msg = "health.save_failed"
+	CacheOpenFail  Msg = "cache.open_failed"
+	SaveFailed     Msg = "save.failed"
+	SaveEmpty      Msg = "save.empty"
+	Saved          Msg = "save.done"
+	MetricsServe   Msg = "metrics.listening"
+	MetricsFail    Msg = "metrics.export_failed"
+	LimiterStats   Msg = "limiter.stats"
+)
+
+// Event sumber dan scraping.
+const (
+	SourceSkipped    Msg = "source.skipped"
+	SourceDisabled   Msg = "source.disabled"
+	SourceEnabled    Msg = "source.enabled"
+	ScrapeStart      Msg = "scrape.start"
+	ScrapeAllStart   Msg = "scrape.all_start"
+	ScrapeDone       Msg = "scrape.done"
+	ScrapeAllDone    Msg = "scrape.all_done"
+	ScrapeFailed     Msg = "scrape.failed"
+	ScrapeStale      Msg = "scrape.stale"
+	ScrapeCached     Msg = "scrape.not_modified"
+	ScrapeStopped    Msg = "scrape.pagination_stopped"
+	ScrapeProxyFail  Msg = "scrape.proxy_failed"
+	ScrapeDirect     Msg = "scrape.direct_fallback"
+	ScrapePoolLoaded Msg = "scrape.pool_loaded"
+	ScrapeNone       Msg = "scrape.none"
+	Deduplicated     Msg = "scrape.deduplicated"
+	InputRead        Msg = "input.read"
+	InputFailed      Msg = "input.read_failed"
+	InputNone        Msg = "input.none"
+	NormalizeNone    Msg = "normalize.none"
+	Dropped          Msg = "normalize.dropped"
+	Resolved         Msg = "resolve.done"
+)
+
+// Event pengecekan.
+const (
+	CheckStart     Msg = "check.start"
+	CheckAllDone   Msg = "check.all_done"
+	CheckValid     Msg = "check.valid"
+	CheckInvalid   Msg = "check.invalid"
+	JudgeFailed    Msg = "check.judge_failed"
+	PrescreenStart Msg = "prescreen.start"
+	StageDone      Msg = "stage.done"
+	ReasonCount    Msg = "reason.count"
+	// ProgressChecked adalah label jumlah proxy dicek di baris progres.
+	ProgressChecked Msg = "progress.checked"
+)
+
+// Teks laporan yang dicetak apa adanya, bukan sebagai event log.
+const (
+	ReportSummary     Msg = "report.summary"
+	ReportValid       Msg = "report.valid"
+	ReportInvalid     Msg = "report.invalid"
+	ReportStageTCP    Msg = "report.stage_tcp"
+	ReportStageHTTP   Msg = "report.stage_http"
+	ReportReasons     Msg = "report.reasons"
+	ReportConcurrency Msg = "report.concurrency"
+	ReportSavedValid  Msg = "report.saved_valid"
+	ReportSavedBad    Msg = "report.saved_invalid"
+	ReportSavedDetail Msg = "report.saved_results"
+	ReportDropped     Msg = "report.dropped"
+	StageNormalize    Msg = "stage.normalize"
+	StageExportPolicy Msg = "stage.export_policy"
+)
+
+// Teks subcommand sources dan alasan sumber dinonaktifkan (package health).
+const (
+	SourcesEmpty         Msg = "sources.empty"
+	SourcesActive        Msg = "sources.active"
+	SourcesInactive      Msg = "sources.inactive"
+	DisabledFetchFailed  Msg = "source.disabled.fetch_failed"
+	DisabledNoValid      Msg = "source.disabled.no_valid"
+	DisabledLowValidRate Msg = "source.disabled.low_valid_rate"
+)
+
+// Teks laporan run (package report).
+const (
+	RptTitle         Msg = "report.run.title"
+	RptOverview      Msg = "report.run.overview"
+	RptFunnel        Msg = "report.run.funnel"
+	RptSources       Msg = "report.run.sources"
+	RptLatency       Msg = "report.run.latency"
+	RptCountries     Msg = "report.run.countries"
+	RptASNs          Msg = "report.run.asns"
+	RptAnonymity     Msg = "report.run.anonymity"
+	RptReasons       Msg = "report.run.reasons"
+	RptDiff          Msg = "report.run.diff"
+	RptDiffNote      Msg = "report.run.diff_note"
+	RptNewLive       Msg = "report.run.new_live"
+	RptNewDead       Msg = "report.run.new_dead"
+	RptNoPrevious    Msg = "report.run.no_previous"
+	RptMore          Msg = "report.run.more"
+	RptOther         Msg = "report.run.other"
+	RptPercentiles   Msg = "report.run.percentiles"
+	RptTime          Msg = "report.run.time"
+	RptValidCount    Msg = "report.run.valid_count"
+	RptSourceCount   Msg = "report.run.source_count"
+	RptMedian        Msg = "report.run.median"
+	RptPrevious      Msg = "report.run.previous"
+	RptNewCount      Msg = "report.run.new_count"
+	RptDeadCount     Msg = "report.run.dead_count"
+	RptChurn         Msg = "report.run.churn"
+	RptColMetric     Msg = "report.col.metric"
+	RptColValue      Msg = "report.col.value"
+	RptColCount      Msg = "report.col.count"
+	RptColShare      Msg = "report.col.share"
+	RptColStage      Msg = "report.col.stage"
+	RptColSource     Msg = "report.col.source"
+	RptColStatus     Msg = "report.col.status"
+	RptColRaw        Msg = "report.col.raw"
+	RptColNormalized Msg = "report.col.normalized"
+	RptColUnique     Msg = "report.col.unique"
+	RptColChecked    Msg = "report.col.checked"
+	RptColValid      Msg = "report.col.valid"
+	RptColRange      Msg = "report.col.range"
+	RptColReason     Msg = "report.col.reason"
+	RptColProxy      Msg = "report.col.proxy"
+	FunnelScraped    Msg = "report.funnel.scraped"
+	FunnelNormalized Msg = "report.funnel.normalized"
+	FunnelTCP        Msg = "report.funnel.tcp"
+	FunnelHTTP       Msg = "report.funnel.http"
+	FunnelExported   Msg = "report.funnel.exported"
+	ReportWritten    Msg = "report.written"
+	ReportFailed     Msg = "report.failed"
+)
+
+// Pesan notifikasi (package notify).
+const (
+	NotifyPoolLow       Msg = "notify.pool_low"
+	NotifySourceFailing Msg = "notify.source_failing"
+	NotifyRunCompleted  Msg = "notify.run_completed"
+	NotifyHighScore     Msg = "notify.high_score_proxy"
+	NotifySent          Msg = "notify.sent"
+	NotifyFailed        Msg = "notify.failed"
+)
+
+// Pesan penerbitan pool (package publish).
+const (
+	PublishDone   Msg = "publish.done"
+	PublishFailed Msg = "publish.failed"
+)
+
+// Pesan subcommand gateway.
+const (
+	GatewayListen       Msg = "gateway.listen"
+	GatewayUpstreamFail Msg = "gateway.upstream_failed"
+	GatewayFailed       Msg = "gateway.failed"
+	PoolAPIListen       Msg = "gateway.pool_api_listen"
+	GatewayReload       Msg = "gateway.reload"
+)
+
+var catalog = map[Msg]map[Lang]string{
+	RunStart: {
+		ID: "🚀 Memulai scraping dan validasi proxy",
+		EN: "🚀 Starting proxy scraping and validation",
+	},
+	RunConfig: {
+		ID: "🔩 Konfigurasi",
+		EN: "🔩 Configuration",
+	},
+	RunDone: {
+		ID: "🎉 Selesai",
+		EN: "🎉 Done",
+	},
+	ConfigInvalid: {
+		ID: "❌ Konfigurasi tidak valid",
+		EN: "❌ Invalid configuration",
+	},
+	HealthLoadFail: {
+		ID: "❌ Gagal membaca statistik sumber",
+		EN: "❌ Failed to read source statistics",
+	},
+	HealthSaveFail: {
+		ID: "❌ Gagal menyimpan statistik sumber",
+		EN: "❌ Failed to save source statistics",
+	},
+	CacheOpenFail: {
+		ID: "❌ Gagal membuka cache sumber",
+		EN: "❌ Failed to open source cache",
+	},
+	SaveFailed: {
+		ID: "❌ Gagal menyimpan file",
+		EN: "❌ Failed to save file",
+	},
+	SaveEmpty: {
+		ID: "Tidak ada proxy yang valid untuk disimpan",
+		EN: "No valid proxies to save",
+	},
+	Saved: {
+		ID: "💾 Proxy disimpan",
+		EN: "💾 Proxies saved",
+	},
+	MetricsServe: {
+		ID: "📈 Metrik tersedia",
+		EN: "📈 Metrics available",
+	},
+	MetricsFail: {
+		ID: "❌ Gagal mengekspor metrik",
+		EN: "❌ Failed to export metrics",
+	},
+	LimiterStats: {
+		ID: "⚙️ Statistik konkurensi",
+		EN: "⚙️ Concurrency statistics",
+	},
+
+	SourceSkipped: {
+		ID: "⏸️ Sumber dilewati",
+		EN: "⏸️ Source skipped",
+	},
+	SourceDisabled: {
+		ID: "⏸️ Sumber dinonaktifkan",
+		EN: "⏸️ Source disabled",
+	},
+	SourceEnabled: {
+		ID: "▶️ Sumber diaktifkan kembali",
+		EN: "▶️ Source re-enabled",
+	},
+	SourcesEmpty: {
+		ID: "Belum ada statistik sumber; jalankan scraper terlebih dahulu.",
+		EN: "No source statistics yet; run the scraper first.",
+	},
+	SourcesActive: {
+		ID: "aktif",
+		EN: "active",
+	},
+	SourcesInactive: {
+		ID: "nonaktif",
+		EN: "disabled",
+	},
+	DisabledFetchFailed: {
+		ID: "gagal fetch %d run berturut-turut",
+		EN: "fetch failed %d runs in a row",
+	},
+	DisabledNoValid: {
+		ID: "0 proxy valid dalam %d run terakhir",
+		EN: "0 valid proxies in the last %d runs",
+	},
+	DisabledLowValidRate: {
+		ID: "tingkat valid %.2f%% di bawah ambang %.2f%%",
+		EN: "valid rate %.2f%% below threshold %.2f%%",
+	},
+	ScrapeStart: {
+		ID: "🌐 Scraping sumber",
+		EN: "🌐 Scraping source",
+	},
+	ScrapeAllStart: {
+		ID: "🔍 Memulai scraping semua sumber",
+		EN: "🔍 Scraping all sources",
+	},
+	ScrapeDone: {
+		ID: "✅ Sumber selesai di-scrape",
+		EN: "✅ Source scraped",
+	},
+	ScrapeAllDone: {
+		ID: "✅ Semua sumber selesai di-scrape",
+		EN: "✅ All sources scraped",
+	},
+	ScrapeFailed: {
+		ID: "❌ Gagal scrape sumber",
+		EN: "❌ Failed to scrape source",
+	},
+	ScrapeStale: {
+		ID: "⚠️ Sumber gagal, memakai salinan cache",
+		EN: "⚠️ Source failed, using cached copy",
+	},
+	ScrapeCached: {
+		ID: "♻️ Sumber tidak berubah, memakai cache",
+		EN: "♻️ Source unchanged, using cache",
+	},
+	ScrapeStopped: {
+		ID: "⚠️ Paginasi berhenti karena error",
+		EN: "⚠️ Pagination stopped on error",
+	},
+	ScrapeProxyFail: {
+		ID: "Scrape lewat proxy gagal, mencoba proxy lain",
+		EN: "Scrape through proxy failed, trying another proxy",
+	},
+	ScrapeDirect: {
+		ID: "Fallback ke koneksi langsung",
+		EN: "Falling back to a direct connection",
+	},
+	ScrapePoolLoaded: {
+		ID: "Memakai proxy dari run sebelumnya untuk scraping",
+		EN: "Using proxies from the previous run for scraping",
+	},
+	ScrapeNone: {
+		ID: "❌ Tidak ada proxy yang berhasil di-scrape",
+		EN: "❌ No proxies were scraped",
+	},
+	Deduplicated: {
+		ID: "🧹 Duplikat dihapus",
+		EN: "🧹 Duplicates removed",
+	},
+	InputRead: {
+		ID: "📥 Input dibaca",
+		EN: "📥 Input read",
+	},
+	InputFailed: {
+		ID: "❌ Gagal membaca input",
+		EN: "❌ Failed to read input",
+	},
+	InputNone: {
+		ID: "❌ Tidak ada proxy untuk dicek",
+		EN: "❌ No proxies to check",
+	},
+	NormalizeNone: {
+		ID: "❌ Tidak ada proxy yang tersisa setelah normalisasi",
+		EN: "❌ No proxies left after normalization",
+	},
+	Dropped: {
+		ID: "🧽 Proxy dibuang",
+		EN: "🧽 Proxies dropped",
+	},
+	Resolved: {
+		ID: "🧭 Resolusi DNS selesai",
+		EN: "🧭 DNS resolution finished",
+	},
+
+	CheckStart: {
+		ID: "🔍 Memulai pengecekan proxy",
+		EN: "🔍 Starting proxy checks",
+	},
+	CheckAllDone: {
+		ID: "✅ Semua proxy telah selesai dicek",
+		EN: "✅ All proxies checked",
+	},
+	CheckValid: {
+		ID: "✅ Proxy valid",
+		EN: "✅ Proxy valid",
+	},
+	CheckInvalid: {
+		ID: "❌ Proxy invalid",
+		EN: "❌ Proxy invalid",
+	},
+	JudgeFailed: {
+		ID: "⚠️ Gagal mengambil IP publik dari judge, anonimitas tidak dideteksi",
+		EN: "⚠️ Failed to get public IP from judge, anonymity will not be detected",
+	},
+	PrescreenStart: {
+		ID: "🔌 Memulai pre-screen TCP",
+		EN: "🔌 Starting TCP pre-screen",
+	},
+	StageDone: {
+		ID: "Tahap selesai",
+		EN: "Stage finished",
+	},
+	ReasonCount: {
+		ID: "🔎 Alasan kegagalan",
+		EN: "🔎 Failure reason",
+	},
+
+	ProgressChecked: {
+		ID: "Dicek",
+		EN: "Checked",
+	},
+
+	ReportSummary: {
+		ID: "📊 RINGKASAN HASIL",
+		EN: "📊 RESULT SUMMARY",
+	},
+	ReportValid: {
+		ID: "✅ Proxy Valid: %d",
+		EN: "✅ Valid proxies: %d",
+	},
+	ReportInvalid: {
+		ID: "❌ Proxy Invalid: %d",
+		EN: "❌ Invalid proxies: %d",
+	},
+	ReportStageTCP: {
+		ID: "   🔌 Tahap 1 (TCP): %d dicek, %d tereliminasi",
+		EN: "   🔌 Stage 1 (TCP): %d checked, %d eliminated",
+	},
+	ReportStageHTTP: {
+		ID: "   🌐 Tahap 2 (HTTP): %d dicek, %d tereliminasi",
+		EN: "   🌐 Stage 2 (HTTP): %d checked, %d eliminated",
+	},
+	ReportReasons: {
+		ID: "🔎 Alasan kegagalan:",
+		EN: "🔎 Failure reasons:",
+	},
+	ReportConcurrency: {
+		ID: "⚙️  Konkurensi: akhir %d, puncak %d (naik %d kali, mundur %d kali)",
+		EN: "⚙️  Concurrency: final %d, peak %d (%d increases, %d backoffs)",
+	},
+	ReportSavedValid: {
+		ID: "📁 Proxy valid disimpan di: %s",
+		EN: "📁 Valid proxies saved to: %s",
+	},
+	ReportSavedBad: {
+		ID: "📁 Proxy invalid disimpan di: %s",
+		EN: "📁 Invalid proxies saved to: %s",
+	},
+	ReportSavedDetail: {
+		ID: "📁 Detail hasil dan alasan disimpan di: %s",
+		EN: "📁 Results and reasons saved to: %s",
+	},
+	ReportDropped: {
+		ID: "%s: %d proxy dibuang",
+		EN: "%s: %d proxies dropped",
+	},
+	StageNormalize: {
+		ID: "🧽 Normalisasi",
+		EN: "🧽 Normalization",
+	},
+	StageExportPolicy: {
+		ID: "🛡️  Policy ekspor",
+		EN: "🛡️  Export policy",
+	},
+
+	RptTitle: {
+		ID: "Laporan Run %s",
+		EN: "Run Report %s",
+	},
+	RptOverview: {
+		ID: "Ringkasan",
+		EN: "Overview",
+	},
+	RptFunnel: {
+		ID: "Funnel Validitas per Tahap",
+		EN: "Validity Funnel by Stage",
+	},
+	RptSources: {
+		ID: "Hasil per Sumber",
+		EN: "Yield per Source",
+	},
+	RptLatency: {
+		ID: "Distribusi Latency",
+		EN: "Latency Distribution",
+	},
+	RptCountries: {
+		ID: "Negara",
+		EN: "Countries",
+	},
+	RptASNs: {
+		ID: "ASN",
+		EN: "ASN",
+	},
+	RptAnonymity: {
+		ID: "Anonimitas",
+		EN: "Anonymity",
+	},
+	RptReasons: {
+		ID: "Alasan Kegagalan",
+		EN: "Failure Reasons",
+	},
+	RptDiff: {
+		ID: "Perbandingan dengan Run Sebelumnya",
+		EN: "Diff Against Previous Run",
+	},
+	RptDiffNote: {
+		ID: "Dibandingkan dengan run %s: %d proxy baru hidup, %d proxy baru mati, churn %s.",
+		EN: "Compared with the run at %s: %d newly live, %d newly dead, churn %s.",
+	},
+	RptNewLive: {
+		ID: "Proxy Baru Hidup",
+		EN: "Newly Live Proxies",
+	},
+	RptNewDead: {
+		ID: "Proxy Baru Mati",
+		EN: "Newly Dead Proxies",
+	},
+	RptNoPrevious: {
+		ID: "Belum ada run sebelumnya untuk dibandingkan.",
+		EN: "No previous run to compare against.",
+	},
+	RptMore: {
+		ID: "… dan %d lainnya.",
+		EN: "… and %d more.",
+	},
+	RptOther: {
+		ID: "lainnya",
+		EN: "other",
+	},
+	RptPercentiles: {
+		ID: "p50 %s · p90 %s · p99 %s",
+		EN: "p50 %s · p90 %s · p99 %s",
+	},
+	RptTime: {
+		ID: "Waktu",
+		EN: "Time",
+	},
+	RptValidCount: {
+		ID: "Proxy valid",
+		EN: "Valid proxies",
+	},
+	RptMedian: {
+		ID: "Median latency",
+		EN: "Median latency",
+	},
+	RptPrevious: {
+		ID: "Run sebelumnya",
+		EN: "Previous run",
+	},
+	RptNewCount: {
+		ID: "Baru hidup",
+		EN: "Newly live",
+	},
+	RptDeadCount: {
+		ID: "Baru mati",
+		EN: "Newly dead",
+	},
+	RptChurn: {
+		ID: "Churn",
+		EN: "Churn",
+	},
+	RptColMetric: {
+		ID: "Metrik",
+		EN: "Metric",
+	},
+	RptColValue: {
+		ID: "Nilai",
+		EN: "Value",
+	},
+	RptColCount: {
+		ID: "Jumlah",
+		EN: "Count",
+	},
+	RptColShare: {
+		ID: "Persen",
+		EN: "Share",
+	},
+	RptColStage: {
+		ID: "Tahap",
+		EN: "Stage",
+	},
+	RptColSource: {
+		ID: "Sumber",
+		EN: "Source",
+	},
+	RptSourceCount: {
+		ID: "Jumlah sumber",
+		EN: "Sources",
+	},
+	RptColStatus: {
+		ID: "Status",
+		EN: "Status",
+	},
+	RptColRaw: {
+		ID: "Mentah",
+		EN: "Raw",
+	},
+	RptColNormalized: {
+		ID: "Normal",
+		EN: "Normalized",
+	},
+	RptColUnique: {
+		ID: "Unik",
+		EN: "Unique",
+	},
+	RptColChecked: {
+		ID: "Dicek",
+		EN: "Checked",
+	},
+	RptColValid: {
+		ID: "Valid",
+		EN: "Valid",
+	},
+	RptColRange: {
+		ID: "Rentang",
+		EN: "Range",
+	},
+	RptColReason: {
+		ID: "Alasan",
+		EN: "Reason",
+	},
+	RptColProxy: {
+		ID: "Proxy",
+		EN: "Proxy",
+	},
+	FunnelScraped: {
+		ID: "Di-scrape",
+		EN: "Scraped",
+	},
+	FunnelNormalized: {
+		ID: "Setelah normalisasi",
+		EN: "After normalization",
+	},
+	FunnelTCP: {
+		ID: "Lolos pre-screen TCP",
+		EN: "Passed TCP pre-screen",
+	},
+	FunnelHTTP: {
+		ID: "Lolos validasi HTTP",
+		EN: "Passed HTTP validation",
+	},
+	FunnelExported: {
+		ID: "Diekspor",
+		EN: "Exported",
+	},
+	ReportWritten: {
+		ID: "📝 Laporan run ditulis",
+		EN: "📝 Run report written",
+	},
+	ReportFailed: {
+		ID: "❌ Gagal menulis laporan run",
+		EN: "❌ Failed to write run report",
+	},
+
+	NotifyPoolLow: {
+		ID: "⚠️ Pool proxy valid tinggal %d, di bawah batas %d",
+		EN: "⚠️ Valid proxy pool is down to %d, below the minimum of %d",
+	},
+	NotifySourceFailing: {
+		ID: "⚠️ Sumber %s tidak menghasilkan proxy valid %d run berturut-turut",
+		EN: "⚠️ Source %s produced no valid proxies for %d runs in a row",
+	},
+	NotifyRunCompleted: {
+		ID: "✅ Run selesai: %d proxy valid dari %d dicek dalam %s",
+		EN: "✅ Run completed: %d valid proxies out of %d checked in %s",
+	},
+	NotifyHighScore: {
+		ID: "⭐ Proxy baru dengan skor tinggi: %s (skor %.2f)",
+		EN: "⭐ New high-score proxy: %s (score %.2f)",
+	},
+	NotifySent: {
+		ID: "🔔 Notifikasi dikirim",
+		EN: "🔔 Notifications sent",
+	},
+	NotifyFailed: {
+		ID: "❌ Gagal mengirim notifikasi",
+		EN: "❌ Failed to send notifications",
+	},
+
+	PublishDone: {
+		ID: "📤 Pool proxy valid diterbitkan",
+		EN: "📤 Valid proxy pool published",
+	},
+	PublishFailed: {
+		ID: "❌ Gagal menerbitkan pool proxy valid",
+		EN: "❌ Failed to publish valid proxy pool",
+	},
+
+	GatewayListen: {
+		ID: "🚪 Gateway proxy mendengarkan",
+		EN: "🚪 Proxy gateway listening",
+	},
+	GatewayUpstreamFail: {
+		ID: "⚠️ Proxy upstream gagal",
+		EN: "⚠️ Upstream proxy failed",
+	},
+	GatewayFailed: {
+		ID: "❌ Gateway proxy berhenti",
+		EN: "❌ Proxy gateway stopped",
+	},
+	PoolAPIListen: {
+		ID: "📡 API pool mendengarkan",
+		EN: "📡 Pool API listening",
+	},
+	GatewayReload: {
+		ID: "🔄 Pool gateway dimuat ulang dari INPUT",
+		EN: "🔄 Gateway pool reloaded from INPUT",
+	},
+}
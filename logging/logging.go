@@ -0,0 +1,153 @@
+// Package logging menyatukan log ketiga tool di atas log/slog. Setiap
+// event punya ID pesan yang tetap (field "event") sehingga pipeline log
+// bisa mem-parse run apa pun bahasanya, sedangkan teks pesan diambil dari
+// katalog dalam bahasa Indonesia atau Inggris.
+package logging
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Lang adalah bahasa pesan.
+type Lang string
+
+const (
+	ID Lang = "id"
+	EN Lang = "en"
+)
+
+// Nama field yang konsisten di semua event.
+const (
+	KeyEvent   = "event"
+	KeyProxy   = "proxy"
+	KeySource  = "source"
+	KeyStage   = "stage"
+	KeyReason  = "reason"
+	KeyLatency = "latency_ms"
+	KeyError   = "error"
+	KeyCount   = "count"
+	KeyFile    = "file"
+)
+
+var lang atomic.Value
+
+func init() {
+	lang.Store(ID)
+}
+
+// SetLang mengganti bahasa pesan; bahasa yang tidak dikenal menjadi Indonesia.
+func SetLang(l Lang) {
+	if l != EN {
+		l = ID
+	}
+	lang.Store(l)
+}
+
+// T mengembalikan teks pesan dalam bahasa aktif. Jika args diberikan,
+// teks dipakai sebagai format fmt. Pesan tanpa terjemahan jatuh ke bahasa
+// Indonesia, lalu ke ID pesannya sendiri.
+func T(id Msg, args ...any) string {
+	texts := catalog[id]
+	text := texts[lang.Load().(Lang)]
+	if text == "" {
+		text = texts[ID]
+	}
+	if text == "" {
+		text = string(id)
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Flags adalah flag log yang sama untuk ketiga tool.
+type Flags struct {
+	Format *string
+	Level  *string
+	Lang   *string
+}
+
+// AddFlags mendaftarkan -log-format, -log-level dan -lang.
+func AddFlags(fs *flag.FlagSet) *Flags {
+	defaultLang := string(ID)
+	if strings.HasPrefix(os.Getenv("PROXYSCRAPER_LANG"), "en") {
+		defaultLang = string(EN)
+	}
+	return &Flags{
+		Format: fs.String("log-format", "text", "format log: text atau json"),
+		Level:  fs.String("log-level", "", "level log: debug, info, warn atau error (default info, atau dari -quiet/-verbose)"),
+		Lang:   fs.String("lang", defaultLang, "bahasa pesan: id atau en (default dari PROXYSCRAPER_LANG)"),
+	}
+}
+
+// Setup memasang logger default yang menulis ke out. Tanpa -log-level,
+// quiet menurunkan log ke warn dan verbose menaikkannya ke debug.
+func (f *Flags) Setup(out io.Writer, quiet, verbose bool) error {
+	level := slog.LevelInfo
+	switch {
+	case *f.Level != "":
+		if err := level.UnmarshalText([]byte(*f.Level)); err != nil {
+			return fmt.Errorf("level log tidak dikenal: %q", *f.Level)
+		}
+	case quiet:
+		level = slog.LevelWarn
+	case verbose:
+		level = slog.LevelDebug
+	}
+
+	switch Lang(*f.Lang) {
+	case ID, EN:
+		SetLang(Lang(*f.Lang))
+	default:
+		return fmt.Errorf("bahasa tidak dikenal: %q", *f.Lang)
+	}
+
+	var handler slog.Handler
+	switch *f.Format {
+	case "json":
+		handler = slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level})
+	case "text":
+		handler = slog.NewTextHandler(out, &slog.HandlerOptions{
+			Level: level,
+			// Waktu dibuang di format text agar tetap enak dibaca di terminal;
+			// pipeline log memakai json yang menyertakan waktu.
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})
+	default:
+		return fmt.Errorf("format log tidak dikenal: %q", *f.Format)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+func emit(level slog.Level, id Msg, args []any) {
+	slog.Default().Log(context.Background(), level, T(id), append([]any{KeyEvent, string(id)}, args...)...)
+}
+
+// Debug, Info, Warn dan Error mencatat event id dengan pasangan key/value args.
+func Debug(id Msg, args ...any) { emit(slog.LevelDebug, id, args) }
+
+func Info(id Msg, args ...any) { emit(slog.LevelInfo, id, args) }
+
+func Warn(id Msg, args ...any) { emit(slog.LevelWarn, id, args) }
+
+func Error(id Msg, args ...any) { emit(slog.LevelError, id, args) }
+
+// Fatal mencatat event sebagai error lalu keluar dengan status 1.
+func Fatal(id Msg, args ...any) {
+	emit(slog.LevelError, id, args)
+	os.Exit(1)
+}
@@ -0,0 +1,398 @@
+// Package metrics menyediakan counter, gauge dan histogram berlabel yang
+// ditulis dalam format teks Prometheus. Formatnya ditulis sendiri agar
+// tidak menambah dependensi: cukup untuk endpoint /metrics, file textfile
+// collector node_exporter, dan push ke Pushgateway.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets cocok untuk latency pengecekan proxy dalam detik.
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 16}
+
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry menyimpan semua metrik yang akan ditulis.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+	names      map[string]int
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]int)}
+}
+
+// register menambahkan c dengan nama name. Nama yang sudah terdaftar
+// diganti di posisi yang sama, misalnya GaugeFunc yang dipasang ulang untuk
+// limiter baru, sehingga pemanggil tidak perlu menjaga agar registrasi
+// hanya terjadi sekali.
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i, ok := r.names[name]; ok {
+		r.collectors[i] = c
+		return
+	}
+	r.names[name] = len(r.collectors)
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText menulis semua metrik dalam format eksposisi teks Prometheus.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, c := range collectors {
+		c.write(&buf)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Handler melayani metrik untuk endpoint /metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// WriteFile menulis metrik ke file secara atomik, untuk textfile collector
+// yang bisa membaca file kapan saja.
+func (r *Registry) WriteFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metrics-*.prom")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := r.WriteText(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Push mengirim metrik ke Pushgateway dengan PUT ke /metrics/job/<job>,
+// menggantikan grup metrik sebelumnya untuk job tersebut.
+func (r *Registry) Push(ctx context.Context, client *http.Client, gateway, job string) error {
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		return err
+	}
+	url := strings.TrimRight(gateway, "/") + "/metrics/job/" + job
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pushgateway membalas HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// --- vektor berlabel ---
+
+type vec[T any] struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+	newT   func() *T
+}
+
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s butuh %d label, diberi %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = v.newT()
+		v.series[key] = s
+		v.values[key] = append([]string(nil), values...)
+	}
+	return s
+}
+
+// each memanggil fn untuk setiap seri, terurut agar keluaran stabil.
+func (v *vec[T]) each(fn func(labels string, s *T)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	series := make([]*T, len(keys))
+	values := make([][]string, len(keys))
+	for i, k := range keys {
+		series[i] = v.series[k]
+		values[i] = v.values[k]
+	}
+	v.mu.Unlock()
+
+	for i := range keys {
+		fn(formatLabels(v.labels, values[i], ""), series[i])
+	}
+}
+
+func (v *vec[T]) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.kind)
+}
+
+func newVec[T any](name, help, kind string, labels []string, newT func() *T) *vec[T] {
+	return &vec[T]{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: make(map[string]*T),
+		values: make(map[string][]string),
+		newT:   newT,
+	}
+}
+
+// --- counter & gauge ---
+
+// Value adalah satu seri counter atau gauge.
+type Value struct {
+	mu sync.Mutex
+	v  float64
+}
+
+func (c *Value) Add(delta float64) {
+	c.mu.Lock()
+	c.v += delta
+	c.mu.Unlock()
+}
+
+func (c *Value) Inc() { c.Add(1) }
+
+func (c *Value) Set(v float64) {
+	c.mu.Lock()
+	c.v = v
+	c.mu.Unlock()
+}
+
+func (c *Value) get() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.v
+}
+
+// CounterVec adalah counter dengan label. Counter hanya boleh naik.
+type CounterVec struct{ v *vec[Value] }
+
+// GaugeVec adalah gauge dengan label.
+type GaugeVec struct{ v *vec[Value] }
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, "counter", labels, func() *Value { return &Value{} })}
+	r.register(name, c)
+	return c
+}
+
+func (r *Registry) NewGauge(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(name, help, "gauge", labels, func() *Value { return &Value{} })}
+	r.register(name, g)
+	return g
+}
+
+// With mengembalikan seri untuk nilai label yang diberikan, urut sesuai label.
+func (c *CounterVec) With(values ...string) *Value { return c.v.with(values) }
+
+func (g *GaugeVec) With(values ...string) *Value { return g.v.with(values) }
+
+// Reset menghapus semua seri, misalnya sebelum gauge diisi ulang.
+func (g *GaugeVec) Reset() {
+	g.v.mu.Lock()
+	g.v.series = make(map[string]*Value)
+	g.v.values = make(map[string][]string)
+	g.v.mu.Unlock()
+}
+
+func (c *CounterVec) write(w io.Writer) { writeValues(w, c.v) }
+
+func (g *GaugeVec) write(w io.Writer) { writeValues(w, g.v) }
+
+func writeValues(w io.Writer, v *vec[Value]) {
+	v.header(w)
+	v.each(func(labels string, s *Value) {
+		fmt.Fprintf(w, "%s%s %s\n", v.name, labels, formatFloat(s.get()))
+	})
+}
+
+// Func adalah metrik tanpa label yang nilainya dibaca saat metrik ditulis,
+// untuk nilai yang sudah dihitung di tempat lain seperti statistik limiter.
+type Func struct {
+	name, help, kind string
+	fn               func() float64
+}
+
+// NewGaugeFunc mendaftarkan gauge yang dibaca dari fn.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) *Func {
+	f := &Func{name: name, help: help, kind: "gauge", fn: fn}
+	r.register(name, f)
+	return f
+}
+
+// NewCounterFunc mendaftarkan counter yang dibaca dari fn; fn harus monoton naik.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) *Func {
+	f := &Func{name: name, help: help, kind: "counter", fn: fn}
+	r.register(name, f)
+	return f
+}
+
+func (f *Func) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", f.name, escapeHelp(f.help), f.name, f.kind, f.name, formatFloat(f.fn()))
+}
+
+// --- histogram ---
+
+// Histogram adalah satu seri histogram.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// HistogramVec adalah histogram dengan label.
+type HistogramVec struct {
+	v       *vec[Histogram]
+	buckets []float64
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{buckets: buckets}
+	h.v = newVec(name, help, "histogram", labels, func() *Histogram {
+		return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+	})
+	r.register(name, h)
+	return h
+}
+
+func (h *HistogramVec) With(values ...string) *Histogram { return h.v.with(values) }
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.v.header(w)
+	h.v.mu.Lock()
+	keys := make([]string, 0, len(h.v.series))
+	for k := range h.v.series {
+		keys = append(keys, k)
+	}
+	h.v.mu.Unlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		h.v.mu.Lock()
+		s, values := h.v.series[key], h.v.values[key]
+		h.v.mu.Unlock()
+
+		s.mu.Lock()
+		counts := append([]uint64(nil), s.counts...)
+		sum, count := s.sum, s.count
+		s.mu.Unlock()
+
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.v.name, formatLabels(h.v.labels, values, formatFloat(upper)), counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.v.name, formatLabels(h.v.labels, values, "+Inf"), count)
+		labels := formatLabels(h.v.labels, values, "")
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.v.name, labels, formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.v.name, labels, count)
+	}
+}
+
+// --- format ---
+
+// formatLabels menulis {a="x",b="y"}; le ditambahkan untuk bucket histogram.
+func formatLabels(names, values []string, le string) string {
+	if len(names) == 0 && le == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, escapeLabel(values[i]))
+	}
+	if le != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "le=\"%s\"", le)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
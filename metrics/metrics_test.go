@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterReplaces(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("a_total", "A.").With().Inc()
+	r.NewGaugeFunc("limit", "Batas.", func() float64 { return 1 })
+	// Registrasi ulang, misalnya untuk limiter baru, tidak panik dan
+	// menggantikan metrik lama tanpa mengubah urutan
+	r.NewGaugeFunc("limit", "Batas.", func() float64 { return 2 })
+
+	var out strings.Builder
+	if err := r.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	if strings.Count(text, "# TYPE limit") != 1 || !strings.Contains(text, "limit 2\n") {
+		t.Errorf("metrik limit tidak diganti:\n%s", text)
+	}
+	if strings.Index(text, "a_total") > strings.Index(text, "limit") {
+		t.Errorf("urutan metrik berubah:\n%s", text)
+	}
+}
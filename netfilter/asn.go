@@ -0,0 +1,122 @@
+package netfilter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ASNInfo adalah informasi jaringan untuk satu alamat IP.
+type ASNInfo struct {
+	ASN     uint32
+	Country string
+	Name    string
+}
+
+type asnRange struct {
+	start, end netip.Addr
+	info       ASNInfo
+}
+
+// ASNDB memetakan alamat IP ke ASN dan negara secara offline.
+type ASNDB struct {
+	ranges []asnRange
+}
+
+// LoadASNDB membaca database format ip2asn (iptoasn.com) berupa TSV dengan
+// kolom: range_start, range_end, AS_number, country_code, AS_description.
+// File berakhiran .gz dibaca langsung tanpa perlu diekstrak.
+func LoadASNDB(path string) (*ASNDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	db, err := ParseASNDB(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// ParseASNDB membaca database ip2asn dari r; lihat LoadASNDB.
+func ParseASNDB(r io.Reader) (*ASNDB, error) {
+	db := &ASNDB{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 4 {
+			continue
+		}
+		start, err1 := netip.ParseAddr(fields[0])
+		end, err2 := netip.ParseAddr(fields[1])
+		asn, err3 := strconv.ParseUint(fields[2], 10, 32)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("baris %d: format ip2asn tidak dikenali", lineNo)
+		}
+		// ASN 0 berarti rentang tidak di-route; tidak ada informasi berguna
+		if asn == 0 {
+			continue
+		}
+		info := ASNInfo{ASN: uint32(asn), Country: strings.ToUpper(fields[3])}
+		if len(fields) > 4 {
+			info.Name = fields[4]
+		}
+		db.ranges = append(db.ranges, asnRange{start.Unmap(), end.Unmap(), info})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return db, nil
+}
+
+// Lookup mengembalikan informasi ASN untuk ip.
+func (db *ASNDB) Lookup(ip netip.Addr) (ASNInfo, bool) {
+	if db == nil {
+		return ASNInfo{}, false
+	}
+	ip = ip.Unmap()
+	// Cari rentang terakhir yang dimulai sebelum atau tepat di ip
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return ip.Less(db.ranges[i].start)
+	}) - 1
+	if i < 0 {
+		return ASNInfo{}, false
+	}
+	r := db.ranges[i]
+	if ip.Compare(r.end) > 0 {
+		return ASNInfo{}, false
+	}
+	return r.info, true
+}
+
+// Len mengembalikan jumlah rentang di database.
+func (db *ASNDB) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.ranges)
+}
@@ -0,0 +1,325 @@
+// Package netfilter menormalkan alamat proxy ke bentuk kanonik dan
+// menyaring alamat berdasarkan daftar CIDR, misalnya rentang bogon yang
+// tidak mungkin menjadi proxy publik (private, loopback, multicast, CGNAT).
+package netfilter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseIP mengurai alamat IPv4 atau IPv6 dan mengembalikannya dalam bentuk
+// kanonik. IPv4 dengan nol di depan oktet (010.001.002.003) dibaca sebagai
+// desimal, karena begitulah daftar proxy menuliskannya sebagai padding.
+// Alamat IPv4-mapped IPv6 dikembalikan sebagai IPv4.
+func ParseIP(s string) (netip.Addr, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		if addr.Zone() != "" {
+			return netip.Addr{}, fmt.Errorf("IP dengan zone tidak didukung: %q", s)
+		}
+		return addr.Unmap(), nil
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return netip.Addr{}, fmt.Errorf("IP tidak valid: %q", s)
+	}
+	var octets [4]byte
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 3 || !isDigits(part) {
+			return netip.Addr{}, fmt.Errorf("IP tidak valid: %q", s)
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n > 255 {
+			return netip.Addr{}, fmt.Errorf("IP tidak valid: %q", s)
+		}
+		octets[i] = byte(n)
+	}
+	return netip.AddrFrom4(octets), nil
+}
+
+// ParsePort memastikan port berupa angka 1-65535 dan mengembalikannya
+// tanpa nol di depan.
+func ParsePort(s string) (string, error) {
+	if len(s) == 0 || len(s) > 5 || !isDigits(s) {
+		return "", fmt.Errorf("port tidak valid: %q", s)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("port di luar rentang 1-65535: %q", s)
+	}
+	return strconv.Itoa(n), nil
+}
+
+// isDigits melaporkan apakah s hanya berisi digit ASCII. strconv.Atoi
+// sendiri menerima tanda '+' dan '-' sehingga "+80" lolos sebagai port.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Entry adalah satu rentang CIDR beserta label alasannya.
+type Entry struct {
+	Prefix netip.Prefix
+	Label  string
+}
+
+// List adalah daftar rentang CIDR berlabel. Rentang dikelompokkan per
+// panjang prefix sehingga pencarian tetap cepat untuk daftar reputasi besar
+// seperti FireHOL yang berisi puluhan ribu entri.
+type List struct {
+	byBits map[int]map[netip.Prefix]string
+	bits   []int // panjang prefix yang ada, dari yang paling spesifik
+	count  int
+}
+
+// Add menambahkan rentang ke daftar. Jika rentang yang sama sudah ada,
+// label pertama yang dipertahankan.
+func (l *List) Add(prefix netip.Prefix, label string) {
+	prefix = prefix.Masked()
+	if l.byBits == nil {
+		l.byBits = make(map[int]map[netip.Prefix]string)
+	}
+	group, ok := l.byBits[prefix.Bits()]
+	if !ok {
+		group = make(map[netip.Prefix]string)
+		l.byBits[prefix.Bits()] = group
+		l.bits = append(l.bits, prefix.Bits())
+		sort.Sort(sort.Reverse(sort.IntSlice(l.bits)))
+	}
+	if _, exists := group[prefix]; !exists {
+		group[prefix] = label
+		l.count++
+	}
+}
+
+// Merge menambahkan semua rentang dari other.
+func (l *List) Merge(other *List) {
+	for _, e := range other.Entries() {
+		l.Add(e.Prefix, e.Label)
+	}
+}
+
+// Entries mengembalikan semua rentang di daftar.
+func (l *List) Entries() []Entry {
+	if l == nil {
+		return nil
+	}
+	entries := make([]Entry, 0, l.count)
+	for _, group := range l.byBits {
+		for prefix, label := range group {
+			entries = append(entries, Entry{Prefix: prefix, Label: label})
+		}
+	}
+	return entries
+}
+
+// Len mengembalikan jumlah rentang di daftar.
+func (l *List) Len() int {
+	if l == nil {
+		return 0
+	}
+	return l.count
+}
+
+// Match mengembalikan label rentang paling spesifik yang memuat ip.
+func (l *List) Match(ip netip.Addr) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	ip = ip.Unmap()
+	for _, bits := range l.bits {
+		if bits > ip.BitLen() {
+			continue
+		}
+		prefix, err := ip.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if label, ok := l.byBits[bits][prefix]; ok {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// ParseList membaca daftar CIDR dari r. Setiap baris berisi satu CIDR atau
+// alamat IP tunggal, opsional diikuti label. Komentar diawali '#' atau ';'
+// sehingga format seperti Spamhaus DROP ("1.2.3.0/24 ; SBL123") dan FireHOL
+// bisa dibaca langsung. Baris JSON dengan field "cidr" (drop_v4.json) juga
+// diterima. Baris tanpa label memakai defaultLabel.
+func ParseList(r io.Reader, defaultLabel string) (*List, error) {
+	list := &List{}
+	err := scanRules(r, defaultLabel, func(value, label string) error {
+		prefix, err := parsePrefix(value)
+		if err != nil {
+			return err
+		}
+		list.Add(prefix, label)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// scanRules membaca r baris per baris dan memanggil fn untuk setiap aturan
+// beserta labelnya; baris kosong dan komentar dilewati. Baris JSON Spamhaus
+// diubah menjadi aturan teks yang setara: field "cidr" dipakai apa adanya
+// dengan label "sblid", field "asn" menjadi "AS<nomor>" dengan label
+// "asname". Error dari fn diberi nomor baris.
+func scanRules(r io.Reader, defaultLabel string, fn func(value, label string) error) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		var value, label string
+		var ok bool
+		if strings.HasPrefix(line, "{") {
+			var err error
+			if value, label, ok, err = splitJSONLine(line, defaultLabel); err != nil {
+				return fmt.Errorf("baris %d: %w", lineNo, err)
+			}
+		} else {
+			value, label, ok = splitRuleLine(line, defaultLabel)
+		}
+		if !ok {
+			continue
+		}
+		if err := fn(value, label); err != nil {
+			return fmt.Errorf("baris %d: %w", lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadFile membaca daftar CIDR dari file; lihat ParseList untuk formatnya.
+func LoadFile(path, defaultLabel string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	list, err := ParseList(f, defaultLabel)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
+
+// splitRuleLine memisahkan nilai aturan dan labelnya dari satu baris.
+// Label diambil dari kolom kedua, atau dari kata pertama komentar ';' seperti
+// ID SBL di Spamhaus DROP, atau defaultLabel jika keduanya tidak ada.
+func splitRuleLine(line, defaultLabel string) (value, label string, ok bool) {
+	comment := ""
+	if i := strings.IndexAny(line, "#;"); i >= 0 {
+		if line[i] == ';' {
+			comment = line[i+1:]
+		}
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", "", false
+	}
+
+	label = defaultLabel
+	if len(fields) > 1 {
+		label = fields[1]
+	} else if words := strings.Fields(comment); len(words) > 0 {
+		label = words[0]
+	}
+	return fields[0], label, true
+}
+
+// splitJSONLine membaca satu baris JSON Spamhaus (drop_v4.json,
+// asndrop.json). Baris metadata tanpa cidr atau asn dilewati.
+func splitJSONLine(line, defaultLabel string) (value, label string, ok bool, err error) {
+	var entry struct {
+		CIDR   string `json:"cidr"`
+		ASN    uint32 `json:"asn"`
+		SBLID  string `json:"sblid"`
+		ASName string `json:"asname"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return "", "", false, err
+	}
+	label = defaultLabel
+	switch {
+	case entry.CIDR != "":
+		if entry.SBLID != "" {
+			label = entry.SBLID
+		}
+		return entry.CIDR, label, true, nil
+	case entry.ASN != 0:
+		if entry.ASName != "" {
+			label = entry.ASName
+		}
+		return fmt.Sprintf("AS%d", entry.ASN), label, true, nil
+	}
+	return "", "", false, nil
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, errors.New("bukan CIDR atau IP: " + s)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// bogons adalah rentang yang tidak boleh muncul sebagai proxy publik.
+var bogons = []struct {
+	cidr  string
+	label string
+}{
+	{"0.0.0.0/8", "this-network"},
+	{"10.0.0.0/8", "private"},
+	{"100.64.0.0/10", "cgnat"},
+	{"127.0.0.0/8", "loopback"},
+	{"169.254.0.0/16", "link-local"},
+	{"172.16.0.0/12", "private"},
+	{"192.0.0.0/24", "reserved"},
+	{"192.0.2.0/24", "documentation"},
+	{"192.168.0.0/16", "private"},
+	{"198.18.0.0/15", "benchmark"},
+	{"198.51.100.0/24", "documentation"},
+	{"203.0.113.0/24", "documentation"},
+	{"224.0.0.0/4", "multicast"},
+	{"240.0.0.0/4", "reserved"},
+	{"::/128", "unspecified"},
+	{"::1/128", "loopback"},
+	{"100::/64", "reserved"},
+	{"2001:db8::/32", "documentation"},
+	{"fc00::/7", "private"},
+	{"fe80::/10", "link-local"},
+	{"ff00::/8", "multicast"},
+}
+
+// Bogons mengembalikan daftar bawaan rentang bogon IPv4 dan IPv6.
+func Bogons() *List {
+	list := &List{}
+	for _, b := range bogons {
+		list.Add(netip.MustParsePrefix(b.cidr), b.label)
+	}
+	return list
+}
@@ -0,0 +1,188 @@
+package netfilter
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestParseIP(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // kosong berarti error
+	}{
+		{"1.2.3.4", "1.2.3.4"},
+		{"010.001.002.003", "10.1.2.3"},
+		{"::ffff:1.2.3.4", "1.2.3.4"},
+		{"2001:DB8::1", "2001:db8::1"},
+		{"fe80::1%eth0", ""},
+		{"1.2.3", ""},
+		{"1.2.3.256", ""},
+		{"1.2.3.0256", ""},
+		{"1.2.+3.4", ""},
+		{"1.2.-3.4", ""},
+		{"1.2..4", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseIP(tt.in)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("ParseIP(%q) = %v, ingin error", tt.in, got)
+		case tt.want != "" && (err != nil || got.String() != tt.want):
+			t.Errorf("ParseIP(%q) = %v, %v, ingin %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // kosong berarti error
+	}{
+		{"8080", "8080"},
+		{"0080", "80"},
+		{"65535", "65535"},
+		{"0", ""},
+		{"65536", ""},
+		{"+80", ""},
+		{"-1", ""},
+		{"80a", ""},
+		{"123456", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParsePort(tt.in)
+		if (tt.want == "") != (err != nil) || got != tt.want {
+			t.Errorf("ParsePort(%q) = %q, %v, ingin %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	input := strings.Join([]string{
+		"# komentar FireHOL",
+		"10.0.0.0/8",
+		"10.1.0.0/16 spesifik",
+		"1.2.3.0/24 ; SBL123",
+		"5.6.7.8",
+		"2001:db8::/32 ipv6",
+		`{"cidr": "9.9.9.0/24", "sblid": "SBL9"}`,
+		`{"type": "metadata", "timestamp": 1700000000}`,
+		"",
+	}, "\n")
+	list, err := ParseList(strings.NewReader(input), "bawaan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Len() != 6 {
+		t.Errorf("Len = %d, ingin 6", list.Len())
+	}
+
+	tests := []struct {
+		ip        string
+		wantLabel string
+		wantOK    bool
+	}{
+		{"10.2.3.4", "bawaan", true},
+		{"10.1.2.3", "spesifik", true}, // rentang paling spesifik menang
+		{"1.2.3.99", "SBL123", true},
+		{"5.6.7.8", "bawaan", true},
+		{"5.6.7.9", "", false},
+		{"::ffff:9.9.9.9", "SBL9", true},
+		{"2001:db8::1", "ipv6", true},
+		{"2001:db9::1", "", false},
+	}
+	for _, tt := range tests {
+		label, ok := list.Match(netip.MustParseAddr(tt.ip))
+		if label != tt.wantLabel || ok != tt.wantOK {
+			t.Errorf("Match(%s) = %q %v, ingin %q %v", tt.ip, label, ok, tt.wantLabel, tt.wantOK)
+		}
+	}
+
+	for _, bad := range []string{"10.0.0.0/33", "bukan-ip", `{"cidr": 1}`} {
+		if _, err := ParseList(strings.NewReader(bad), "x"); err == nil {
+			t.Errorf("ParseList(%q) tidak error", bad)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	input := strings.Join([]string{
+		"; Spamhaus ASN-DROP",
+		"AS13335 ; cloudflare",
+		"country:cn",
+		"1.2.3.0/24 # komentar",
+		`{"asn": 4134, "asname": "CHINANET", "domain": "chinatelecom.cn"}`,
+		`{"cidr": "9.9.9.0/24", "sblid": "SBL9"}`,
+		`{"type": "metadata", "records": 2}`,
+	}, "\n")
+	r, err := ParseRules(strings.NewReader(input), "bawaan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 5 {
+		t.Errorf("Len = %d, ingin 5", r.Len())
+	}
+	if r.ASNs[13335] != "cloudflare" || r.ASNs[4134] != "CHINANET" || !r.Countries["CN"] {
+		t.Errorf("ASNs %v Countries %v", r.ASNs, r.Countries)
+	}
+	if label, ok := r.CIDRs.Match(netip.MustParseAddr("9.9.9.9")); !ok || label != "SBL9" {
+		t.Errorf("Match 9.9.9.9 = %q %v", label, ok)
+	}
+
+	for _, bad := range []string{"ASxyz", "country:CHN", "{bukan json", "bukan-cidr"} {
+		if _, err := ParseRules(strings.NewReader(bad), "x"); err == nil {
+			t.Errorf("ParseRules(%q) tidak error", bad)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	db, err := ParseASNDB(strings.NewReader(strings.Join([]string{
+		"1.0.0.0\t1.0.0.255\t13335\tUS\tCLOUDFLARENET",
+		"2.0.0.0\t2.0.0.255\t4134\tCN\tCHINANET",
+		"3.0.0.0\t3.0.0.255\t64500\tID\tCONTOH",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := func(s string) *Rules {
+		r, err := ParseRules(strings.NewReader(s), "tes")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name       string
+		policy     *Policy
+		ip         string
+		wantReason string
+		wantOK     bool
+	}{
+		{"tanpa aturan", &Policy{}, "1.0.0.1", "", true},
+		{"policy nil", nil, "1.0.0.1", "", true},
+		{"deny CIDR", &Policy{Deny: rules("1.0.0.0/24 cdn")}, "1.0.0.1", "deny:cidr:cdn", false},
+		{"deny ASN", &Policy{Deny: rules("AS13335"), DB: db}, "1.0.0.1", "deny:asn:AS13335", false},
+		{"deny negara", &Policy{Deny: rules("country:cn"), DB: db}, "2.0.0.1", "deny:country:CN", false},
+		{"deny tidak cocok", &Policy{Deny: rules("country:cn"), DB: db}, "3.0.0.1", "", true},
+		{"allow cocok", &Policy{Allow: rules("country:ID"), DB: db}, "3.0.0.1", "", true},
+		{"allow tidak cocok", &Policy{Allow: rules("country:ID"), DB: db}, "1.0.0.1", "not_allowed", false},
+		{"IP di luar database", &Policy{Allow: rules("country:ID"), DB: db}, "4.0.0.1", "not_allowed", false},
+		{"deny menang atas allow", &Policy{Allow: rules("country:ID"), Deny: rules("3.0.0.0/24"), DB: db}, "3.0.0.1", "deny:cidr:tes", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := tt.policy.Check(netip.MustParseAddr(tt.ip))
+			if reason != tt.wantReason || ok != tt.wantOK {
+				t.Errorf("Check(%s) = %q %v, ingin %q %v", tt.ip, reason, ok, tt.wantReason, tt.wantOK)
+			}
+		})
+	}
+
+	if err := (&Policy{Deny: rules("AS4134")}).Validate(); err == nil {
+		t.Error("aturan ASN tanpa database lolos Validate")
+	}
+}
@@ -0,0 +1,168 @@
+package netfilter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Rules adalah kumpulan aturan jaringan berdasarkan CIDR, ASN dan negara.
+type Rules struct {
+	CIDRs     List
+	ASNs      map[uint32]string // ASN -> label
+	Countries map[string]bool   // kode negara ISO 3166 huruf besar
+}
+
+// Len mengembalikan jumlah aturan.
+func (r *Rules) Len() int {
+	if r == nil {
+		return 0
+	}
+	return r.CIDRs.Len() + len(r.ASNs) + len(r.Countries)
+}
+
+// needsASNDB melaporkan apakah aturan membutuhkan database ASN.
+func (r *Rules) needsASNDB() bool {
+	return r != nil && (len(r.ASNs) > 0 || len(r.Countries) > 0)
+}
+
+// ParseRules membaca aturan dari r. Format per baris:
+//
+//	203.0.113.0/24 [label]   rentang CIDR atau IP tunggal
+//	AS13335 [label]          nomor ASN
+//	country:CN               kode negara
+//
+// Komentar diawali '#' atau ';', sehingga daftar FireHOL, Spamhaus DROP dan
+// ASN-DROP versi teks bisa dipakai langsung. Versi JSON Spamhaus (baris
+// dengan field "cidr" atau "asn") juga diterima.
+func ParseRules(rd io.Reader, label string) (*Rules, error) {
+	rules := &Rules{
+		ASNs:      make(map[uint32]string),
+		Countries: make(map[string]bool),
+	}
+	if err := scanRules(rd, label, rules.add); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *Rules) add(value, label string) error {
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(lower, "country:"):
+		cc := strings.ToUpper(value[len("country:"):])
+		if len(cc) != 2 {
+			return fmt.Errorf("kode negara tidak valid: %q", value)
+		}
+		r.Countries[cc] = true
+	case strings.HasPrefix(lower, "as"):
+		asn, err := strconv.ParseUint(value[2:], 10, 32)
+		if err != nil {
+			return fmt.Errorf("ASN tidak valid: %q", value)
+		}
+		r.ASNs[uint32(asn)] = label
+	default:
+		prefix, err := parsePrefix(value)
+		if err != nil {
+			return err
+		}
+		r.CIDRs.Add(prefix, label)
+	}
+	return nil
+}
+
+// LoadRules membaca dan menggabungkan aturan dari beberapa file. Label
+// bawaan setiap aturan adalah nama file asalnya.
+func LoadRules(paths ...string) (*Rules, error) {
+	merged := &Rules{
+		ASNs:      make(map[uint32]string),
+		Countries: make(map[string]bool),
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		rules, err := ParseRules(f, filepath.Base(path))
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		merged.CIDRs.Merge(&rules.CIDRs)
+		for asn, label := range rules.ASNs {
+			merged.ASNs[asn] = label
+		}
+		for cc := range rules.Countries {
+			merged.Countries[cc] = true
+		}
+	}
+	return merged, nil
+}
+
+// Policy menggabungkan allow-list, deny-list dan database ASN. Deny selalu
+// menang; jika allow-list tidak kosong, hanya alamat yang cocok yang lolos.
+type Policy struct {
+	Allow *Rules
+	Deny  *Rules
+	DB    *ASNDB
+}
+
+// Active melaporkan apakah policy memiliki aturan.
+func (p *Policy) Active() bool {
+	return p != nil && (p.Allow.Len() > 0 || p.Deny.Len() > 0)
+}
+
+// Validate memastikan aturan ASN dan negara memiliki database untuk lookup.
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if (p.Allow.needsASNDB() || p.Deny.needsASNDB()) && p.DB.Len() == 0 {
+		return errors.New("aturan ASN/negara membutuhkan database ip2asn")
+	}
+	return nil
+}
+
+// Check mengembalikan alasan penolakan untuk ip, atau string kosong dan
+// true jika ip diizinkan.
+func (p *Policy) Check(ip netip.Addr) (string, bool) {
+	if !p.Active() {
+		return "", true
+	}
+	info, haveInfo := p.DB.Lookup(ip)
+
+	if reason, ok := p.Deny.match(ip, info, haveInfo); ok {
+		return "deny:" + reason, false
+	}
+	if p.Allow.Len() > 0 {
+		if _, ok := p.Allow.match(ip, info, haveInfo); !ok {
+			return "not_allowed", false
+		}
+	}
+	return "", true
+}
+
+func (r *Rules) match(ip netip.Addr, info ASNInfo, haveInfo bool) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	if label, ok := r.CIDRs.Match(ip); ok {
+		return "cidr:" + label, true
+	}
+	if !haveInfo {
+		return "", false
+	}
+	if _, ok := r.ASNs[info.ASN]; ok {
+		return fmt.Sprintf("asn:AS%d", info.ASN), true
+	}
+	if r.Countries[info.Country] {
+		return "country:" + info.Country, true
+	}
+	return "", false
+}
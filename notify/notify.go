@@ -0,0 +1,163 @@
+// Package notify mengirim peringatan tentang perubahan pool: ukuran pool di
+// bawah batas, sumber yang berhenti menghasilkan proxy, run yang selesai,
+// dan proxy baru dengan skor tinggi. Event dikirim ke sink: webhook JSON,
+// email SMTP, atau perintah lokal.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"deep.go/logging"
+)
+
+// Kind adalah jenis event.
+type Kind string
+
+const (
+	PoolLow        Kind = "pool_low"
+	SourceFailing  Kind = "source_failing"
+	RunCompleted   Kind = "run_completed"
+	HighScoreProxy Kind = "high_score_proxy"
+)
+
+// Event adalah satu peringatan. Text berisi pesan yang sama dengan Message
+// agar webhook Slack/Mattermost bisa menampilkannya tanpa transformasi.
+type Event struct {
+	Kind      Kind      `json:"kind"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	Text      string    `json:"text"`
+	Source    string    `json:"source,omitempty"`
+	Proxy     string    `json:"proxy,omitempty"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold,omitempty"`
+}
+
+func newEvent(kind Kind, message string) Event {
+	return Event{Kind: kind, Time: time.Now(), Message: message, Text: message}
+}
+
+// Rules mengatur kapan event dibuat. Nilai nol mematikan aturan tersebut.
+type Rules struct {
+	// MinPoolSize memicu PoolLow jika proxy valid kurang dari nilai ini.
+	MinPoolSize int
+	// FailureStreak memicu SourceFailing saat sumber gagal menghasilkan proxy
+	// valid tepat sebanyak ini run berturut-turut, sehingga satu sumber
+	// hanya diperingatkan sekali per rangkaian kegagalan.
+	FailureStreak int
+	// MinProxyScore memicu HighScoreProxy untuk proxy baru dengan skor
+	// (0..1) minimal nilai ini.
+	MinProxyScore float64
+	// MaxProxyEvents membatasi jumlah event HighScoreProxy per run.
+	MaxProxyEvents int
+}
+
+// DefaultMaxProxyEvents dipakai jika Rules.MaxProxyEvents nol.
+const DefaultMaxProxyEvents = 10
+
+// Run adalah hasil satu run yang dinilai oleh Rules.
+type Run struct {
+	Started time.Time
+	Checked int
+	Valid   int
+	// Streaks adalah jumlah run gagal berturut-turut per sumber.
+	Streaks map[string]int
+	// NewProxies adalah skor proxy yang valid di run ini tetapi tidak di run sebelumnya.
+	NewProxies map[string]float64
+}
+
+// Events mengembalikan event untuk run, diakhiri dengan RunCompleted.
+func (r Rules) Events(run Run) []Event {
+	var events []Event
+	if r.MinPoolSize > 0 && run.Valid < r.MinPoolSize {
+		e := newEvent(PoolLow, logging.T(logging.NotifyPoolLow, run.Valid, r.MinPoolSize))
+		e.Value, e.Threshold = float64(run.Valid), float64(r.MinPoolSize)
+		events = append(events, e)
+	}
+
+	if r.FailureStreak > 0 {
+		names := make([]string, 0, len(run.Streaks))
+		for name, streak := range run.Streaks {
+			if streak == r.FailureStreak {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			e := newEvent(SourceFailing, logging.T(logging.NotifySourceFailing, name, r.FailureStreak))
+			e.Source, e.Value, e.Threshold = name, float64(r.FailureStreak), float64(r.FailureStreak)
+			events = append(events, e)
+		}
+	}
+
+	if r.MinProxyScore > 0 {
+		addrs := make([]string, 0, len(run.NewProxies))
+		for addr, score := range run.NewProxies {
+			if score >= r.MinProxyScore {
+				addrs = append(addrs, addr)
+			}
+		}
+		sort.Slice(addrs, func(i, j int) bool {
+			if run.NewProxies[addrs[i]] != run.NewProxies[addrs[j]] {
+				return run.NewProxies[addrs[i]] > run.NewProxies[addrs[j]]
+			}
+			return addrs[i] < addrs[j]
+		})
+		limit := r.MaxProxyEvents
+		if limit <= 0 {
+			limit = DefaultMaxProxyEvents
+		}
+		if len(addrs) > limit {
+			addrs = addrs[:limit]
+		}
+		for _, addr := range addrs {
+			score := run.NewProxies[addr]
+			e := newEvent(HighScoreProxy, logging.T(logging.NotifyHighScore, addr, score))
+			e.Proxy, e.Value, e.Threshold = addr, score, r.MinProxyScore
+			events = append(events, e)
+		}
+	}
+
+	e := newEvent(RunCompleted, logging.T(logging.NotifyRunCompleted,
+		run.Valid, run.Checked, time.Since(run.Started).Round(time.Second)))
+	e.Value = float64(run.Valid)
+	return append(events, e)
+}
+
+// Sink adalah tujuan pengiriman event.
+type Sink interface {
+	Name() string
+	Notify(ctx context.Context, e Event) error
+}
+
+// Notifier mengirim event ke semua sink.
+type Notifier struct {
+	Sinks []Sink
+	// Timeout membatasi satu pengiriman ke satu sink; default 15 detik.
+	Timeout time.Duration
+}
+
+// Send mengirim setiap event ke setiap sink. Sink yang gagal tidak
+// menghentikan pengiriman ke sink lain; semua error digabung.
+func (n *Notifier) Send(ctx context.Context, events []Event) error {
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	var errs []error
+	for _, e := range events {
+		for _, sink := range n.Sinks {
+			sctx, cancel := context.WithTimeout(ctx, timeout)
+			err := sink.Notify(sctx, e)
+			cancel()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s (%s): %w", sink.Name(), e.Kind, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func kinds(events []Event) []Kind {
+	var out []Kind
+	for _, e := range events {
+		out = append(out, e.Kind)
+	}
+	return out
+}
+
+func TestRulesEvents(t *testing.T) {
+	rules := Rules{MinPoolSize: 50, FailureStreak: 3, MinProxyScore: 0.8, MaxProxyEvents: 1}
+	events := rules.Events(Run{
+		Started: time.Now(),
+		Checked: 100,
+		Valid:   10,
+		// Hanya sumber yang tepat mencapai ambang yang diperingatkan
+		Streaks:    map[string]int{"a": 3, "b": 4, "c": 1},
+		NewProxies: map[string]float64{"1.1.1.1:80": 0.95, "2.2.2.2:80": 0.9, "3.3.3.3:80": 0.5},
+	})
+
+	want := []Kind{PoolLow, SourceFailing, HighScoreProxy, RunCompleted}
+	if got := kinds(events); !slices.Equal(got, want) {
+		t.Fatalf("kinds = %v, want %v", got, want)
+	}
+	if events[1].Source != "a" {
+		t.Errorf("source = %q, want a", events[1].Source)
+	}
+	if events[2].Proxy != "1.1.1.1:80" {
+		t.Errorf("proxy = %q, want skor tertinggi 1.1.1.1:80", events[2].Proxy)
+	}
+
+	// Tanpa aturan aktif hanya RunCompleted yang dikirim
+	if got := kinds(Rules{}.Events(Run{Valid: 1})); !slices.Equal(got, []Kind{RunCompleted}) {
+		t.Errorf("kinds tanpa aturan = %v", got)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	received := make(chan Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer rahasia" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var e Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- e
+	}))
+	defer srv.Close()
+
+	sink := &Webhook{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer rahasia"}}
+	n := &Notifier{Sinks: []Sink{sink}}
+	event := newEvent(PoolLow, "pool rendah")
+	event.Value = 3
+	if err := n.Send(context.Background(), []Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	got := <-received
+	if got.Kind != PoolLow || got.Text != "pool rendah" || got.Value != 3 {
+		t.Errorf("payload = %+v", got)
+	}
+
+	// Status non-2xx dilaporkan sebagai error
+	sink.Headers = nil
+	if err := n.Send(context.Background(), []Event{event}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("err = %v, want HTTP 401", err)
+	}
+}
+
+func TestWebhookRedacted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	// Token di path dan query tidak boleh bocor lewat nama sink atau error
+	sink := &Webhook{URL: srv.URL + "/hooks/T000/rahasia?token=rahasia"}
+	if name := sink.Name(); name != "webhook "+srv.URL {
+		t.Errorf("Name = %q, ingin %q", name, "webhook "+srv.URL)
+	}
+	n := &Notifier{Sinks: []Sink{sink}}
+	err := n.Send(context.Background(), []Event{newEvent(PoolLow, "pool rendah")})
+	if err == nil || strings.Contains(err.Error(), "rahasia") {
+		t.Errorf("err = %v, ingin error tanpa token", err)
+	}
+}
+
+// fakeSMTP menjalankan server SMTP minimal yang menerima satu email.
+func fakeSMTP(t *testing.T) (addr string, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var body strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					out <- body.String()
+					reply("250 OK")
+					continue
+				}
+				body.WriteString(line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				inData = true
+				reply("354 lanjut")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestSMTP(t *testing.T) {
+	addr, data := fakeSMTP(t)
+	sink := &SMTP{Addr: addr, From: "scraper@example.com", To: []string{"ops@example.com"}}
+	event := newEvent(SourceFailing, "Sumber ProxyList-1 gagal")
+	event.Source = "ProxyList-1"
+	if err := sink.Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-data:
+		for _, want := range []string{"To: ops@example.com", "Subject: [proxyscraper] Sumber ProxyList-1 gagal", `"source": "ProxyList-1"`} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("email tidak berisi %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("email tidak diterima")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("butuh /bin/sh")
+	}
+	out := filepath.Join(t.TempDir(), "event.json")
+	sink := &Command{Path: "/bin/sh", Args: []string{"-c", `cat > "$1"; test "$PROXYSCRAPER_EVENT" = run_completed`, "sh", out}}
+	if err := sink.Notify(context.Background(), newEvent(RunCompleted, "selesai")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil || e.Kind != RunCompleted {
+		t.Errorf("stdin = %s (%v)", data, err)
+	}
+
+	failing := &Command{Path: "/bin/sh", Args: []string{"-c", "echo gagal >&2; exit 3"}}
+	if err := failing.Notify(context.Background(), newEvent(RunCompleted, "selesai")); err == nil || !strings.Contains(err.Error(), "gagal") {
+		t.Errorf("err = %v, want output perintah", err)
+	}
+}
@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Webhook mengirim event sebagai JSON dengan POST.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+	// Headers ditambahkan ke setiap request, misalnya token autentikasi.
+	Headers map[string]string
+}
+
+// Name hanya memuat skema dan host karena URL webhook (Slack, Discord,
+// dll.) sering menyimpan token rahasia di path atau query, dan nama sink
+// ikut tercetak di log dan pesan error.
+func (w *Webhook) Name() string { return "webhook " + redactURL(w.URL) }
+
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return stripURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return stripURL(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook membalas HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// redactURL menyisakan skema dan host dari raw.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(URL tidak valid)"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// stripURL membuang URL lengkap yang disisipkan net/http ke error; Notifier
+// sudah menambahkan Name sebagai konteks.
+func stripURL(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return fmt.Errorf("%s: %w", uerr.Op, uerr.Err)
+	}
+	return err
+}
+
+// SMTP mengirim event sebagai email teks. Auth PLAIN hanya dipakai jika
+// Username diisi; net/smtp menolak mengirim kredensial tanpa TLS kecuali
+// ke localhost.
+type SMTP struct {
+	// Addr adalah host:port server SMTP.
+	Addr     string
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+func (s *SMTP) Name() string { return "smtp " + s.Addr }
+
+func (s *SMTP) Notify(ctx context.Context, e Event) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	subject := mime.QEncoding.Encode("utf-8", fmt.Sprintf("[proxyscraper] %s", e.Message))
+	details, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", e.Time.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\n%s\r\n", e.Message, strings.ReplaceAll(string(details), "\n", "\r\n"))
+
+	// net/smtp tidak menerima context; pengiriman dijalankan di goroutine
+	// agar timeout tetap dihormati.
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(s.Addr, auth, s.From, s.To, msg.Bytes()) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Command menjalankan perintah lokal untuk setiap event. Event dikirim
+// sebagai JSON lewat stdin, dan jenis serta pesannya juga tersedia di
+// variabel lingkungan PROXYSCRAPER_EVENT dan PROXYSCRAPER_MESSAGE.
+type Command struct {
+	Path string
+	Args []string
+}
+
+func (c *Command) Name() string { return "command " + c.Path }
+
+func (c *Command) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"PROXYSCRAPER_EVENT="+string(e.Kind),
+		"PROXYSCRAPER_MESSAGE="+e.Message,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
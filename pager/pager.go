@@ -0,0 +1,185 @@
+// Package pager menjabarkan satu definisi sumber menjadi banyak fetch:
+// ekspansi parameter (misalnya protocol=http,socks5 atau daftar negara)
+// dan paginasi berbasis nomor halaman, offset, atau cursor.
+package pager
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// Mode adalah jenis paginasi sebuah sumber.
+type Mode string
+
+const (
+	// Page menaikkan nomor halaman: page=1, page=2, ...
+	Page Mode = "page"
+	// Offset menaikkan offset sebesar ukuran halaman: offset=0, offset=500, ...
+	Offset Mode = "offset"
+	// Cursor memakai token halaman berikutnya yang dikembalikan sumber.
+	Cursor Mode = "cursor"
+)
+
+// DefaultMaxPages membatasi jumlah halaman jika Pagination.MaxPages kosong,
+// agar sumber yang tidak pernah mengembalikan halaman kosong tidak diulang terus.
+const DefaultMaxPages = 20
+
+// Pagination mendeskripsikan cara sumber membagi hasilnya.
+type Pagination struct {
+	Mode Mode
+	// Param adalah nama query parameter untuk halaman, offset, atau cursor.
+	Param string
+	// Start adalah nilai awal; default 1 untuk Page dan 0 untuk Offset.
+	Start int
+	// Size adalah jumlah item per halaman. Jika SizeParam diisi, nilainya
+	// ikut dipasang di URL. Halaman yang lebih pendek dari Size dianggap terakhir.
+	Size      int
+	SizeParam string
+	// MaxPages membatasi jumlah halaman yang diambil.
+	MaxPages int
+}
+
+// Result adalah ringkasan satu halaman yang sudah diambil.
+type Result struct {
+	// Count adalah jumlah item di halaman ini; 0 menghentikan paginasi.
+	Count int
+	// Total adalah jumlah item yang dilaporkan sumber, 0 jika tidak diketahui.
+	Total int
+	// Next adalah cursor halaman berikutnya untuk mode Cursor.
+	Next string
+}
+
+// FetchFunc mengambil satu URL halaman dan melaporkan isinya.
+type FetchFunc func(pageURL string) (Result, error)
+
+// Expand menghasilkan satu URL untuk setiap kombinasi nilai params.
+// Urutan hasil deterministik: kunci diurutkan, nilai mengikuti urutan definisi.
+func Expand(rawURL string, params map[string][]string) ([]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(params))
+	for k, values := range params {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("parameter %q tidak punya nilai", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	combos := []url.Values{u.Query()}
+	for _, k := range keys {
+		next := make([]url.Values, 0, len(combos)*len(params[k]))
+		for _, base := range combos {
+			for _, v := range params[k] {
+				q := cloneValues(base)
+				q.Set(k, v)
+				next = append(next, q)
+			}
+		}
+		combos = next
+	}
+
+	urls := make([]string, len(combos))
+	for i, q := range combos {
+		c := *u
+		c.RawQuery = q.Encode()
+		urls[i] = c.String()
+	}
+	return urls, nil
+}
+
+// Walk mengambil halaman demi halaman mulai dari rawURL sampai salah satu
+// kondisi berhenti terpenuhi: halaman kosong, halaman lebih pendek dari Size,
+// total item sudah tercapai, cursor habis atau berulang, atau MaxPages.
+// Jika p nil, rawURL diambil satu kali. Walk mengembalikan jumlah halaman
+// yang berhasil diambil; error dari fetch menghentikan paginasi.
+func Walk(rawURL string, p *Pagination, fetch FetchFunc) (int, error) {
+	if p == nil {
+		if _, err := fetch(rawURL); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, err
+	}
+	q := u.Query()
+	if p.SizeParam != "" && p.Size > 0 {
+		q.Set(p.SizeParam, strconv.Itoa(p.Size))
+	}
+
+	maxPages := p.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+	position := p.Start
+	if position == 0 && p.Mode == Page {
+		position = 1
+	}
+
+	var cursor string
+	seenCursors := make(map[string]bool)
+	seen := 0
+	for pages := 0; pages < maxPages; {
+		switch p.Mode {
+		case Page, Offset:
+			q.Set(p.Param, strconv.Itoa(position))
+		case Cursor:
+			if cursor != "" {
+				q.Set(p.Param, cursor)
+			}
+		default:
+			return pages, fmt.Errorf("mode paginasi tidak dikenal: %q", p.Mode)
+		}
+		c := *u
+		c.RawQuery = q.Encode()
+
+		res, err := fetch(c.String())
+		if err != nil {
+			return pages, err
+		}
+		pages++
+		seen += res.Count
+
+		switch {
+		case res.Count == 0:
+			return pages, nil
+		case p.Size > 0 && res.Count < p.Size:
+			return pages, nil
+		case res.Total > 0 && seen >= res.Total:
+			return pages, nil
+		}
+
+		switch p.Mode {
+		case Page:
+			position++
+		case Offset:
+			step := p.Size
+			if step <= 0 {
+				step = res.Count
+			}
+			position += step
+		case Cursor:
+			if res.Next == "" || seenCursors[res.Next] {
+				return pages, nil
+			}
+			seenCursors[res.Next] = true
+			cursor = res.Next
+		}
+	}
+	return maxPages, nil
+}
+
+func cloneValues(v url.Values) url.Values {
+	c := make(url.Values, len(v))
+	for k, vals := range v {
+		c[k] = append([]string(nil), vals...)
+	}
+	return c
+}
@@ -0,0 +1,109 @@
+package pager
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	urls, err := Expand("https://x.example/api?fmt=txt", map[string][]string{
+		"protocol": {"http", "socks5"},
+		"country":  {"ID", "SG"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"https://x.example/api?country=ID&fmt=txt&protocol=http",
+		"https://x.example/api?country=ID&fmt=txt&protocol=socks5",
+		"https://x.example/api?country=SG&fmt=txt&protocol=http",
+		"https://x.example/api?country=SG&fmt=txt&protocol=socks5",
+	}
+	if !slices.Equal(urls, want) {
+		t.Errorf("Expand =\n%v\ningin\n%v", urls, want)
+	}
+
+	if urls, err := Expand("https://x.example/a", nil); err != nil || len(urls) != 1 {
+		t.Errorf("Expand tanpa params = %v %v", urls, err)
+	}
+	if _, err := Expand("https://x.example/a", map[string][]string{"p": nil}); err == nil {
+		t.Error("parameter tanpa nilai diterima")
+	}
+}
+
+// pages membuat FetchFunc yang membalas counts berurutan dan mencatat URL.
+func pages(counts []int, total int, next []string) (FetchFunc, *[]string) {
+	var urls []string
+	return func(pageURL string) (Result, error) {
+		i := len(urls)
+		urls = append(urls, pageURL)
+		res := Result{Total: total}
+		if i < len(counts) {
+			res.Count = counts[i]
+		}
+		if i < len(next) {
+			res.Next = next[i]
+		}
+		return res, nil
+	}, &urls
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         *Pagination
+		counts    []int
+		total     int
+		next      []string
+		wantPages int
+		wantLast  string
+	}{
+		{"tanpa paginasi", nil, []int{5}, 0, nil, 1, "https://x.example/l"},
+		{"halaman kosong", &Pagination{Mode: Page, Param: "page"}, []int{5, 5, 0}, 0, nil, 3, "https://x.example/l?page=3"},
+		{"halaman pendek", &Pagination{Mode: Page, Param: "page", Size: 5}, []int{5, 3}, 0, nil, 2, "https://x.example/l?page=2"},
+		{"total tercapai", &Pagination{Mode: Page, Param: "p", Start: 0}, []int{4, 4, 4}, 8, nil, 2, "https://x.example/l?p=2"},
+		{"offset dengan size", &Pagination{Mode: Offset, Param: "offset", Size: 10, SizeParam: "limit"}, []int{10, 10, 2}, 0, nil, 3, "https://x.example/l?limit=10&offset=20"},
+		{"offset tanpa size", &Pagination{Mode: Offset, Param: "skip"}, []int{7, 7, 0}, 0, nil, 3, "https://x.example/l?skip=14"},
+		{"MaxPages", &Pagination{Mode: Page, Param: "page", MaxPages: 2}, []int{5, 5, 5}, 0, nil, 2, "https://x.example/l?page=2"},
+		{"cursor habis", &Pagination{Mode: Cursor, Param: "c"}, []int{5, 5}, 0, []string{"abc"}, 2, "https://x.example/l?c=abc"},
+		{"cursor berulang", &Pagination{Mode: Cursor, Param: "c"}, []int{5, 5, 5, 5}, 0, []string{"a", "b", "a"}, 3, "https://x.example/l?c=b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetch, urls := pages(tt.counts, tt.total, tt.next)
+			n, err := Walk("https://x.example/l", tt.p, fetch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.wantPages || len(*urls) != tt.wantPages {
+				t.Fatalf("Walk = %d halaman (%v), ingin %d", n, *urls, tt.wantPages)
+			}
+			if last := (*urls)[len(*urls)-1]; last != tt.wantLast {
+				t.Errorf("URL terakhir = %s, ingin %s", last, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	failAt := 2
+	calls := 0
+	boom := errors.New("HTTP 500")
+	fetch := func(string) (Result, error) {
+		calls++
+		if calls == failAt {
+			return Result{}, boom
+		}
+		return Result{Count: 5}, nil
+	}
+	// Error menghentikan paginasi; halaman yang sudah berhasil tetap dihitung
+	n, err := Walk("https://x.example/l", &Pagination{Mode: Page, Param: "page"}, fetch)
+	if !errors.Is(err, boom) || n != 1 {
+		t.Errorf("Walk = %d %v, ingin 1 halaman dan error", n, err)
+	}
+
+	if _, err := Walk("https://x.example/l", &Pagination{Mode: "acak", Param: "x"}, fetch); err == nil {
+		t.Error("mode tidak dikenal diterima")
+	}
+}
@@ -0,0 +1,182 @@
+package parse
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"deep.go/netfilter"
+)
+
+// Alasan proxy dibuang pada tahap normalisasi
+const (
+	DropInvalidIP   = "invalid_ip"
+	DropInvalidPort = "invalid_port"
+	DropBogon       = "bogon"
+	DropUnresolved  = "policy_unresolved"
+)
+
+// Normalize mengubah alamat ke bentuk kanonik (IPv4 tanpa nol di depan,
+// IPv6 ringkas huruf kecil, hostname huruf kecil, port tanpa nol di depan)
+// lalu membuang alamat yang termasuk deny (biasanya netfilter.Bogons) atau
+// ditolak policy allow/deny. deny dan policy boleh nil. Jumlah proxy yang
+// dibuang dikembalikan per alasan, dengan label rentang untuk bogon.
+func Normalize(proxies []Proxy, deny *netfilter.List, policy *netfilter.Policy) ([]Proxy, map[string]int) {
+	var normalized []Proxy
+	dropped := make(map[string]int)
+
+	for _, proxy := range proxies {
+		port, err := netfilter.ParsePort(proxy.Port)
+		if err != nil {
+			dropped[DropInvalidPort]++
+			continue
+		}
+
+		host := strings.TrimSuffix(strings.ToLower(proxy.Host), ".")
+		ip := ""
+		if proxy.IP != "" {
+			addr, err := netfilter.ParseIP(proxy.IP)
+			if err != nil {
+				dropped[DropInvalidIP]++
+				continue
+			}
+			if label, ok := deny.Match(addr); ok {
+				dropped[DropBogon+":"+label]++
+				continue
+			}
+			if why, ok := policy.Check(addr); !ok {
+				dropped[why]++
+				continue
+			}
+			ip = addr.String()
+			if validIP(proxy.Host) {
+				host = ip
+			}
+		} else if policy.Active() {
+			// Hostname tanpa IP tidak bisa dinilai oleh policy jaringan
+			dropped[DropUnresolved]++
+			continue
+		}
+
+		canonical := New(proxy.Scheme, host, port, proxy.Username, proxy.Password)
+		canonical.IP = ip
+		canonical.Sources = proxy.Sources
+		normalized = append(normalized, canonical)
+	}
+
+	// Alamat yang berbeda penulisan bisa menjadi sama setelah normalisasi
+	return Dedup(normalized), dropped
+}
+
+// FilterPolicy membuang proxy yang ditolak policy allow/deny. policy boleh nil.
+func FilterPolicy(proxies []Proxy, policy *netfilter.Policy) ([]Proxy, map[string]int) {
+	if !policy.Active() {
+		return proxies, nil
+	}
+
+	var allowed []Proxy
+	dropped := make(map[string]int)
+	for _, proxy := range proxies {
+		addr, err := netfilter.ParseIP(proxy.IP)
+		if err != nil {
+			dropped[DropUnresolved]++
+			continue
+		}
+		if why, ok := policy.Check(addr); !ok {
+			dropped[why]++
+			continue
+		}
+		allowed = append(allowed, proxy)
+	}
+	return allowed, dropped
+}
+
+// Resolve mengisi IP untuk proxy dengan hostname lalu menghapus duplikat
+// berdasarkan alamat hasil resolusi. Hostname yang gagal di-resolve dibuang
+// dan jumlahnya dikembalikan. concurrency membatasi lookup DNS bersamaan.
+func Resolve(ctx context.Context, proxies []Proxy, concurrency int) ([]Proxy, int) {
+	resolved := make([]Proxy, len(proxies))
+	ok := make([]bool, len(proxies))
+	sem := make(chan struct{}, max(1, concurrency))
+	var wg sync.WaitGroup
+
+	for i, proxy := range proxies {
+		if proxy.IP != "" {
+			resolved[i], ok[i] = proxy, true
+			continue
+		}
+		wg.Add(1)
+		go func(i int, proxy Proxy) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", proxy.Host)
+			if err != nil || len(ips) == 0 {
+				return
+			}
+			// Utamakan IPv4, IPv6 dipakai jika hanya itu yang tersedia
+			ip := ips[0]
+			for _, candidate := range ips {
+				if candidate.Is4() || candidate.Is4In6() {
+					ip = candidate
+					break
+				}
+			}
+			proxy.IP = ip.Unmap().String()
+			resolved[i], ok[i] = proxy, true
+		}(i, proxy)
+	}
+	wg.Wait()
+
+	var result []Proxy
+	failed := 0
+	for i := range resolved {
+		if ok[i] {
+			result = append(result, resolved[i])
+		} else {
+			failed++
+		}
+	}
+	return Dedup(result), failed
+}
+
+// Dedup membuang proxy dengan Key yang sama. Daftar sumber duplikat
+// digabung ke entri pertama agar kontribusi unik per sumber bisa dihitung.
+func Dedup(proxies []Proxy) []Proxy {
+	seen := make(map[string]int)
+	var unique []Proxy
+
+	for _, proxy := range proxies {
+		key := proxy.Key()
+		if i, ok := seen[key]; ok {
+			unique[i].Sources = mergeSources(unique[i].Sources, proxy.Sources)
+			continue
+		}
+		seen[key] = len(unique)
+		unique = append(unique, proxy)
+	}
+
+	return unique
+}
+
+func mergeSources(a, b []string) []string {
+	merged := append([]string(nil), a...)
+	for _, name := range b {
+		found := false
+		for _, existing := range merged {
+			if existing == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,289 @@
+// Package parse mengenali proxy di dalam teks bebas dan mengubahnya ke
+// bentuk kanonik. Proxy adalah tipe dasar yang dipakai package lain:
+// sources, check, pool, export dan gateway.
+package parse
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"deep.go/netfilter"
+)
+
+// Proxy adalah satu proxy beserta protokol, kredensial dan asal sumbernya.
+type Proxy struct {
+	Host string // IP atau hostname seperti yang tertulis di sumber
+	IP   string // alamat IP; untuk hostname baru terisi setelah resolusi DNS
+	Port string
+	Full string // host:port seperti di sumber, IPv6 ditulis dalam kurung siku
+
+	// Scheme adalah protokol proxy: http (default), https, socks5 atau socks5h
+	Scheme string
+	// Kredensial untuk proxy berbayar/privat; dikirim sebagai
+	// Proxy-Authorization untuk HTTP dan autentikasi RFC 1929 untuk SOCKS5
+	Username string
+	Password string
+
+	// Sources adalah nama sumber yang mencantumkan proxy ini
+	Sources []string
+}
+
+// New membuat Proxy dari bagian-bagiannya. IP terisi jika host adalah alamat IP.
+func New(scheme, host, port, user, pass string) Proxy {
+	ip := ""
+	if validIP(host) {
+		ip = host
+	}
+	return Proxy{
+		Host:     host,
+		IP:       ip,
+		Port:     port,
+		Full:     net.JoinHostPort(host, port),
+		Scheme:   scheme,
+		Username: user,
+		Password: pass,
+	}
+}
+
+// HasAuth melaporkan apakah proxy membutuhkan kredensial.
+func (p Proxy) HasAuth() bool {
+	return p.Username != "" || p.Password != ""
+}
+
+// Protocol mengembalikan Scheme, atau "http" jika kosong.
+func (p Proxy) Protocol() string {
+	if p.Scheme == "" {
+		return "http"
+	}
+	return p.Scheme
+}
+
+// URL mengembalikan alamat proxy lengkap dengan kredensial untuk dipakai
+// oleh http.Transport.
+func (p Proxy) URL() *url.URL {
+	u := &url.URL{Scheme: p.Protocol(), Host: p.Full}
+	if p.HasAuth() {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u
+}
+
+// Export mengembalikan bentuk proxy untuk ditulis ke file. Proxy HTTP tanpa
+// kredensial tetap ditulis sebagai ip:port agar file lama tetap kompatibel.
+func (p Proxy) Export() string {
+	if !p.HasAuth() && p.Protocol() == "http" {
+		return p.Full
+	}
+	return p.URL().String()
+}
+
+// String mengembalikan bentuk proxy yang aman untuk log: password disamarkan.
+func (p Proxy) String() string {
+	if !p.HasAuth() {
+		if p.Protocol() == "http" {
+			return p.Full
+		}
+		return p.Protocol() + "://" + p.Full
+	}
+	return fmt.Sprintf("%s://%s:***@%s", p.Protocol(), p.Username, p.Full)
+}
+
+// Addr mengembalikan alamat yang dipakai untuk dial dan deduplikasi: IP hasil
+// resolusi jika ada, selain itu host:port seperti di sumber.
+func (p Proxy) Addr() string {
+	if p.IP != "" {
+		return net.JoinHostPort(p.IP, p.Port)
+	}
+	return p.Full
+}
+
+// Key mengidentifikasi proxy untuk deduplikasi. Proxy yang sama dengan
+// kredensial berbeda dianggap entri terpisah; hostname yang sudah
+// di-resolve dibandingkan lewat alamat IP-nya.
+func (p Proxy) Key() string {
+	return p.Protocol() + "://" + p.Username + ":" + p.Password + "@" + p.Addr()
+}
+
+// Regex untuk menangkap format IP:Port dan [IPv6]:Port di dalam teks bebas
+var (
+	ipPortPattern   = regexp.MustCompile(`(\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}):(\d{1,5})`)
+	ipv6PortPattern = regexp.MustCompile(`\[([0-9a-fA-F:.]+)\]:(\d{1,5})`)
+)
+
+// Skema proxy yang bisa dicek oleh http.Transport
+var supportedSchemes = map[string]bool{
+	"http":    true,
+	"https":   true,
+	"socks5":  true,
+	"socks5h": true,
+}
+
+// Text mengambil semua proxy dari teks bebas: daftar satu proxy per baris,
+// CSV, HTML atau JSON. Token yang dikenali Token dipakai apa adanya;
+// selain itu IP:Port dicari di dalam token.
+func Text(content string) []Proxy {
+	var proxies []Proxy
+
+	// Pecah konten menjadi token agar format dengan kredensial bisa dikenali
+	tokens := strings.FieldsFunc(content, isTokenSeparator)
+
+	for _, token := range tokens {
+		if proxy, ok := Token(token); ok {
+			proxies = append(proxies, proxy)
+			continue
+		}
+
+		// Fallback: cari IP:Port di dalam token seperti format lama
+		matches := ipPortPattern.FindAllStringSubmatch(token, -1)
+		matches = append(matches, ipv6PortPattern.FindAllStringSubmatch(token, -1)...)
+		for _, match := range matches {
+			ip := match[1]
+			port := match[2]
+
+			// Validasi IP dan Port
+			if validIP(ip) && validPort(port) {
+				proxies = append(proxies, New("", ip, port, "", ""))
+			}
+		}
+	}
+
+	return proxies
+}
+
+func isTokenSeparator(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', ',', ';', '"', '\'', '<', '>', '|':
+		return true
+	}
+	return false
+}
+
+// Token mengenali format proxy yang umum dipakai. Host boleh berupa IPv4,
+// IPv6 dalam kurung siku, atau hostname:
+//
+//	host:port
+//	user:pass@host:port
+//	host:port@user:pass
+//	host:port:user:pass
+//	scheme://[user:pass@]host:port
+func Token(token string) (Proxy, bool) {
+	if strings.Contains(token, "://") {
+		u, err := url.Parse(token)
+		if err != nil || !supportedSchemes[strings.ToLower(u.Scheme)] {
+			return Proxy{}, false
+		}
+		host, port := u.Hostname(), u.Port()
+		if !validHost(host) || !validPort(port) {
+			return Proxy{}, false
+		}
+		user, pass := "", ""
+		if u.User != nil {
+			user = u.User.Username()
+			pass, _ = u.User.Password()
+		}
+		return New(strings.ToLower(u.Scheme), host, port, user, pass), true
+	}
+
+	if at := strings.LastIndex(token, "@"); at >= 0 {
+		left, right := token[:at], token[at+1:]
+		if host, port, rest, ok := cutHostPort(right); ok && rest == "" {
+			user, pass := splitCredentials(left)
+			return New("", host, port, user, pass), user != ""
+		}
+		if host, port, rest, ok := cutHostPort(left); ok && rest == "" {
+			user, pass := splitCredentials(right)
+			return New("", host, port, user, pass), user != ""
+		}
+		return Proxy{}, false
+	}
+
+	host, port, rest, ok := cutHostPort(token)
+	if !ok {
+		return Proxy{}, false
+	}
+	if rest == "" {
+		return New("", host, port, "", ""), true
+	}
+	user, pass := splitCredentials(rest)
+	return New("", host, port, user, pass), user != ""
+}
+
+// cutHostPort memisahkan host dan port di awal s. Sisa setelah port (tanpa
+// ':' pemisah) dikembalikan sebagai rest. IPv6 harus dalam kurung siku.
+func cutHostPort(s string) (host, port, rest string, ok bool) {
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]:")
+		if end < 0 {
+			return "", "", "", false
+		}
+		host, s = s[1:end], s[end+2:]
+		if !strings.Contains(host, ":") {
+			return "", "", "", false
+		}
+	} else {
+		var found bool
+		host, s, found = strings.Cut(s, ":")
+		if !found {
+			return "", "", "", false
+		}
+	}
+	port, rest, _ = strings.Cut(s, ":")
+	if !validHost(host) || !validPort(port) {
+		return "", "", "", false
+	}
+	return host, port, rest, true
+}
+
+// splitCredentials memisahkan user:pass; password boleh mengandung ':'.
+func splitCredentials(s string) (string, string) {
+	user, pass, _ := strings.Cut(s, ":")
+	return user, pass
+}
+
+// validIP menerima semua IP yang bisa dinormalisasi, termasuk IPv4 dengan
+// nol di depan oktet; bentuk kanoniknya ditentukan di Normalize.
+func validIP(ip string) bool {
+	_, err := netfilter.ParseIP(ip)
+	return err == nil
+}
+
+// validHost menerima alamat IP atau hostname dengan minimal dua label dan
+// TLD alfabet, agar token acak seperti "Port:8080" tidak dianggap proxy.
+func validHost(host string) bool {
+	if validIP(host) {
+		return true
+	}
+	if len(host) > 253 {
+		return false
+	}
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, char := range label {
+			if !(char == '-' || (char >= '0' && char <= '9') || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')) {
+				return false
+			}
+		}
+	}
+	tld := labels[len(labels)-1]
+	for _, char := range tld {
+		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')) {
+			return false
+		}
+	}
+	return len(tld) >= 2
+}
+
+func validPort(port string) bool {
+	// Port harus antara 1-65535
+	_, err := netfilter.ParsePort(port)
+	return err == nil
+}
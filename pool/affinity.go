@@ -0,0 +1,117 @@
+package pool
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Affinity menjaga kunci afinitas (ID sesi, host tujuan, cookie jar) tetap
+// terikat ke proxy yang sama selama TTL sejak terakhir dipakai, sehingga
+// alur yang butuh IP keluar tetap, misalnya login, tidak terputus.
+//
+// Proxy untuk kunci baru, dan pengganti jika proxy terikat sudah tidak ada
+// di kandidat, dipilih dengan rendezvous hashing atas kunci proxy saja.
+// Skor sengaja tidak dipakai karena setiap proses memperbaruinya dari
+// laporannya sendiri; dengan kandidat yang sama, gateway dan API pool di
+// proses berbeda memilih pengganti yang sama untuk kunci yang sama.
+type Affinity struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	bindings  map[string]binding
+	lastSweep time.Time
+}
+
+type binding struct {
+	proxy   string
+	expires time.Time
+}
+
+// NewAffinity membuat Affinity dengan ttl; ttl nol atau negatif berarti 10 menit.
+func NewAffinity(ttl time.Duration) *Affinity {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	return &Affinity{ttl: ttl, now: time.Now, bindings: make(map[string]binding)}
+}
+
+// Pick memilih proxy untuk key dari candidates (tidak boleh kosong) dan
+// memperpanjang ikatannya. Jika proxy terikat tidak ada di candidates,
+// misalnya sudah dibuang dari pool atau gagal pada request ini, key
+// dipindah ke pengganti yang deterministik.
+func (a *Affinity) Pick(key string, candidates []Entry) Entry {
+	now := a.now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sweep(now)
+
+	chosen, found := Entry{}, false
+	if b, ok := a.bindings[key]; ok && now.Before(b.expires) {
+		for _, e := range candidates {
+			if e.Key() == b.proxy {
+				chosen, found = e, true
+				break
+			}
+		}
+	}
+	if !found {
+		chosen = rendezvous(key, candidates)
+	}
+	a.bindings[key] = binding{proxy: chosen.Key(), expires: now.Add(a.ttl)}
+	return chosen
+}
+
+// Forget melepas ikatan key, misalnya saat sesi di sisi pemakai berakhir.
+func (a *Affinity) Forget(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.bindings, key)
+}
+
+// Len mengembalikan jumlah ikatan yang belum kedaluwarsa.
+func (a *Affinity) Len() int {
+	now := a.now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := 0
+	for _, b := range a.bindings {
+		if now.Before(b.expires) {
+			n++
+		}
+	}
+	return n
+}
+
+// sweep membuang ikatan kedaluwarsa paling sering sekali per TTL.
+func (a *Affinity) sweep(now time.Time) {
+	if now.Sub(a.lastSweep) < a.ttl {
+		return
+	}
+	a.lastSweep = now
+	for key, b := range a.bindings {
+		if !now.Before(b.expires) {
+			delete(a.bindings, key)
+		}
+	}
+}
+
+// rendezvous memilih kandidat dengan hash (key, proxy) tertinggi. Setiap
+// proxy berpeluang sama, dan membuang satu proxy hanya memindahkan kunci
+// yang terikat ke proxy itu. Hash yang sama dipecah dengan kunci proxy agar
+// urutan kandidat tidak berpengaruh.
+func rendezvous(key string, candidates []Entry) Entry {
+	best, bestHash := candidates[0], uint64(0)
+	for i, e := range candidates {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(e.Key()))
+		sum := h.Sum64()
+		if i == 0 || sum > bestHash || (sum == bestHash && e.Key() < best.Key()) {
+			best, bestHash = e, sum
+		}
+	}
+	return best
+}
@@ -0,0 +1,106 @@
+package pool
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"deep.go/parse"
+)
+
+func testEntries(n int) []Entry {
+	var entries []Entry
+	for i := range n {
+		proxy, _ := parse.Token(fmt.Sprintf("10.0.0.%d:8080", i+1))
+		entries = append(entries, Entry{Proxy: proxy, Score: 0.5})
+	}
+	return entries
+}
+
+func without(entries []Entry, key string) []Entry {
+	var out []Entry
+	for _, e := range entries {
+		if e.Key() != key {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestAffinitySticky(t *testing.T) {
+	entries := testEntries(8)
+	a := NewAffinity(time.Minute)
+	first := a.Pick("sesi", entries)
+
+	// Proxy baru yang masuk pool tidak merebut sesi yang sudah terikat
+	more := append(testEntries(8), testEntries(30)[8:]...)
+	for range 5 {
+		if got := a.Pick("sesi", more); got.Key() != first.Key() {
+			t.Fatalf("sesi pindah dari %s ke %s", first.Key(), got.Key())
+		}
+	}
+}
+
+func TestAffinityFailover(t *testing.T) {
+	entries := testEntries(8)
+	// Dua instance terpisah, misalnya gateway dan API pool
+	a, b := NewAffinity(time.Minute), NewAffinity(time.Minute)
+	bound := a.Pick("sesi", entries)
+	if got := b.Pick("sesi", entries); got.Key() != bound.Key() {
+		t.Fatalf("pilihan awal berbeda: %s vs %s", bound.Key(), got.Key())
+	}
+
+	rest := without(entries, bound.Key())
+	failover := a.Pick("sesi", rest)
+	if failover.Key() == bound.Key() {
+		t.Fatal("proxy mati masih dipilih")
+	}
+	if got := b.Pick("sesi", rest); got.Key() != failover.Key() {
+		t.Errorf("pengganti tidak deterministik: %s vs %s", failover.Key(), got.Key())
+	}
+	// Setelah pindah, sesi tetap di pengganti walaupun proxy lama kembali
+	if got := a.Pick("sesi", entries); got.Key() != failover.Key() {
+		t.Errorf("sesi kembali ke %s, want tetap di %s", got.Key(), failover.Key())
+	}
+}
+
+func TestAffinityIgnoresScore(t *testing.T) {
+	// Skor berbeda per proses karena diperbarui dari laporan masing-masing;
+	// pilihan untuk kunci baru dan pengganti tidak boleh ikut berbeda
+	entries := testEntries(8)
+	rescored := testEntries(8)
+	for i := range rescored {
+		rescored[i].Score = float64(i+1) / 10
+	}
+	a, b := NewAffinity(time.Minute), NewAffinity(time.Minute)
+	for _, key := range []string{"sesi-1", "sesi-2", "sesi-3", "example.com"} {
+		if x, y := a.Pick(key, entries), b.Pick(key, rescored); x.Key() != y.Key() {
+			t.Errorf("%s: %s vs %s dengan skor berbeda", key, x.Key(), y.Key())
+		}
+	}
+}
+
+func TestAffinityTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	a := NewAffinity(time.Minute)
+	a.now = func() time.Time { return now }
+
+	entries := testEntries(8)
+	first := a.Pick("sesi", entries)
+	rest := without(entries, first.Key())
+	second := a.Pick("sesi", rest)
+
+	// Ikatan diperpanjang setiap dipakai, lalu kedaluwarsa setelah TTL
+	// tanpa pemakaian sehingga pilihan kembali mengikuti rendezvous
+	now = now.Add(50 * time.Second)
+	if got := a.Pick("sesi", entries); got.Key() != second.Key() {
+		t.Fatalf("ikatan hilang sebelum TTL")
+	}
+	now = now.Add(2 * time.Minute)
+	if a.Len() != 0 {
+		t.Errorf("Len = %d setelah TTL", a.Len())
+	}
+	if got := a.Pick("sesi", entries); got.Key() != first.Key() {
+		t.Errorf("setelah TTL = %s, want %s", got.Key(), first.Key())
+	}
+}
@@ -0,0 +1,153 @@
+package pool
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"deep.go/parse"
+)
+
+// APIEntry adalah bentuk JSON Entry pada API pool.
+type APIEntry struct {
+	// Proxy dalam bentuk ekspor, termasuk kredensial; sekaligus key di pool.
+	Proxy     string    `json:"proxy"`
+	Score     float64   `json:"score"`
+	LatencyMs float64   `json:"latency_ms,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	Anonymity string    `json:"anonymity,omitempty"`
+	Failures  int       `json:"failures,omitempty"`
+	CheckedAt time.Time `json:"checked_at,omitzero"`
+}
+
+// API mengubah entri ke bentuk JSON API.
+func (e Entry) API() APIEntry {
+	return APIEntry{
+		Proxy:     e.Key(),
+		Score:     e.Score,
+		LatencyMs: e.LatencyMs,
+		Country:   e.Country,
+		Anonymity: e.Anonymity,
+		Failures:  e.Failures,
+		CheckedAt: e.CheckedAt,
+	}
+}
+
+// Entry mengubah bentuk JSON API kembali ke Entry.
+func (a APIEntry) Entry() (Entry, bool) {
+	proxy, ok := parse.Token(a.Proxy)
+	if !ok {
+		return Entry{}, false
+	}
+	return Entry{
+		Proxy:     proxy,
+		Score:     a.Score,
+		LatencyMs: a.LatencyMs,
+		Country:   a.Country,
+		Anonymity: a.Anonymity,
+		Failures:  a.Failures,
+		CheckedAt: a.CheckedAt,
+	}, true
+}
+
+// Report adalah body POST /report: hasil pemakaian satu proxy. Body juga
+// boleh berupa array Report untuk mengirim beberapa laporan sekaligus.
+type Report struct {
+	Proxy     string  `json:"proxy"`
+	OK        bool    `json:"ok"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+}
+
+// APIOptions mengatur pemilihan proxy pada GET /random.
+type APIOptions struct {
+	// Strategy untuk request tanpa kunci sesi. Default Random.
+	Strategy Strategy
+	// Affinity untuk request dengan ?session=KEY; nil berarti kunci sesi
+	// diabaikan.
+	Affinity *Affinity
+}
+
+// Handler melayani API pool untuk pemakai di proses lain:
+//
+//	GET  /proxies?limit=N                     semua entri, skor tertinggi dulu
+//	GET  /random?session=KEY&exclude=PROXY    satu entri untuk sesi atau strategy
+//	POST /report                              laporan Report (atau array) untuk skor kesehatan
+func Handler(p *Pool, opts APIOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /proxies", func(w http.ResponseWriter, r *http.Request) {
+		entries := p.Entries()
+		if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit >= 0 {
+			entries = entries[:min(limit, len(entries))]
+		}
+		out := make([]APIEntry, len(entries))
+		for i, e := range entries {
+			out[i] = e.API()
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+	mux.HandleFunc("GET /random", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		exclude := make(map[string]bool)
+		for _, key := range query["exclude"] {
+			exclude[key] = true
+		}
+		var e Entry
+		var ok bool
+		if session := query.Get("session"); session != "" && opts.Affinity != nil {
+			e, ok = p.PickSticky(opts.Affinity, session, exclude)
+		} else {
+			e, ok = p.Pick(opts.Strategy, exclude)
+		}
+		if !ok {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "pool kosong"})
+			return
+		}
+		writeJSON(w, http.StatusOK, e.API())
+	})
+	mux.HandleFunc("POST /report", func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body laporan tidak valid"})
+			return
+		}
+		if batch := bytes.TrimSpace(raw); len(batch) > 0 && batch[0] == '[' {
+			var reports []Report
+			if err := json.Unmarshal(batch, &reports); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body laporan tidak valid"})
+				return
+			}
+			removed := 0
+			for _, report := range reports {
+				if report.Proxy != "" && apply(p, report) {
+					removed++
+				}
+			}
+			writeJSON(w, http.StatusOK, map[string]int{"removed": removed})
+			return
+		}
+		var report Report
+		if err := json.Unmarshal(raw, &report); err != nil || report.Proxy == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body laporan tidak valid"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"removed": apply(p, report)})
+	})
+	return mux
+}
+
+// apply menerapkan satu laporan ke p dan melaporkan apakah proxy dibuang.
+func apply(p *Pool, report Report) bool {
+	if report.OK {
+		p.ReportSuccess(report.Proxy, time.Duration(report.LatencyMs*float64(time.Millisecond)))
+		return false
+	}
+	return p.ReportFailure(report.Proxy)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
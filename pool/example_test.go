@@ -0,0 +1,29 @@
+package pool_test
+
+import (
+	"fmt"
+	"time"
+
+	"deep.go/parse"
+	"deep.go/pool"
+)
+
+func Example() {
+	p := pool.New(pool.Options{Timeout: 10 * time.Second, MaxFailures: 2})
+	for _, addr := range []string{"1.1.1.1:80", "2.2.2.2:80"} {
+		proxy, _ := parse.Token(addr)
+		p.Add(pool.Entry{Proxy: proxy, Score: pool.Score(1000, 10*time.Second)})
+	}
+
+	// Pemakai melaporkan hasil pemakaian proxy kembali ke pool
+	bad, _ := parse.Token("2.2.2.2:80")
+	p.ReportFailure(pool.KeyOf(bad))
+	for _, e := range p.Entries() {
+		fmt.Printf("%s %.2f\n", e.Proxy, e.Score)
+	}
+	fmt.Println(p.ReportFailure(pool.KeyOf(bad)), p.Len())
+	// Output:
+	// 1.1.1.1:80 0.90
+	// 2.2.2.2:80 0.45
+	// true 1
+}
@@ -0,0 +1,212 @@
+// Package pool menyimpan proxy yang sudah lolos pengecekan beserta skornya.
+// Skor turun setiap kali pemakai melaporkan kegagalan dan naik lagi saat
+// proxy berhasil dipakai; proxy yang gagal berturut-turut dibuang dari pool.
+// Pool aman dipakai dari banyak goroutine.
+package pool
+
+import (
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"deep.go/check"
+	"deep.go/parse"
+)
+
+// Entry adalah satu proxy di pool.
+type Entry struct {
+	Proxy parse.Proxy
+	// Score antara 0 dan 1; lebih tinggi lebih baik.
+	Score     float64
+	LatencyMs float64
+	Country   string
+	// Anonymity adalah tingkat anonimitas menurut judge, lihat check.Anonymity.
+	Anonymity string
+	CheckedAt time.Time
+	// Failures adalah jumlah kegagalan berturut-turut sejak sukses terakhir.
+	Failures int
+}
+
+// Key mengidentifikasi entri di pool; lihat KeyOf.
+func (e Entry) Key() string { return KeyOf(e.Proxy) }
+
+// KeyOf mengembalikan key pool untuk proxy, yaitu bentuk parse.Proxy.Export,
+// sehingga pemakai yang hanya menyimpan alamat ekspor tetap bisa melapor.
+func KeyOf(proxy parse.Proxy) string { return proxy.Export() }
+
+// Score menghitung skor dari latency relatif terhadap timeout pengecekan:
+// 1 untuk proxy yang menjawab seketika, 0 untuk yang nyaris timeout.
+func Score(latencyMs float64, timeout time.Duration) float64 {
+	if timeout <= 0 {
+		return 0
+	}
+	return max(0, 1-latencyMs/float64(timeout.Milliseconds()))
+}
+
+// Options mengatur Pool. Nilai nol memakai default.
+type Options struct {
+	// Timeout pengecekan untuk menghitung Score dari latency. Default 10 detik.
+	Timeout time.Duration
+	// MaxFailures adalah jumlah kegagalan berturut-turut sebelum proxy
+	// dibuang. Default 3.
+	MaxFailures int
+}
+
+// Pool adalah kumpulan proxy hidup.
+type Pool struct {
+	opts    Options
+	mu      sync.RWMutex
+	entries map[string]*Entry
+}
+
+// New membuat pool kosong.
+func New(opts Options) *Pool {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = 3
+	}
+	return &Pool{opts: opts, entries: make(map[string]*Entry)}
+}
+
+// Add menambahkan entri; entri dengan Key yang sama diganti.
+func (p *Pool) Add(entries ...Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range entries {
+		p.entries[e.Key()] = &e
+	}
+}
+
+// AddResult memasukkan proxy yang valid menurut result dengan skor dari
+// latency-nya, atau membuangnya dari pool jika tidak valid.
+func (p *Pool) AddResult(proxy parse.Proxy, result check.Result) {
+	if !result.Valid {
+		p.Remove(KeyOf(proxy))
+		return
+	}
+	p.Add(Entry{
+		Proxy:     proxy,
+		Score:     Score(result.LatencyMs, p.opts.Timeout),
+		LatencyMs: result.LatencyMs,
+		Anonymity: result.Anonymity,
+		CheckedAt: result.CheckedAt,
+	})
+}
+
+// Replace mengganti seluruh isi pool. Map baru disiapkan di luar lock lalu
+// ditukar sekaligus sehingga pembaca tidak pernah melihat pool kosong.
+func (p *Pool) Replace(entries []Entry) {
+	m := make(map[string]*Entry, len(entries))
+	for _, e := range entries {
+		m[e.Key()] = &e
+	}
+	p.mu.Lock()
+	p.entries = m
+	p.mu.Unlock()
+}
+
+// Remove membuang entri dengan key dan melaporkan apakah entri itu ada.
+func (p *Pool) Remove(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.entries[key]
+	delete(p.entries, key)
+	return ok
+}
+
+// Get mengembalikan salinan entri dengan key.
+func (p *Pool) Get(key string) (Entry, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	e, ok := p.entries[key]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Len mengembalikan jumlah proxy di pool.
+func (p *Pool) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.entries)
+}
+
+// Entries mengembalikan salinan semua entri, skor tertinggi dulu. Entri
+// dengan skor sama diurutkan menurut alamat agar hasilnya stabil.
+func (p *Pool) Entries() []Entry {
+	p.mu.RLock()
+	out := make([]Entry, 0, len(p.entries))
+	for _, e := range p.entries {
+		out = append(out, *e)
+	}
+	p.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Key() < out[j].Key()
+	})
+	return out
+}
+
+// Best mengembalikan paling banyak n entri dengan skor tertinggi.
+func (p *Pool) Best(n int) []Entry {
+	entries := p.Entries()
+	return entries[:min(n, len(entries))]
+}
+
+// Random memilih satu entri secara acak, atau false jika pool kosong.
+func (p *Pool) Random() (Entry, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.entries) == 0 {
+		return Entry{}, false
+	}
+	i := rand.IntN(len(p.entries))
+	for _, e := range p.entries {
+		if i == 0 {
+			return *e, true
+		}
+		i--
+	}
+	return Entry{}, false
+}
+
+// ReportSuccess mencatat bahwa proxy berhasil dipakai dengan latency tersebut.
+// Skor bergerak ke skor latency itu sehingga satu request lambat tidak
+// langsung menjatuhkan proxy yang biasanya cepat.
+func (p *Pool) ReportSuccess(key string, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+	ms := float64(latency.Microseconds()) / 1000
+	e.Failures = 0
+	e.LatencyMs = ms
+	e.Score = 0.7*e.Score + 0.3*Score(ms, p.opts.Timeout)
+}
+
+// ReportFailure mencatat bahwa proxy gagal dipakai: skornya dibagi dua dan
+// setelah MaxFailures kegagalan berturut-turut proxy dibuang. Hasilnya
+// true jika proxy dibuang.
+func (p *Pool) ReportFailure(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.entries[key]
+	if !ok {
+		return false
+	}
+	e.Failures++
+	e.Score /= 2
+	if e.Failures >= p.opts.MaxFailures {
+		delete(p.entries, key)
+		return true
+	}
+	return false
+}
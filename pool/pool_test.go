@@ -0,0 +1,37 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestReplaceNeverEmpty(t *testing.T) {
+	p := New(Options{})
+	entries := testEntries(50)
+	p.Replace(entries)
+
+	// Pembaca yang berjalan bersamaan dengan Replace tidak boleh melihat
+	// pool kosong atau setengah terisi
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if n := p.Len(); n != len(entries) {
+				t.Errorf("Len = %d selama Replace, ingin %d", n, len(entries))
+				return
+			}
+		}
+	}()
+	for range 200 {
+		p.Replace(entries)
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,107 @@
+package pool
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// Strategy memilih satu proxy dari kandidat. Kandidat tidak pernah kosong
+// dan sudah terurut dari skor tertinggi, seperti hasil Entries.
+type Strategy interface {
+	Pick(candidates []Entry) Entry
+}
+
+// StrategyFunc mengubah fungsi biasa menjadi Strategy.
+type StrategyFunc func(candidates []Entry) Entry
+
+func (f StrategyFunc) Pick(candidates []Entry) Entry { return f(candidates) }
+
+// Random memilih kandidat secara acak dengan peluang yang sama.
+var Random Strategy = StrategyFunc(func(candidates []Entry) Entry {
+	return candidates[rand.IntN(len(candidates))]
+})
+
+// Best selalu memilih kandidat dengan skor tertinggi.
+var Best Strategy = StrategyFunc(func(candidates []Entry) Entry {
+	return candidates[0]
+})
+
+// Weighted memilih kandidat secara acak dengan peluang sebanding skornya,
+// sehingga proxy cepat lebih sering dipakai tanpa membebani satu proxy saja.
+var Weighted Strategy = StrategyFunc(func(candidates []Entry) Entry {
+	total := 0.0
+	for _, e := range candidates {
+		total += e.Score
+	}
+	if total <= 0 {
+		return Random.Pick(candidates)
+	}
+	n := rand.Float64() * total
+	for _, e := range candidates {
+		if n -= e.Score; n < 0 {
+			return e
+		}
+	}
+	return candidates[len(candidates)-1]
+})
+
+// RoundRobin bergiliran memakai kandidat. Urutannya mengikuti Entries
+// sehingga giliran bergeser jika isi pool berubah.
+type RoundRobin struct {
+	next atomic.Uint64
+}
+
+func (r *RoundRobin) Pick(candidates []Entry) Entry {
+	return candidates[(r.next.Add(1)-1)%uint64(len(candidates))]
+}
+
+// Strategies adalah nama strategi yang dikenali ParseStrategy.
+var Strategies = []string{"random", "weighted", "best", "round-robin"}
+
+// ParseStrategy membuat Strategy dari nama, misalnya nilai flag.
+func ParseStrategy(name string) (Strategy, error) {
+	switch name {
+	case "", "random":
+		return Random, nil
+	case "weighted":
+		return Weighted, nil
+	case "best":
+		return Best, nil
+	case "round-robin":
+		return &RoundRobin{}, nil
+	}
+	return nil, fmt.Errorf("strategi rotasi tidak dikenal: %q (pilihan: %v)", name, Strategies)
+}
+
+// Pick memilih proxy dengan strategy dari entri yang key-nya tidak ada di
+// exclude. Hasilnya false jika tidak ada kandidat.
+func (p *Pool) Pick(strategy Strategy, exclude map[string]bool) (Entry, bool) {
+	candidates := p.candidates(exclude)
+	if len(candidates) == 0 {
+		return Entry{}, false
+	}
+	if strategy == nil {
+		strategy = Random
+	}
+	return strategy.Pick(candidates), true
+}
+
+// PickSticky seperti Pick, tetapi proxy dipilih lewat affinity untuk key.
+func (p *Pool) PickSticky(affinity *Affinity, key string, exclude map[string]bool) (Entry, bool) {
+	candidates := p.candidates(exclude)
+	if len(candidates) == 0 {
+		return Entry{}, false
+	}
+	return affinity.Pick(key, candidates), true
+}
+
+func (p *Pool) candidates(exclude map[string]bool) []Entry {
+	var candidates []Entry
+	for _, e := range p.Entries() {
+		if !exclude[e.Key()] {
+			candidates = append(candidates, e)
+		}
+	}
+	return candidates
+}
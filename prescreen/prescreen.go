@@ -0,0 +1,135 @@
+// Package prescreen menjalankan tahap pertama pengecekan proxy: hanya membuka
+// koneksi TCP dengan timeout pendek dan paralelisme tinggi. Sebagian besar
+// proxy gratis yang mati hanyalah port yang tertutup, sehingga tahap ini
+// membuang mereka sebelum pengecekan HTTP yang mahal.
+package prescreen
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"deep.go/limiter"
+)
+
+// Config mengatur tahap pre-screen TCP.
+type Config struct {
+	// Timeout adalah batas waktu untuk satu percobaan connect.
+	Timeout time.Duration
+	// Limiter membatasi jumlah dial bersamaan. Jika nil, dibuat limiter
+	// adaptif dengan batas atas MaxConcurrency.
+	Limiter *limiter.Limiter
+	// MaxConcurrency dipakai jika Limiter nil.
+	MaxConcurrency int
+	// Retries adalah jumlah pengulangan jika dial gagal karena sumber daya
+	// lokal habis; kegagalan seperti itu tidak berarti proxy mati.
+	Retries int
+}
+
+// Stats adalah ringkasan hasil pre-screen.
+type Stats struct {
+	Total  int
+	Passed int
+	// Eliminated termasuk alamat yang tidak sempat diperiksa karena ctx
+	// berakhir.
+	Eliminated int
+	// Inconclusive adalah alamat yang tetap gagal karena kejenuhan lokal dan
+	// diteruskan ke tahap berikutnya daripada dianggap mati.
+	Inconclusive int
+}
+
+// Result adalah hasil pre-screen untuk satu alamat.
+type Result struct {
+	Passed bool
+	// Err adalah error dial terakhir; tetap terisi untuk alamat yang
+	// diteruskan karena hasilnya tidak meyakinkan. Alamat yang tidak sempat
+	// diperiksa berisi ctx.Err().
+	Err error
+}
+
+// Dial mencoba membuka koneksi TCP ke addr lalu langsung menutupnya.
+func Dial(ctx context.Context, addr string, timeout time.Duration) error {
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// dial adalah Dial; diganti dalam tes.
+var dial = Dial
+
+// Screen memeriksa semua alamat secara paralel dan mengembalikan hasil yang
+// sejajar dengan addrs. Alamat dengan Passed true lolos ke tahap HTTP.
+func Screen(ctx context.Context, addrs []string, cfg Config) ([]Result, Stats) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	if cfg.Retries <= 0 {
+		cfg.Retries = 2
+	}
+	lim := cfg.Limiter
+	if lim == nil {
+		lim = limiter.New(limiter.Config{Max: max(cfg.MaxConcurrency, 1)})
+	}
+
+	results := make([]Result, len(addrs))
+	stats := Stats{Total: len(addrs)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, addr := range addrs {
+		if err := lim.Acquire(ctx); err != nil {
+			// ctx berakhir: sisa alamat tidak diperiksa dan tidak boleh
+			// terbaca sebagai lolos
+			mu.Lock()
+			for j := i; j < len(addrs); j++ {
+				results[j].Err = err
+			}
+			stats.Eliminated += len(addrs) - i
+			mu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+
+			err := dial(ctx, addr, cfg.Timeout)
+			for try := 0; try < cfg.Retries && limiter.IsSaturation(err); try++ {
+				lim.Release(limiter.Saturated)
+				time.Sleep(time.Duration(try+1) * 200 * time.Millisecond)
+				if lim.Acquire(ctx) != nil {
+					// Slot tidak didapat lagi; anggap belum terbukti mati.
+					mu.Lock()
+					results[i] = Result{Passed: true, Err: err}
+					stats.Passed++
+					stats.Inconclusive++
+					mu.Unlock()
+					return
+				}
+				err = dial(ctx, addr, cfg.Timeout)
+			}
+			lim.Release(limiter.Classify(err))
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i].Err = err
+			switch {
+			case err == nil:
+				results[i].Passed = true
+				stats.Passed++
+			case limiter.IsSaturation(err):
+				results[i].Passed = true
+				stats.Passed++
+				stats.Inconclusive++
+			default:
+				stats.Eliminated++
+			}
+		}(i, addr)
+	}
+
+	wg.Wait()
+	return results, stats
+}
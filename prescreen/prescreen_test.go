@@ -0,0 +1,106 @@
+package prescreen
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"deep.go/limiter"
+)
+
+// fakeDial mengganti dial selama tes; fn menerima alamat dan nomor
+// percobaan untuk alamat itu (mulai dari 0).
+func fakeDial(t *testing.T, fn func(addr string, try int) error) {
+	t.Helper()
+	var mu sync.Mutex
+	tries := make(map[string]int)
+	dial = func(_ context.Context, addr string, _ time.Duration) error {
+		mu.Lock()
+		try := tries[addr]
+		tries[addr]++
+		mu.Unlock()
+		return fn(addr, try)
+	}
+	t.Cleanup(func() { dial = Dial })
+}
+
+func TestScreen(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	open := ln.Addr().String()
+	// Port yang baru dilepas hampir pasti tertutup
+	tmp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := tmp.Addr().String()
+	tmp.Close()
+
+	results, stats := Screen(context.Background(), []string{open, closed}, Config{Timeout: time.Second, MaxConcurrency: 2})
+	if !results[0].Passed || results[1].Passed || results[1].Err == nil {
+		t.Errorf("results = %+v", results)
+	}
+	if stats != (Stats{Total: 2, Passed: 1, Eliminated: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestScreenSaturationRetry(t *testing.T) {
+	emfile := &net.OpError{Op: "dial", Err: syscall.EMFILE}
+	fakeDial(t, func(addr string, try int) error {
+		switch {
+		case addr == "pulih:1" && try == 0:
+			return emfile
+		case addr == "jenuh:1":
+			return emfile
+		case addr == "mati:1":
+			return syscall.ECONNREFUSED
+		}
+		return nil
+	})
+
+	addrs := []string{"pulih:1", "jenuh:1", "mati:1"}
+	results, stats := Screen(context.Background(), addrs, Config{MaxConcurrency: 3, Retries: 1})
+	// Kejenuhan lokal dicoba ulang; yang tetap jenuh diteruskan, bukan dibuang
+	if !results[0].Passed || results[0].Err != nil {
+		t.Errorf("pulih = %+v, ingin lolos setelah dicoba ulang", results[0])
+	}
+	if !results[1].Passed || !limiter.IsSaturation(results[1].Err) {
+		t.Errorf("jenuh = %+v, ingin lolos tidak meyakinkan", results[1])
+	}
+	if results[2].Passed {
+		t.Errorf("mati = %+v, ingin dibuang", results[2])
+	}
+	if stats != (Stats{Total: 3, Passed: 2, Eliminated: 1, Inconclusive: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestScreenCanceled(t *testing.T) {
+	fakeDial(t, func(string, int) error { return nil })
+
+	// Slot satu-satunya dipegang sehingga Screen menunggu sampai ctx berakhir
+	lim := limiter.New(limiter.Config{Min: 1, Max: 1})
+	if err := lim.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	results, stats := Screen(ctx, []string{"a:1", "b:1"}, Config{Limiter: lim})
+	for i, r := range results {
+		if r.Passed || !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("results[%d] = %+v, ingin tidak lolos dengan error ctx", i, r)
+		}
+	}
+	if stats != (Stats{Total: 2, Eliminated: 2}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
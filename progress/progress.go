@@ -0,0 +1,305 @@
+// Package progress menampilkan kemajuan pengecekan proxy. Di terminal,
+// beberapa baris status digambar ulang di tempat: jumlah dicek, valid dan
+// invalid, status sumber, laju, ETA, alasan kegagalan teratas, dan
+// sparkline latency. Jika output bukan terminal, ringkasan satu baris
+// dicetak berkala agar log CI atau file tetap terbaca.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"deep.go/logging"
+)
+
+// Level mengatur seberapa banyak yang dicetak.
+type Level int
+
+const (
+	// Quiet hanya menyisakan ringkasan akhir dari pemanggil.
+	Quiet Level = iota
+	// Normal menampilkan progres.
+	Normal
+	// Verbose menampilkan progres; baris per proxy datang dari log level debug.
+	Verbose
+)
+
+// LevelFromFlags memetakan flag -quiet dan -verbose; quiet menang jika keduanya diset.
+func LevelFromFlags(quiet, verbose bool) Level {
+	switch {
+	case quiet:
+		return Quiet
+	case verbose:
+		return Verbose
+	}
+	return Normal
+}
+
+const (
+	ttyInterval  = 200 * time.Millisecond
+	lineInterval = 10 * time.Second
+	sparkSize    = 32
+	topReasons   = 3
+)
+
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// Tracker mengumpulkan hasil pengecekan dan menggambar progres. Semua
+// metode aman dipanggil dari banyak goroutine.
+type Tracker struct {
+	out   io.Writer
+	level Level
+	tty   bool
+
+	mu        sync.Mutex
+	total     int
+	checked   int
+	valid     int
+	start     time.Time
+	reasons   map[string]int
+	latencies []time.Duration
+	sources   []string
+	status    map[string]string
+	drawn     int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New membuat tracker yang menulis ke out. Tampilan interaktif hanya
+// dipakai jika out adalah terminal.
+func New(out io.Writer, level Level) *Tracker {
+	return &Tracker{
+		out:     out,
+		level:   level,
+		tty:     IsTerminal(out),
+		reasons: make(map[string]int),
+		status:  make(map[string]string),
+	}
+}
+
+// IsTerminal melaporkan apakah w adalah terminal yang mendukung ANSI.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Level mengembalikan level tracker.
+func (t *Tracker) Level() Level { return t.level }
+
+// AddTotal menambah jumlah proxy yang akan dicek, untuk input yang datang bertahap.
+func (t *Tracker) AddTotal(n int) {
+	t.mu.Lock()
+	t.total += n
+	t.mu.Unlock()
+}
+
+// Source mencatat status satu sumber untuk ditampilkan.
+func (t *Tracker) Source(name, status string) {
+	t.mu.Lock()
+	if _, ok := t.status[name]; !ok {
+		t.sources = append(t.sources, name)
+	}
+	t.status[name] = status
+	t.mu.Unlock()
+}
+
+// Done mencatat satu proxy yang selesai dicek. reason kosong untuk proxy valid.
+func (t *Tracker) Done(valid bool, reason string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checked++
+	if valid {
+		t.valid++
+		t.latencies = append(t.latencies, latency)
+		if len(t.latencies) > sparkSize {
+			t.latencies = t.latencies[len(t.latencies)-sparkSize:]
+		}
+	} else {
+		t.reasons[reason]++
+	}
+}
+
+// Write menulis baris log di atas tampilan status agar tidak saling
+// timpa, sehingga tracker bisa dipakai sebagai tujuan logger.
+func (t *Tracker) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clear()
+	n, err := t.out.Write(p)
+	if t.stop != nil && t.tty {
+		t.draw()
+	}
+	return n, err
+}
+
+// Start mulai menggambar progres sampai Stop dipanggil.
+func (t *Tracker) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+	if t.level == Quiet || t.stop != nil {
+		return
+	}
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+
+	interval := lineInterval
+	if t.tty {
+		interval = ttyInterval
+	}
+	go t.loop(interval)
+}
+
+func (t *Tracker) loop(interval time.Duration) {
+	defer close(t.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			if t.tty {
+				t.clear()
+				t.draw()
+			} else {
+				fmt.Fprintln(t.out, t.summaryLine())
+			}
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Stop menghentikan tampilan dan meninggalkan satu gambar terakhir.
+func (t *Tracker) Stop() {
+	t.mu.Lock()
+	stop := t.stop
+	t.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-t.done
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stop = nil
+	if t.tty {
+		t.clear()
+		t.draw()
+		t.drawn = 0
+	} else {
+		fmt.Fprintln(t.out, t.summaryLine())
+	}
+}
+
+// clear menghapus baris status yang terakhir digambar. mu harus dipegang.
+func (t *Tracker) clear() {
+	if t.drawn > 0 {
+		fmt.Fprintf(t.out, "\x1b[%dA\x1b[J", t.drawn)
+		t.drawn = 0
+	}
+}
+
+// draw menggambar baris status. mu harus dipegang.
+func (t *Tracker) draw() {
+	lines := []string{t.summaryLine()}
+	if len(t.sources) > 0 {
+		parts := make([]string, len(t.sources))
+		for i, name := range t.sources {
+			parts[i] = name + " " + t.status[name]
+		}
+		lines = append(lines, "📡 "+strings.Join(parts, " · "))
+	}
+	if reasons := t.topReasons(); reasons != "" {
+		lines = append(lines, "🔎 "+reasons)
+	}
+	if len(t.latencies) > 0 {
+		lines = append(lines, fmt.Sprintf("📈 %s median %s", sparkline(t.latencies), median(t.latencies)))
+	}
+	for _, line := range lines {
+		fmt.Fprintln(t.out, line)
+	}
+	t.drawn = len(lines)
+}
+
+// summaryLine menghasilkan baris utama: jumlah, laju, dan ETA. mu harus dipegang.
+func (t *Tracker) summaryLine() string {
+	elapsed := time.Since(t.start)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(t.checked) / elapsed.Seconds()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔍 %s %d", logging.T(logging.ProgressChecked), t.checked)
+	if t.total > 0 {
+		fmt.Fprintf(&b, "/%d (%.1f%%)", t.total, 100*float64(t.checked)/float64(t.total))
+	}
+	fmt.Fprintf(&b, "  ✅ %d  ❌ %d  ⚡ %.1f/s", t.valid, t.checked-t.valid, rate)
+	if remaining := t.total - t.checked; remaining > 0 && rate > 0 {
+		eta := time.Duration(float64(remaining) / rate * float64(time.Second))
+		fmt.Fprintf(&b, "  ⏳ ETA %s", eta.Round(time.Second))
+	}
+	return b.String()
+}
+
+func (t *Tracker) topReasons() string {
+	type entry struct {
+		reason string
+		count  int
+	}
+	entries := make([]entry, 0, len(t.reasons))
+	for r, n := range t.reasons {
+		entries = append(entries, entry{r, n})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].reason < entries[j].reason
+	})
+	if len(entries) > topReasons {
+		entries = entries[:topReasons]
+	}
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = fmt.Sprintf("%s %d", e.reason, e.count)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// sparkline menggambar latency terbaru relatif terhadap yang paling lambat.
+func sparkline(latencies []time.Duration) string {
+	var highest time.Duration
+	for _, l := range latencies {
+		highest = max(highest, l)
+	}
+	var b strings.Builder
+	for _, l := range latencies {
+		i := 0
+		if highest > 0 {
+			i = int(float64(l) / float64(highest) * float64(len(sparkChars)-1))
+		}
+		b.WriteRune(sparkChars[i])
+	}
+	return b.String()
+}
+
+func median(latencies []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2].Round(time.Millisecond)
+}
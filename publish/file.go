@@ -0,0 +1,151 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Manifest menjelaskan file pool yang sedang berlaku. Konsumen membaca
+// manifest lebih dulu lalu file yang dirujuknya, dan bisa memastikan
+// isinya utuh lewat SHA256.
+type Manifest struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	File        string    `json:"file"`
+	Count       int       `json:"count"`
+	SHA256      string    `json:"sha256"`
+	Proxies     []Entry   `json:"proxies"`
+}
+
+// FileDrop menulis pool ke Dir sebagai file data berversi
+// <Base>-<unixnano>.txt (satu proxy per baris, skor tertinggi dulu),
+// salinan <Base>.txt untuk pembaca sederhana, dan manifest <Base>.json yang
+// merujuk file berversi. Setiap file ditulis ke file sementara lalu
+// di-rename, dan manifest diganti terakhir. File berversi tidak pernah
+// diubah setelah ditulis, sehingga konsumen yang membaca manifest lalu
+// file yang dirujuknya selalu mendapat pasangan yang cocok.
+type FileDrop struct {
+	Dir  string
+	Base string
+	// Keep adalah jumlah file berversi terbaru yang disimpan; sisanya
+	// dihapus. File lama tetap ada sebentar untuk konsumen yang baru saja
+	// membaca manifest sebelumnya. Default 3.
+	Keep int
+}
+
+func (f *FileDrop) Name() string { return "file " + f.manifestPath() }
+
+func (f *FileDrop) listPath() string     { return filepath.Join(f.Dir, f.Base+".txt") }
+func (f *FileDrop) manifestPath() string { return filepath.Join(f.Dir, f.Base+".json") }
+
+func (f *FileDrop) versionPath(at time.Time) string {
+	return filepath.Join(f.Dir, fmt.Sprintf("%s-%d.txt", f.Base, at.UnixNano()))
+}
+
+func (f *FileDrop) Publish(_ context.Context, entries []Entry, at time.Time) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	var list bytes.Buffer
+	for _, e := range entries {
+		list.WriteString(e.Addr)
+		list.WriteByte('\n')
+	}
+	sum := sha256.Sum256(list.Bytes())
+	version := f.versionPath(at)
+	if err := writeAtomic(version, list.Bytes()); err != nil {
+		return err
+	}
+	if err := writeAtomic(f.listPath(), list.Bytes()); err != nil {
+		return err
+	}
+
+	manifest := Manifest{
+		GeneratedAt: at,
+		File:        filepath.Base(version),
+		Count:       len(entries),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Proxies:     entries,
+	}
+	if manifest.Proxies == nil {
+		manifest.Proxies = []Entry{}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(f.manifestPath(), append(data, '\n')); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// prune menghapus file berversi selain Keep yang terbaru.
+func (f *FileDrop) prune() error {
+	keep := f.Keep
+	if keep <= 0 {
+		keep = 3
+	}
+	entries, err := os.ReadDir(f.Dir)
+	if err != nil {
+		return err
+	}
+	type version struct {
+		name string
+		ns   int64
+	}
+	var versions []version
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), f.Base+"-")
+		if !ok {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, ".txt")
+		if !ok {
+			continue
+		}
+		if ns, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+			versions = append(versions, version{e.Name(), ns})
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ns > versions[j].ns })
+	for _, v := range versions[min(keep, len(versions)):] {
+		if err := os.Remove(filepath.Join(f.Dir, v.name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAtomic menulis data ke file sementara, melakukan fsync, lalu rename ke path.
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
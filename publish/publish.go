@@ -0,0 +1,43 @@
+// Package publish menerbitkan pool proxy valid untuk layanan lain tanpa
+// perlu memanggil proses ini: ke sorted set Redis yang diurutkan menurut
+// skor, atau sebagai file teks plus manifest JSON yang diganti secara atomik.
+package publish
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Entry adalah satu proxy yang diterbitkan.
+type Entry struct {
+	// Addr adalah proxy dalam bentuk ekspor: ip:port atau scheme://ip:port.
+	Addr      string   `json:"addr"`
+	Score     float64  `json:"score"`
+	LatencyMs float64  `json:"latency_ms,omitempty"`
+	Country   string   `json:"country,omitempty"`
+	Sources   []string `json:"sources,omitempty"`
+}
+
+// Sink adalah tujuan penerbitan. Publish mengganti seluruh isi pool
+// sebelumnya sehingga konsumen tidak pernah melihat pool setengah jadi.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, entries []Entry, at time.Time) error
+}
+
+// All menerbitkan entries ke semua sink, terurut dari skor tertinggi. Sink
+// yang gagal tidak menghentikan sink lain dan semua error digabung.
+func All(ctx context.Context, sinks []Sink, entries []Entry) error {
+	entries = append([]Entry(nil), entries...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Score > entries[j].Score })
+	at := time.Now()
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, entries, at); err != nil {
+			errs = append(errs, errors.New(sink.Name()+": "+err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
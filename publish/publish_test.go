@@ -0,0 +1,278 @@
+package publish
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis adalah server RESP minimal yang cukup untuk perintah Redis sink.
+type fakeRedis struct {
+	mu       sync.Mutex
+	password string
+	zsets    map[string]map[string]float64
+	ttl      map[string]time.Duration
+	commands []string
+	// dropAt memutus koneksi saat perintah ini diterima, sebelum dijalankan
+	dropAt string
+}
+
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeRedis{password: password, zsets: map[string]map[string]float64{}, ttl: map[string]time.Duration{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	var queued [][]string
+	inTx := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		cmd := strings.ToUpper(args[0])
+		f.commands = append(f.commands, cmd)
+		if cmd == f.dropAt {
+			f.mu.Unlock()
+			return
+		}
+		var reply string
+		switch {
+		case cmd == "AUTH":
+			reply = "+OK"
+			if args[len(args)-1] == f.password {
+				authed = true
+			} else {
+				reply = "-WRONGPASS invalid password"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required."
+		case cmd == "MULTI":
+			inTx, reply = true, "+OK"
+		case cmd == "EXEC":
+			reply = fmt.Sprintf("*%d", len(queued))
+			for _, q := range queued {
+				reply += "\r\n" + f.exec(q)
+			}
+			inTx, queued = false, nil
+		case inTx:
+			queued = append(queued, args)
+			reply = "+QUEUED"
+		default:
+			reply = f.exec(args)
+		}
+		f.mu.Unlock()
+		io.WriteString(conn, reply+"\r\n")
+	}
+}
+
+// exec menjalankan satu perintah data dan mengembalikan balasannya.
+func (f *fakeRedis) exec(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "DEL":
+		delete(f.zsets, args[1])
+		delete(f.ttl, args[1])
+		return ":1"
+	case "ZADD":
+		set := f.zsets[args[1]]
+		if set == nil {
+			set = map[string]float64{}
+			f.zsets[args[1]] = set
+		}
+		for i := 2; i+1 < len(args); i += 2 {
+			score, _ := strconv.ParseFloat(args[i], 64)
+			set[args[i+1]] = score
+		}
+		return fmt.Sprintf(":%d", (len(args)-2)/2)
+	case "RENAME":
+		if _, ok := f.zsets[args[1]]; !ok {
+			return "-ERR no such key"
+		}
+		// RENAME membawa TTL key sumber, termasuk tanpa TTL
+		f.zsets[args[2]], f.ttl[args[2]] = f.zsets[args[1]], f.ttl[args[1]]
+		delete(f.zsets, args[1])
+		delete(f.ttl, args[1])
+		return "+OK"
+	case "PEXPIRE":
+		if _, ok := f.zsets[args[1]]; !ok {
+			return ":0"
+		}
+		ms, _ := strconv.Atoi(args[2])
+		f.ttl[args[1]] = time.Duration(ms) * time.Millisecond
+		return ":1"
+	case "SELECT":
+		return "+OK"
+	}
+	return "-ERR unknown command " + args[0]
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimRight(arg, "\r\n")
+	}
+	return args, nil
+}
+
+var testEntries = []Entry{
+	{Addr: "1.1.1.1:80", Score: 0.5},
+	{Addr: "socks5://2.2.2.2:1080", Score: 0.9},
+}
+
+func TestRedisPublish(t *testing.T) {
+	f, addr := startFakeRedis(t, "rahasia")
+	sink, err := ParseRedisURL("redis://:rahasia@" + addr + "/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Key, sink.TTL = "pool", time.Hour
+	if err := All(context.Background(), []Sink{sink}, testEntries); err != nil {
+		t.Fatal(err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	want := map[string]float64{"1.1.1.1:80": 0.5, "socks5://2.2.2.2:1080": 0.9}
+	if got := f.zsets["pool"]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("pool = %v, want %v", got, want)
+	}
+	if len(f.zsets) != 1 {
+		t.Errorf("key sementara tertinggal: %v", f.zsets)
+	}
+	if f.ttl["pool"] != time.Hour {
+		t.Errorf("ttl = %v", f.ttl["pool"])
+	}
+	if wantCmds := []string{"AUTH", "SELECT", "MULTI", "DEL", "ZADD", "PEXPIRE", "RENAME", "EXEC"}; !slices.Equal(f.commands, wantCmds) {
+		t.Errorf("commands = %v, want %v", f.commands, wantCmds)
+	}
+}
+
+func TestRedisDropped(t *testing.T) {
+	f, addr := startFakeRedis(t, "")
+	f.dropAt = "EXEC"
+	sink := &Redis{Addr: addr, Key: "pool", TTL: time.Hour}
+	if err := sink.Publish(context.Background(), testEntries, time.Now()); err == nil {
+		t.Fatal("koneksi putus tidak dilaporkan")
+	}
+
+	// Koneksi putus sebelum EXEC: tidak ada key sementara yang tertinggal
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.zsets) != 0 || len(f.ttl) != 0 {
+		t.Errorf("key tertinggal: %v %v", f.zsets, f.ttl)
+	}
+}
+
+func TestRedisError(t *testing.T) {
+	_, addr := startFakeRedis(t, "rahasia")
+	sink := &Redis{Addr: addr, Password: "salah", Key: "pool"}
+	err := sink.Publish(context.Background(), testEntries, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("err = %v, want WRONGPASS", err)
+	}
+}
+
+func TestFileDrop(t *testing.T) {
+	dir := t.TempDir()
+	sink := &FileDrop{Dir: dir, Base: "pool", Keep: 2}
+	if err := All(context.Background(), []Sink{sink}, testEntries); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := os.ReadFile(filepath.Join(dir, "pool.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "socks5://2.2.2.2:1080\n1.1.1.1:80\n"; string(list) != want {
+		t.Errorf("pool.txt = %q, want %q", list, want)
+	}
+
+	readManifest := func() (Manifest, []byte) {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "pool.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatal(err)
+		}
+		versioned, err := os.ReadFile(filepath.Join(dir, m.File))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m, versioned
+	}
+	m, versioned := readManifest()
+	sum := sha256.Sum256(versioned)
+	if !strings.HasPrefix(m.File, "pool-") || string(versioned) != string(list) || m.Count != 2 || m.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	// Manifest selalu merujuk file berversi yang cocok; hanya Keep versi
+	// terbaru yang disimpan
+	for i := range 3 {
+		at := time.Now().Add(time.Duration(i+1) * time.Second)
+		if err := sink.Publish(context.Background(), testEntries[:1], at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, versioned = readManifest()
+	sum = sha256.Sum256(versioned)
+	if m.Count != 1 || m.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("manifest = %+v tidak cocok dengan %s", m, m.File)
+	}
+
+	// Tidak ada file sementara yang tertinggal
+	files, _ := os.ReadDir(dir)
+	if len(files) != 4 {
+		t.Errorf("isi direktori = %v, ingin pool.txt, pool.json dan 2 versi", files)
+	}
+}
@@ -0,0 +1,173 @@
+package publish
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zaddBatch adalah jumlah anggota terbanyak per perintah ZADD.
+const zaddBatch = 500
+
+// Redis menerbitkan pool ke sorted set dengan skor proxy sebagai score,
+// sehingga konsumen cukup memanggil ZREVRANGE untuk proxy terbaik. Isi
+// baru ditulis ke key sementara lalu di-RENAME ke Key dalam satu MULTI/EXEC;
+// TTL ikut terbawa ke Key agar pool yang tidak lagi diperbarui hilang sendiri.
+// Protokol RESP ditulis langsung tanpa dependensi klien Redis.
+type Redis struct {
+	// Addr adalah host:port server Redis atau yang kompatibel.
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	Key      string
+	// TTL pada Key; nol berarti tanpa kedaluwarsa.
+	TTL time.Duration
+	// Timeout untuk dial; batas keseluruhan diambil dari context.
+	Timeout time.Duration
+}
+
+// ParseRedisURL membaca redis://[user:password@]host[:port][/db].
+func ParseRedisURL(raw string) (*Redis, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("skema URL Redis tidak dikenal: %q", u.Scheme)
+	}
+	r := &Redis{Addr: u.Host}
+	if u.Port() == "" {
+		r.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		r.Username = u.User.Username()
+		r.Password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if r.DB, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("nomor database Redis tidak valid: %q", db)
+		}
+	}
+	return r, nil
+}
+
+func (r *Redis) Name() string { return "redis " + r.Addr + "/" + r.Key }
+
+func (r *Redis) Publish(ctx context.Context, entries []Entry, at time.Time) error {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, "tcp", r.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Semua perintah dikirim sekaligus (pipelining), lalu semua balasan dibaca
+	var cmds [][]string
+	if r.Password != "" {
+		if r.Username != "" {
+			cmds = append(cmds, []string{"AUTH", r.Username, r.Password})
+		} else {
+			cmds = append(cmds, []string{"AUTH", r.Password})
+		}
+	}
+	if r.DB != 0 {
+		cmds = append(cmds, []string{"SELECT", strconv.Itoa(r.DB)})
+	}
+
+	if len(entries) == 0 {
+		// Pool kosong diterbitkan sebagai key yang tidak ada
+		cmds = append(cmds, []string{"DEL", r.Key})
+	} else {
+		// Key sementara diisi dan di-RENAME dalam MULTI/EXEC: jika koneksi
+		// putus sebelum EXEC tidak ada perintah yang dijalankan, sehingga
+		// tidak ada key sementara yatim. TTL dipasang sebelum RENAME karena
+		// RENAME ikut membawa TTL ke key tujuan
+		tmp := fmt.Sprintf("%s:tmp:%d", r.Key, at.UnixNano())
+		cmds = append(cmds, []string{"MULTI"}, []string{"DEL", tmp})
+		for start := 0; start < len(entries); start += zaddBatch {
+			cmd := []string{"ZADD", tmp}
+			for _, e := range entries[start:min(start+zaddBatch, len(entries))] {
+				cmd = append(cmd, strconv.FormatFloat(e.Score, 'f', -1, 64), e.Addr)
+			}
+			cmds = append(cmds, cmd)
+		}
+		if r.TTL > 0 {
+			cmds = append(cmds, []string{"PEXPIRE", tmp, strconv.FormatInt(r.TTL.Milliseconds(), 10)})
+		}
+		cmds = append(cmds, []string{"RENAME", tmp, r.Key}, []string{"EXEC"})
+	}
+
+	w := bufio.NewWriter(conn)
+	for _, cmd := range cmds {
+		writeCommand(w, cmd)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	rd := bufio.NewReader(conn)
+	for _, cmd := range cmds {
+		if err := readReply(rd); err != nil {
+			return fmt.Errorf("%s: %w", cmd[0], err)
+		}
+	}
+	return nil
+}
+
+// writeCommand menulis perintah sebagai array bulk string RESP.
+func writeCommand(w *bufio.Writer, args []string) {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(a), a)
+	}
+}
+
+// readReply membaca satu balasan RESP dan mengembalikan error untuk balasan "-ERR".
+func readReply(r *bufio.Reader) error {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return fmt.Errorf("balasan RESP kosong")
+	}
+	switch line[0] {
+	case '+', ':':
+		return nil
+	case '-':
+		return fmt.Errorf("redis: %s", line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return err
+		}
+		_, err = io.CopyN(io.Discard, r, int64(n)+2)
+		return err
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := readReply(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("balasan RESP tidak dikenal: %q", line)
+}
@@ -0,0 +1,302 @@
+// Package ratelimit membatasi laju request dengan token bucket per proxy
+// upstream, per host tujuan (termasuk URL tes) dan global. Tanpa batas ini
+// ratusan worker bisa menghantam satu proxy gratis sampai mati, atau
+// mengirim terlalu banyak pengecekan ke httpbin.org sampai kita diblokir.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cakupan batas, dipakai sebagai label metrik waktu tunggu
+const (
+	ScopeGlobal = "global"
+	ScopeProxy  = "proxy"
+	ScopeHost   = "host"
+)
+
+// Rate adalah batas satu token bucket. PerSecond nol berarti tanpa batas.
+type Rate struct {
+	// PerSecond adalah jumlah request per detik dalam jangka panjang.
+	PerSecond float64 `json:"per_second"`
+	// Burst adalah jumlah request yang boleh lewat sekaligus. Default
+	// PerSecond dibulatkan ke atas, minimal 1.
+	Burst int `json:"burst,omitempty"`
+}
+
+func (r Rate) unlimited() bool { return r.PerSecond <= 0 }
+
+func (r Rate) burst() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return max(1, float64(int(r.PerSecond+0.999)))
+}
+
+// Config mengatur Limiter. Nilai nol berarti tanpa batas pada cakupan itu.
+type Config struct {
+	// Global membatasi semua request bersama-sama.
+	Global Rate `json:"global"`
+	// PerProxy membatasi request lewat satu proxy upstream.
+	PerProxy Rate `json:"per_proxy"`
+	// PerHost membatasi request ke satu host tujuan.
+	PerHost Rate `json:"per_host"`
+	// Hosts mengganti PerHost untuk host tertentu, misalnya batas yang
+	// lebih longgar untuk judge milik sendiri.
+	Hosts map[string]Rate `json:"hosts,omitempty"`
+
+	// OnWait dipanggil setiap kali request harus menunggu, misalnya untuk metrik.
+	OnWait func(scope string, waited time.Duration) `json:"-"`
+}
+
+// LoadConfig membaca Config dari file JSON, misalnya:
+//
+//	{
+//	  "global":    {"per_second": 200},
+//	  "per_proxy": {"per_second": 2, "burst": 4},
+//	  "per_host":  {"per_second": 20},
+//	  "hosts":     {"httpbin.org": {"per_second": 5}}
+//	}
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate memastikan tidak ada batas negatif.
+func (c Config) Validate() error {
+	check := func(name string, r Rate) error {
+		if r.PerSecond < 0 || r.Burst < 0 {
+			return fmt.Errorf("batas %s tidak boleh negatif", name)
+		}
+		return nil
+	}
+	if err := check(ScopeGlobal, c.Global); err != nil {
+		return err
+	}
+	if err := check(ScopeProxy, c.PerProxy); err != nil {
+		return err
+	}
+	if err := check(ScopeHost, c.PerHost); err != nil {
+		return err
+	}
+	for host, r := range c.Hosts {
+		if err := check(host, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Limiter menyimpan token bucket per kunci. Limiter nil tidak membatasi
+// apa pun sehingga pemakai tidak perlu memeriksa apakah batas dipasang.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	global    *bucket
+	proxies   map[string]*bucket
+	hosts     map[string]*bucket
+	lastSweep time.Time
+}
+
+// New membuat Limiter dari cfg.
+func New(cfg Config) *Limiter {
+	hosts := make(map[string]Rate, len(cfg.Hosts))
+	for host, r := range cfg.Hosts {
+		hosts[normalizeHost(host)] = r
+	}
+	cfg.Hosts = hosts
+	l := &Limiter{
+		cfg:     cfg,
+		now:     time.Now,
+		proxies: make(map[string]*bucket),
+		hosts:   make(map[string]*bucket),
+	}
+	if !cfg.Global.unlimited() {
+		l.global = newBucket(cfg.Global, l.now())
+	}
+	return l
+}
+
+// Wait menunggu sampai request lewat proxy ke host boleh dikirim menurut
+// batas per proxy, per host dan global. proxy atau host kosong melewati
+// batas cakupan itu. Wait mengembalikan total waktu tunggu, yang sebaiknya
+// tidak dihitung sebagai latency proxy, atau error jika ctx berakhir.
+func (l *Limiter) Wait(ctx context.Context, proxy, host string) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	var total time.Duration
+	var reserved []*bucket
+	for _, step := range []struct {
+		scope string
+		key   string
+	}{
+		{ScopeProxy, proxy},
+		{ScopeHost, normalizeHost(host)},
+		{ScopeGlobal, ScopeGlobal},
+	} {
+		if step.key == "" {
+			continue
+		}
+		b := l.bucket(step.scope, step.key)
+		if b == nil {
+			continue
+		}
+		waited, err := l.wait(ctx, b)
+		total += waited
+		if waited > 0 && l.cfg.OnWait != nil {
+			l.cfg.OnWait(step.scope, waited)
+		}
+		if err != nil {
+			// Request tidak jadi dikirim; token cakupan sebelumnya dikembalikan
+			for _, b := range reserved {
+				b.cancel()
+			}
+			return total, err
+		}
+		reserved = append(reserved, b)
+	}
+	return total, nil
+}
+
+// bucket mengembalikan bucket untuk key pada scope, atau nil jika scope
+// tidak dibatasi.
+func (l *Limiter) bucket(scope, key string) *bucket {
+	if scope == ScopeGlobal {
+		return l.global
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	buckets, rate := l.proxies, l.cfg.PerProxy
+	if scope == ScopeHost {
+		buckets, rate = l.hosts, l.cfg.PerHost
+		if r, ok := l.cfg.Hosts[key]; ok {
+			rate = r
+		}
+	}
+	if rate.unlimited() {
+		return nil
+	}
+	b, ok := buckets[key]
+	if !ok {
+		b = newBucket(rate, now)
+		buckets[key] = b
+	}
+	return b
+}
+
+func (l *Limiter) wait(ctx context.Context, b *bucket) (time.Duration, error) {
+	delay := b.reserve(l.now())
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	start := time.Now()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		// Token dikembalikan agar request yang batal tidak menahan yang lain
+		b.cancel()
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// sweep membuang bucket yang sudah penuh lagi paling sering sekali per
+// menit; bucket penuh sama dengan bucket baru sehingga aman dibuang. Tanpa
+// ini gateway yang melayani banyak host menumpuk bucket tanpa batas.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for _, buckets := range []map[string]*bucket{l.proxies, l.hosts} {
+		for key, b := range buckets {
+			if b.full(now) {
+				delete(buckets, key)
+			}
+		}
+	}
+}
+
+// normalizeHost membuang port dan menyamakan huruf agar "HTTPBIN.org:80"
+// dan "httpbin.org" berbagi bucket.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// bucket adalah token bucket yang boleh berutang: reserve selalu mengambil
+// satu token dan mengembalikan berapa lama pemanggil harus menunggu sampai
+// token itu benar-benar tersedia. Dengan begitu antrean dilayani berurutan
+// tanpa goroutine pengisi.
+type bucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(r Rate, now time.Time) *bucket {
+	return &bucket{rate: r.PerSecond, burst: r.burst(), tokens: r.burst(), last: now}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// reserve mengambil satu token dan mengembalikan waktu tunggunya.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel mengembalikan token dari reserve yang dibatalkan.
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+func (b *bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	return b.tokens >= b.burst
+}
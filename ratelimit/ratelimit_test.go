@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClock menggantikan time.Now agar isi ulang token bisa diatur.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(cfg Config) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := New(cfg)
+	l.now = clock.now
+	if l.global != nil {
+		l.global.last = clock.t
+	}
+	return l, clock
+}
+
+func TestBucketReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newBucket(Rate{PerSecond: 2, Burst: 2}, now)
+
+	// Burst lewat tanpa menunggu, sisanya antre 0.5 detik per token
+	for i, want := range []time.Duration{0, 0, 500 * time.Millisecond, time.Second} {
+		if got := b.reserve(now); got != want {
+			t.Fatalf("reserve #%d = %v, ingin %v", i, got, want)
+		}
+	}
+	// Setelah 1 detik utang 2 token baru lunas, bucket penuh lagi setelah 2 detik
+	now = now.Add(time.Second)
+	if b.full(now) {
+		t.Fatal("bucket penuh padahal baru lunas")
+	}
+	now = now.Add(time.Second)
+	if !b.full(now) {
+		t.Fatal("bucket belum penuh setelah 2 detik")
+	}
+}
+
+func TestLimiterScopes(t *testing.T) {
+	var waits []string
+	l, _ := newTestLimiter(Config{
+		PerProxy: Rate{PerSecond: 1},
+		PerHost:  Rate{PerSecond: 1000},
+		Hosts:    map[string]Rate{"HTTPBIN.org": {PerSecond: 1}},
+		OnWait: func(scope string, _ time.Duration) {
+			waits = append(waits, scope)
+		},
+	})
+	// Dengan jam palsu yang tidak bergerak, request kedua lewat proxy yang
+	// sama atau ke httpbin.org harus menunggu; yang lain tidak
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Wait(ctx, "a:1", "httpbin.org:80"); err != nil {
+		t.Fatalf("request pertama tertahan: %v", err)
+	}
+	if _, err := l.Wait(ctx, "b:1", "example.com"); err != nil {
+		t.Fatalf("proxy dan host lain tertahan: %v", err)
+	}
+	if _, err := l.Wait(ctx, "a:1", "example.com"); err == nil {
+		t.Fatal("proxy a:1 tidak dibatasi")
+	}
+	if _, err := l.Wait(ctx, "c:1", "httpbin.org"); err == nil {
+		t.Fatal("override host httpbin.org tidak dipakai")
+	}
+	if len(waits) != 2 || waits[0] != ScopeProxy || waits[1] != ScopeHost {
+		t.Fatalf("OnWait = %v, ingin [proxy host]", waits)
+	}
+	// Token proxy c:1 dari request yang batal di batas host dikembalikan
+	if d, err := l.Wait(ctx, "c:1", "example.com"); d != 0 || err != nil {
+		t.Fatalf("token c:1 tidak dikembalikan: %v %v", d, err)
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := New(Config{Global: Rate{PerSecond: 50, Burst: 1}})
+	start := time.Now()
+	var waited time.Duration
+	for range 3 {
+		d, err := l.Wait(context.Background(), "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		waited += d
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond || waited < 30*time.Millisecond {
+		t.Fatalf("3 request pada 50/detik selesai dalam %v (tunggu %v)", elapsed, waited)
+	}
+
+	var nilLimiter *Limiter
+	if d, err := nilLimiter.Wait(context.Background(), "a:1", "x"); d != 0 || err != nil {
+		t.Fatalf("Limiter nil membatasi: %v %v", d, err)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l, clock := newTestLimiter(Config{PerHost: Rate{PerSecond: 10}})
+	for _, host := range []string{"a.example", "b.example"} {
+		l.Wait(context.Background(), "", host)
+	}
+	clock.t = clock.t.Add(2 * time.Minute)
+	l.Wait(context.Background(), "", "c.example")
+	if len(l.hosts) != 1 {
+		t.Fatalf("bucket host tersisa %d, ingin 1", len(l.hosts))
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	os.WriteFile(path, []byte(`{"per_proxy": {"per_second": 2, "burst": 4}, "hosts": {"httpbin.org": {"per_second": 5}}}`), 0o644)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PerProxy.PerSecond != 2 || cfg.PerProxy.Burst != 4 || cfg.Hosts["httpbin.org"].PerSecond != 5 || !cfg.Global.unlimited() {
+		t.Fatalf("config terbaca salah: %+v", cfg)
+	}
+
+	os.WriteFile(path, []byte(`{"per_host": {"per_second": -1}}`), 0o644)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("batas negatif diterima")
+	}
+}
@@ -0,0 +1,230 @@
+// Package reason mengklasifikasikan kegagalan pengecekan proxy ke dalam
+// alasan yang bisa dihitung, sehingga ringkasan hasil bisa membedakan sumber
+// proxy yang buruk dengan masalah jaringan di sisi kita.
+package reason
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Reason adalah alasan sebuah pengecekan gagal.
+type Reason string
+
+const (
+	None           Reason = ""
+	DNS            Reason = "dns"
+	ConnRefused    Reason = "connection_refused"
+	ConnectTimeout Reason = "connect_timeout"
+	ReadTimeout    Reason = "read_timeout"
+	TLS            Reason = "tls_error"
+	AuthRequired   Reason = "auth_required"
+	Blocked        Reason = "blocked"
+	Captcha        Reason = "captcha"
+	WrongStatus    Reason = "wrong_status"
+	EmptyBody      Reason = "empty_body"
+	TamperedBody   Reason = "tampered_body"
+	Other          Reason = "other"
+)
+
+// All berisi semua alasan kegagalan dalam urutan tampilan.
+var All = []Reason{
+	DNS, ConnRefused, ConnectTimeout, ReadTimeout, TLS, AuthRequired,
+	Blocked, Captcha, WrongStatus, EmptyBody, TamperedBody, Other,
+}
+
+// Error membungkus error pengecekan beserta alasannya.
+type Error struct {
+	Reason Reason
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Reason)
+	}
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Errorf membuat *Error dengan alasan r dan pesan yang diformat.
+func Errorf(r Reason, format string, args ...any) error {
+	return &Error{Reason: r, Err: fmt.Errorf(format, args...)}
+}
+
+// Of mengembalikan alasan untuk err. Jika err sudah membawa *Error, alasan
+// itu yang dipakai; jika tidak, alasan ditebak dari jenis error jaringan.
+func Of(err error) Reason {
+	if err == nil {
+		return None
+	}
+	var re *Error
+	if errors.As(err, &re) {
+		return re.Reason
+	}
+	return FromError(err)
+}
+
+// FromError mengklasifikasikan error jaringan atau transport.
+func FromError(err error) Reason {
+	if err == nil {
+		return None
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return DNS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ConnRefused
+	}
+	if isTLS(err) {
+		return TLS
+	}
+	if isTimeout(err) {
+		// Timeout saat membuka koneksi ke proxy dibedakan dari timeout saat
+		// menunggu respons melalui proxy.
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect") {
+			return ConnectTimeout
+		}
+		return ReadTimeout
+	}
+	if strings.Contains(err.Error(), "Proxy Authentication Required") {
+		return AuthRequired
+	}
+	return Other
+}
+
+// FromStatus mengklasifikasikan status HTTP yang tidak diharapkan.
+func FromStatus(code int) Reason {
+	switch code {
+	case 407:
+		return AuthRequired
+	case 403, 429, 451:
+		return Blocked
+	default:
+		return WrongStatus
+	}
+}
+
+var captchaMarkers = [][]byte{
+	[]byte("captcha"),
+	[]byte("cf-chl"),
+	[]byte("challenge-platform"),
+	[]byte("attention required"),
+	[]byte("are you a robot"),
+}
+
+// IsCaptcha melaporkan apakah body terlihat seperti halaman captcha atau
+// challenge anti-bot.
+func IsCaptcha(body []byte) bool {
+	lower := bytes.ToLower(body)
+	for _, m := range captchaMarkers {
+		if bytes.Contains(lower, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromBody mengklasifikasikan body respons 200. valid dipanggil untuk
+// memastikan isi body sesuai yang diharapkan dari target; jika nil, body
+// apa pun yang tidak kosong dianggap benar.
+func FromBody(body []byte, valid func([]byte) bool) Reason {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return EmptyBody
+	}
+	if IsCaptcha(body) {
+		return Captcha
+	}
+	if valid != nil && !valid(body) {
+		return TamperedBody
+	}
+	return None
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+func isTLS(err error) bool {
+	var (
+		recErr    tls.RecordHeaderError
+		alertErr  tls.AlertError
+		verifyErr *tls.CertificateVerificationError
+		authErr   x509.UnknownAuthorityError
+		hostErr   x509.HostnameError
+		certErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &recErr), errors.As(err, &alertErr), errors.As(err, &verifyErr),
+		errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &certErr):
+		return true
+	}
+	return strings.Contains(err.Error(), "tls: ")
+}
+
+// Counter menghitung jumlah kegagalan per alasan dan aman dipakai bersamaan.
+type Counter struct {
+	mu     sync.Mutex
+	counts map[Reason]int
+}
+
+// Add menambah hitungan untuk r. Alasan kosong diabaikan.
+func (c *Counter) Add(r Reason) {
+	if r == None {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[Reason]int)
+	}
+	c.counts[r]++
+}
+
+// Count mengembalikan jumlah kegagalan untuk r.
+func (c *Counter) Count(r Reason) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[r]
+}
+
+// Entry adalah satu baris ringkasan hitungan.
+type Entry struct {
+	Reason Reason
+	Count  int
+}
+
+// Sorted mengembalikan hitungan terurut dari yang terbanyak.
+func (c *Counter) Sorted() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, 0, len(c.counts))
+	for r, n := range c.counts {
+		entries = append(entries, Entry{r, n})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Reason < entries[j].Reason
+	})
+	return entries
+}
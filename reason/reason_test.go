@@ -0,0 +1,118 @@
+package reason
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// timeoutErr meniru error net yang melaporkan Timeout.
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Reason
+	}{
+		{"nil", nil, None},
+		{"dns", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x.invalid"}}, DNS},
+		{"refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, ConnRefused},
+		{"dial timeout", &net.OpError{Op: "dial", Err: timeoutErr{}}, ConnectTimeout},
+		{"proxyconnect timeout", fmt.Errorf("Get x: %w", &net.OpError{Op: "proxyconnect", Err: timeoutErr{}}), ConnectTimeout},
+		{"read timeout", &net.OpError{Op: "read", Err: timeoutErr{}}, ReadTimeout},
+		{"context deadline", fmt.Errorf("Get x: %w", context.DeadlineExceeded), ReadTimeout},
+		{"deadline socket", os.ErrDeadlineExceeded, ReadTimeout},
+		{"tls record", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, TLS},
+		{"tls teks", errors.New("remote error: tls: handshake failure"), TLS},
+		{"407", errors.New("proxyconnect tcp: Proxy Authentication Required"), AuthRequired},
+		{"lain", errors.New("EOF"), Other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromError(tt.err); got != tt.want {
+				t.Errorf("FromError(%v) = %q, ingin %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOf(t *testing.T) {
+	// Alasan yang sudah dibawa *Error menang atas tebakan dari error asli
+	err := fmt.Errorf("cek: %w", &Error{Reason: Captcha, Err: context.DeadlineExceeded})
+	if got := Of(err); got != Captcha {
+		t.Errorf("Of = %q, ingin %q", got, Captcha)
+	}
+	if got := Of(Errorf(WrongStatus, "HTTP %d", 500)); got != WrongStatus {
+		t.Errorf("Of(Errorf) = %q, ingin %q", got, WrongStatus)
+	}
+	if got := Of(context.DeadlineExceeded); got != ReadTimeout {
+		t.Errorf("Of tanpa *Error = %q, ingin %q", got, ReadTimeout)
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	for code, want := range map[int]Reason{
+		407: AuthRequired,
+		403: Blocked,
+		429: Blocked,
+		451: Blocked,
+		301: WrongStatus,
+		404: WrongStatus,
+		500: WrongStatus,
+		502: WrongStatus,
+	} {
+		if got := FromStatus(code); got != want {
+			t.Errorf("FromStatus(%d) = %q, ingin %q", code, got, want)
+		}
+	}
+}
+
+func TestFromBody(t *testing.T) {
+	isIP := func(body []byte) bool { return net.ParseIP(string(body)) != nil }
+	tests := []struct {
+		name  string
+		body  string
+		valid func([]byte) bool
+		want  Reason
+	}{
+		{"IP", "203.0.113.7", isIP, None},
+		{"kosong", "", isIP, EmptyBody},
+		{"spasi saja", " \n\t", nil, EmptyBody},
+		{"captcha", "<title>Attention Required! | Cloudflare</title>", isIP, Captcha},
+		{"challenge", `<script src="/cdn-cgi/challenge-platform/x.js">`, nil, Captcha},
+		{"halaman diganti", "<html>iklan</html>", isIP, TamperedBody},
+		{"tanpa validasi", "<html>apa saja</html>", nil, None},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromBody([]byte(tt.body), tt.valid); got != tt.want {
+				t.Errorf("FromBody(%q) = %q, ingin %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounter(t *testing.T) {
+	var c Counter
+	for _, r := range []Reason{ReadTimeout, None, DNS, ReadTimeout, Captcha, DNS, ReadTimeout} {
+		c.Add(r)
+	}
+	want := []Entry{{ReadTimeout, 3}, {DNS, 2}, {Captcha, 1}}
+	got := c.Sorted()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Sorted = %v, ingin %v", got, want)
+	}
+	if c.Count(None) != 0 {
+		t.Error("alasan kosong ikut dihitung")
+	}
+}
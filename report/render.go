@@ -0,0 +1,388 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"deep.go/logging"
+)
+
+const (
+	// maxBreakdown adalah jumlah baris terbanyak per sebaran; sisanya digabung.
+	maxBreakdown = 15
+	// maxDiffList adalah jumlah proxy terbanyak yang dicantumkan per daftar diff.
+	maxDiffList = 50
+	barWidth    = 20
+)
+
+// latencyBuckets adalah batas atas rentang latency dalam milidetik.
+var latencyBuckets = []float64{250, 500, 1000, 2000, 4000, 8000}
+
+var stageNames = map[string]logging.Msg{
+	StageScraped:    logging.FunnelScraped,
+	StageNormalized: logging.FunnelNormalized,
+	StageTCP:        logging.FunnelTCP,
+	StageHTTP:       logging.FunnelHTTP,
+	StageExported:   logging.FunnelExported,
+}
+
+// table adalah satu bagian laporan. Markdown dan HTML menggambar model yang
+// sama sehingga isi kedua format selalu sama.
+type table struct {
+	title   string
+	note    string
+	headers []string
+	rows    [][]string
+	// bars, jika ada, berisi panjang batang 0..1 per baris.
+	bars []float64
+}
+
+func sections(run *Run, diff Diff) []table {
+	tables := []table{overview(run, diff), funnel(run), sourceYield(run), latency(run)}
+	for _, b := range []struct {
+		title logging.Msg
+		key   func(Proxy) string
+	}{
+		{logging.RptCountries, func(p Proxy) string { return p.Country }},
+		{logging.RptASNs, func(p Proxy) string { return p.ASN }},
+		{logging.RptAnonymity, func(p Proxy) string { return p.Anonymity }},
+	} {
+		counts := make(map[string]int)
+		for _, p := range run.Live {
+			k := b.key(p)
+			if k == "" {
+				k = Unknown
+			}
+			counts[k]++
+		}
+		tables = append(tables, breakdown(logging.T(b.title), logging.T(logging.RptColValue), counts, len(run.Live)))
+	}
+	total := 0
+	for _, n := range run.Reasons {
+		total += n
+	}
+	tables = append(tables, breakdown(logging.T(logging.RptReasons), logging.T(logging.RptColReason), run.Reasons, total))
+	return append(tables, diffTables(diff)...)
+}
+
+func overview(run *Run, diff Diff) table {
+	t := table{
+		title:   logging.T(logging.RptOverview),
+		headers: []string{logging.T(logging.RptColMetric), logging.T(logging.RptColValue)},
+	}
+	t.rows = append(t.rows,
+		[]string{logging.T(logging.RptTime), run.Time.Format(time.RFC3339)},
+		[]string{logging.T(logging.RptValidCount), fmt.Sprint(len(run.Live))},
+		[]string{logging.T(logging.RptSourceCount), fmt.Sprint(len(run.Sources))},
+	)
+	if values := liveLatencies(run); len(values) > 0 {
+		t.rows = append(t.rows, []string{logging.T(logging.RptMedian), formatMs(percentile(values, 0.5))})
+	}
+	if !diff.Previous.IsZero() {
+		t.rows = append(t.rows,
+			[]string{logging.T(logging.RptPrevious), diff.Previous.Format(time.RFC3339)},
+			[]string{logging.T(logging.RptNewCount), fmt.Sprint(len(diff.New))},
+			[]string{logging.T(logging.RptDeadCount), fmt.Sprint(len(diff.Dead))},
+			[]string{logging.T(logging.RptChurn), formatPercent(diff.Churn)},
+		)
+	}
+	return t
+}
+
+func funnel(run *Run) table {
+	t := table{
+		title:   logging.T(logging.RptFunnel),
+		headers: []string{logging.T(logging.RptColStage), logging.T(logging.RptColCount), logging.T(logging.RptColShare)},
+	}
+	first := 0
+	if len(run.Funnel) > 0 {
+		first = run.Funnel[0].Count
+	}
+	for _, s := range run.Funnel {
+		name := s.Name
+		if id, ok := stageNames[s.Name]; ok {
+			name = logging.T(id)
+		}
+		t.rows = append(t.rows, []string{name, fmt.Sprint(s.Count), formatPercent(ratio(s.Count, first))})
+		t.bars = append(t.bars, ratio(s.Count, first))
+	}
+	return t
+}
+
+func sourceYield(run *Run) table {
+	t := table{
+		title: logging.T(logging.RptSources),
+		headers: []string{
+			logging.T(logging.RptColSource), logging.T(logging.RptColStatus), logging.T(logging.RptColRaw),
+			logging.T(logging.RptColNormalized), logging.T(logging.RptColUnique), logging.T(logging.RptColChecked),
+			logging.T(logging.RptColValid), logging.T(logging.RptColShare),
+		},
+	}
+	sources := append([]Source(nil), run.Sources...)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Valid > sources[j].Valid })
+	highest := 0
+	for _, s := range sources {
+		highest = max(highest, s.Valid)
+	}
+	for _, s := range sources {
+		t.rows = append(t.rows, []string{
+			s.Name, s.Status, fmt.Sprint(s.Raw), fmt.Sprint(s.Normalized), fmt.Sprint(s.Unique),
+			fmt.Sprint(s.Checked), fmt.Sprint(s.Valid), formatPercent(ratio(s.Valid, s.Checked)),
+		})
+		t.bars = append(t.bars, ratio(s.Valid, highest))
+	}
+	return t
+}
+
+func latency(run *Run) table {
+	t := table{
+		title:   logging.T(logging.RptLatency),
+		headers: []string{logging.T(logging.RptColRange), logging.T(logging.RptColCount)},
+	}
+	values := liveLatencies(run)
+	if len(values) == 0 {
+		return t
+	}
+	t.note = logging.T(logging.RptPercentiles,
+		formatMs(percentile(values, 0.5)), formatMs(percentile(values, 0.9)), formatMs(percentile(values, 0.99)))
+
+	counts := make([]int, len(latencyBuckets)+1)
+	for _, v := range values {
+		i := sort.SearchFloat64s(latencyBuckets, v)
+		counts[i]++
+	}
+	highest := 0
+	for _, n := range counts {
+		highest = max(highest, n)
+	}
+	lower := 0.0
+	for i, n := range counts {
+		label := "≥ " + formatMs(lower)
+		if i < len(latencyBuckets) {
+			label = formatMs(lower) + " – " + formatMs(latencyBuckets[i])
+			lower = latencyBuckets[i]
+		}
+		t.rows = append(t.rows, []string{label, fmt.Sprint(n)})
+		t.bars = append(t.bars, ratio(n, highest))
+	}
+	return t
+}
+
+// breakdown membuat tabel jumlah per nilai, terurut dari yang terbanyak.
+func breakdown(title, column string, counts map[string]int, total int) table {
+	t := table{
+		title:   title,
+		headers: []string{column, logging.T(logging.RptColCount), logging.T(logging.RptColShare)},
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	rest := 0
+	if len(keys) > maxBreakdown {
+		for _, k := range keys[maxBreakdown:] {
+			rest += counts[k]
+		}
+		keys = keys[:maxBreakdown]
+	}
+	highest := 0
+	if len(keys) > 0 {
+		highest = counts[keys[0]]
+	}
+	for _, k := range keys {
+		t.rows = append(t.rows, []string{k, fmt.Sprint(counts[k]), formatPercent(ratio(counts[k], total))})
+		t.bars = append(t.bars, ratio(counts[k], highest))
+	}
+	if rest > 0 {
+		t.rows = append(t.rows, []string{logging.T(logging.RptOther), fmt.Sprint(rest), formatPercent(ratio(rest, total))})
+		t.bars = append(t.bars, ratio(rest, highest))
+	}
+	return t
+}
+
+func diffTables(diff Diff) []table {
+	if diff.Previous.IsZero() {
+		return []table{{title: logging.T(logging.RptDiff), note: logging.T(logging.RptNoPrevious)}}
+	}
+	list := func(title logging.Msg, addrs []string) table {
+		t := table{title: logging.T(title), headers: []string{logging.T(logging.RptColProxy)}}
+		for i, addr := range addrs {
+			if i == maxDiffList {
+				t.note = logging.T(logging.RptMore, len(addrs)-maxDiffList)
+				break
+			}
+			t.rows = append(t.rows, []string{addr})
+		}
+		return t
+	}
+	return []table{
+		{
+			title: logging.T(logging.RptDiff),
+			note: logging.T(logging.RptDiffNote, diff.Previous.Format(time.RFC3339),
+				len(diff.New), len(diff.Dead), formatPercent(diff.Churn)),
+		},
+		list(logging.RptNewLive, diff.New),
+		list(logging.RptNewDead, diff.Dead),
+	}
+}
+
+// --- Markdown ---
+
+// WriteMarkdown menulis laporan run dalam Markdown; grafik digambar
+// sebagai batang teks agar tetap terbaca tanpa renderer.
+func WriteMarkdown(w io.Writer, run *Run, diff Diff) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", logging.T(logging.RptTitle, run.Time.Format(time.RFC3339)))
+	for _, t := range sections(run, diff) {
+		fmt.Fprintf(&b, "\n## %s\n\n", t.title)
+		if t.note != "" {
+			fmt.Fprintf(&b, "%s\n\n", t.note)
+		}
+		if len(t.rows) == 0 {
+			continue
+		}
+		headers := t.headers
+		if t.bars != nil {
+			headers = append(headers[:len(headers):len(headers)], "")
+		}
+		fmt.Fprintf(&b, "| %s |\n|%s\n", strings.Join(escapeCells(headers), " | "), strings.Repeat(" --- |", len(headers)))
+		for i, row := range t.rows {
+			cells := escapeCells(row)
+			if t.bars != nil {
+				cells = append(cells, "`"+textBar(t.bars[i])+"`")
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var mdEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+func escapeCells(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		out[i] = mdEscaper.Replace(c)
+	}
+	return out
+}
+
+// textBar menggambar batang sepanjang v (0..1) dengan blok Unicode.
+func textBar(v float64) string {
+	partial := []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+	eighths := int(v*barWidth*8 + 0.5)
+	bar := strings.Repeat("█", eighths/8) + partial[eighths%8]
+	return bar + strings.Repeat(" ", barWidth-(eighths+7)/8)
+}
+
+// --- HTML ---
+
+const htmlStyle = `body{font-family:system-ui,sans-serif;margin:2rem auto;max-width:960px;color:#222}
+h1{font-size:1.5rem}h2{font-size:1.15rem;margin-top:2rem;border-bottom:1px solid #ddd}
+table{border-collapse:collapse;width:100%}th,td{padding:.25rem .5rem;text-align:left;border-bottom:1px solid #eee}
+td.n{text-align:right;font-variant-numeric:tabular-nums}td.bar{width:35%}
+.bar div{background:#4a90d9;height:.8rem;border-radius:2px}p.note{color:#555}`
+
+// WriteHTML menulis laporan run sebagai satu file HTML tanpa aset luar;
+// grafik digambar dengan batang CSS.
+func WriteHTML(w io.Writer, run *Run, diff Diff) error {
+	var b strings.Builder
+	title := html.EscapeString(logging.T(logging.RptTitle, run.Time.Format(time.RFC3339)))
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title><style>%s</style></head>\n<body>\n<h1>%s</h1>\n",
+		title, htmlStyle, title)
+	for _, t := range sections(run, diff) {
+		fmt.Fprintf(&b, "<h2>%s</h2>\n", html.EscapeString(t.title))
+		if t.note != "" {
+			fmt.Fprintf(&b, "<p class=\"note\">%s</p>\n", html.EscapeString(t.note))
+		}
+		if len(t.rows) == 0 {
+			continue
+		}
+		b.WriteString("<table>\n<tr>")
+		for _, h := range t.headers {
+			fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(h))
+		}
+		if t.bars != nil {
+			b.WriteString("<th></th>")
+		}
+		b.WriteString("</tr>\n")
+		for i, row := range t.rows {
+			b.WriteString("<tr>")
+			for _, cell := range row {
+				class := ""
+				if isNumeric(cell) {
+					class = ` class="n"`
+				}
+				fmt.Fprintf(&b, "<td%s>%s</td>", class, html.EscapeString(cell))
+			}
+			if t.bars != nil {
+				fmt.Fprintf(&b, "<td class=\"bar\"><div style=\"width:%.1f%%\"></div></td>", 100*t.bars[i])
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>\n")
+	}
+	b.WriteString("</body></html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func isNumeric(s string) bool {
+	s = strings.TrimSuffix(s, "%")
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// --- angka ---
+
+func liveLatencies(run *Run) []float64 {
+	var values []float64
+	for _, p := range run.Live {
+		if p.LatencyMs > 0 {
+			values = append(values, p.LatencyMs)
+		}
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// percentile mengembalikan persentil q dari values yang sudah terurut.
+func percentile(sorted []float64, q float64) float64 {
+	return sorted[int(q*float64(len(sorted)-1)+0.5)]
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.1f%%", 100*v)
+}
+
+func formatMs(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.1fs", ms/1000)
+	}
+	return fmt.Sprintf("%.0fms", ms)
+}
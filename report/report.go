@@ -0,0 +1,177 @@
+// Package report menulis laporan per run dalam Markdown dan HTML mandiri:
+// hasil per sumber, funnel validitas per tahap, distribusi latency,
+// sebaran negara/ASN/anonimitas, alasan kegagalan, dan perbandingan dengan
+// run sebelumnya. Snapshot run disimpan sebagai JSON agar run berikutnya
+// bisa menghitung proxy yang baru hidup, baru mati, dan tingkat churn.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// LastRunFile adalah nama snapshot run terakhir di direktori laporan.
+const LastRunFile = "last_run.json"
+
+// Nilai untuk proxy yang negara, ASN atau anonimitasnya tidak diketahui.
+const Unknown = "unknown"
+
+// ID tahap funnel yang dikenal; tahap lain ditampilkan apa adanya.
+const (
+	StageScraped    = "scraped"
+	StageNormalized = "normalized"
+	StageTCP        = "tcp"
+	StageHTTP       = "http"
+	StageExported   = "exported"
+)
+
+// Proxy adalah satu proxy valid pada run.
+type Proxy struct {
+	Addr      string   `json:"addr"`
+	Sources   []string `json:"sources,omitempty"`
+	LatencyMs float64  `json:"latency_ms,omitempty"`
+	Country   string   `json:"country,omitempty"`
+	ASN       string   `json:"asn,omitempty"`
+	Anonymity string   `json:"anonymity,omitempty"`
+}
+
+// Source adalah hasil satu sumber pada run.
+type Source struct {
+	Name       string `json:"name"`
+	Status     string `json:"status,omitempty"`
+	Raw        int    `json:"raw"`
+	Normalized int    `json:"normalized"`
+	Unique     int    `json:"unique"`
+	Checked    int    `json:"checked"`
+	Valid      int    `json:"valid"`
+}
+
+// Stage adalah jumlah proxy yang tersisa setelah satu tahap funnel.
+type Stage struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Run adalah snapshot satu run.
+type Run struct {
+	Time    time.Time      `json:"time"`
+	Sources []Source       `json:"sources,omitempty"`
+	Funnel  []Stage        `json:"funnel,omitempty"`
+	Reasons map[string]int `json:"reasons,omitempty"`
+	Live    []Proxy        `json:"live"`
+}
+
+// Diff membandingkan proxy valid run ini dengan run sebelumnya.
+type Diff struct {
+	// Previous adalah waktu run pembanding; nol jika belum ada run sebelumnya.
+	Previous time.Time
+	New      []string
+	Dead     []string
+	// Churn adalah (baru + mati) / (jumlah run sebelumnya + run ini):
+	// 0 berarti pool tidak berubah, 1 berarti pool berganti seluruhnya.
+	Churn float64
+}
+
+// Compare menghitung Diff antara prev dan cur. prev boleh nil.
+func Compare(prev, cur *Run) Diff {
+	if prev == nil {
+		return Diff{}
+	}
+	before := make(map[string]bool, len(prev.Live))
+	for _, p := range prev.Live {
+		before[p.Addr] = true
+	}
+	now := make(map[string]bool, len(cur.Live))
+	d := Diff{Previous: prev.Time}
+	for _, p := range cur.Live {
+		now[p.Addr] = true
+		if !before[p.Addr] {
+			d.New = append(d.New, p.Addr)
+		}
+	}
+	for _, p := range prev.Live {
+		if !now[p.Addr] {
+			d.Dead = append(d.Dead, p.Addr)
+		}
+	}
+	sort.Strings(d.New)
+	sort.Strings(d.Dead)
+	if total := len(before) + len(now); total > 0 {
+		d.Churn = float64(len(d.New)+len(d.Dead)) / float64(total)
+	}
+	return d
+}
+
+// Load membaca snapshot run. File yang belum ada menghasilkan nil tanpa error.
+func Load(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("snapshot run %s rusak: %w", path, err)
+	}
+	return &run, nil
+}
+
+// Save menulis snapshot run secara atomik.
+func (r *Run) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Write membandingkan run dengan snapshot sebelumnya di dir, menulis
+// report-<waktu>.md dan .html, lalu menyimpan run sebagai snapshot baru.
+// Hasil perbandingan dan path laporan yang ditulis dikembalikan.
+func Write(dir string, run *Run) (Diff, []string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Diff{}, nil, err
+	}
+	last := filepath.Join(dir, LastRunFile)
+	prev, err := Load(last)
+	if err != nil {
+		return Diff{}, nil, err
+	}
+	diff := Compare(prev, run)
+
+	base := filepath.Join(dir, "report-"+run.Time.Format("20060102-150405"))
+	var paths []string
+	for _, out := range []struct {
+		ext   string
+		write func(*os.File) error
+	}{
+		{".md", func(f *os.File) error { return WriteMarkdown(f, run, diff) }},
+		{".html", func(f *os.File) error { return WriteHTML(f, run, diff) }},
+	} {
+		f, err := os.Create(base + out.ext)
+		if err != nil {
+			return diff, paths, err
+		}
+		err = out.write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return diff, paths, err
+		}
+		paths = append(paths, base+out.ext)
+	}
+	return diff, paths, run.Save(last)
+}
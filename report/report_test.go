@@ -0,0 +1,71 @@
+package report
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func live(addrs ...string) *Run {
+	run := &Run{Time: time.Unix(1_700_000_000, 0)}
+	for _, addr := range addrs {
+		run.Live = append(run.Live, Proxy{Addr: addr})
+	}
+	return run
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur *Run
+		wantNew   []string
+		wantDead  []string
+		wantChurn float64
+	}{
+		{"run pertama", nil, live("a:1", "b:1"), nil, nil, 0},
+		{"tidak berubah", live("a:1", "b:1"), live("b:1", "a:1"), nil, nil, 0},
+		{"berganti seluruhnya", live("a:1", "b:1"), live("c:1", "d:1"), []string{"c:1", "d:1"}, []string{"a:1", "b:1"}, 1},
+		// (1 baru + 1 mati) / (3 sebelumnya + 3 sekarang)
+		{"sebagian", live("a:1", "b:1", "c:1"), live("d:1", "b:1", "a:1"), []string{"d:1"}, []string{"c:1"}, 2.0 / 6},
+		{"semua mati", live("b:1", "a:1"), live(), nil, []string{"a:1", "b:1"}, 1},
+		{"keduanya kosong", live(), live(), nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compare(tt.prev, tt.cur)
+			if !slices.Equal(d.New, tt.wantNew) || !slices.Equal(d.Dead, tt.wantDead) {
+				t.Errorf("baru %v mati %v, ingin %v %v", d.New, d.Dead, tt.wantNew, tt.wantDead)
+			}
+			if d.Churn != tt.wantChurn {
+				t.Errorf("Churn = %v, ingin %v", d.Churn, tt.wantChurn)
+			}
+			if tt.prev != nil && !d.Previous.Equal(tt.prev.Time) {
+				t.Errorf("Previous = %v, ingin %v", d.Previous, tt.prev.Time)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	first := live("a:1", "b:1")
+	if diff, paths, err := Write(dir, first); err != nil || len(paths) != 2 || !diff.Previous.IsZero() {
+		t.Fatalf("Write pertama = %+v %v %v", diff, paths, err)
+	}
+
+	// Run kedua dibandingkan dengan snapshot run pertama
+	second := live("b:1", "c:1")
+	second.Time = first.Time.Add(time.Hour)
+	diff, _, err := Write(dir, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(diff.New, []string{"c:1"}) || !slices.Equal(diff.Dead, []string{"a:1"}) {
+		t.Errorf("diff = %+v", diff)
+	}
+	saved, err := Load(filepath.Join(dir, LastRunFile))
+	if err != nil || saved == nil || !saved.Time.Equal(second.Time) {
+		t.Fatalf("snapshot = %+v %v, ingin run kedua", saved, err)
+	}
+}
@@ -0,0 +1,265 @@
+// Package sourcecache menyimpan salinan terakhir setiap daftar proxy yang
+// berhasil diambil dan memakai ETag/Last-Modified untuk fetch bersyarat.
+// Daftar yang tidak berubah tidak diunduh ulang, dan saat sumber sedang
+// down salinan terakhir tetap dipakai agar input tidak menyusut.
+package sourcecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Status menjelaskan dari mana body hasil Fetch berasal.
+type Status string
+
+const (
+	// Fresh berarti body baru diunduh dari sumber.
+	Fresh Status = "fresh"
+	// NotModified berarti sumber membalas 304 dan body diambil dari cache.
+	NotModified Status = "not_modified"
+	// Stale berarti fetch gagal dan body diambil dari salinan terakhir.
+	Stale Status = "stale"
+	// Offline berarti jaringan tidak dipakai sama sekali.
+	Offline Status = "offline"
+)
+
+// ErrNotCached dikembalikan dalam mode offline jika sumber belum pernah di-cache.
+var ErrNotCached = errors.New("sumber belum ada di cache")
+
+// ErrEmptyBody adalah Result.Err jika sumber membalas 200 dengan body kosong
+// dan salinan lama dipakai sebagai gantinya.
+var ErrEmptyBody = errors.New("sumber mengembalikan body kosong")
+
+// Options mengatur satu pemanggilan Fetch.
+type Options struct {
+	// Offline hanya memutar ulang salinan di cache tanpa akses jaringan.
+	Offline bool
+	// Header tambahan untuk request, misalnya User-Agent.
+	Header http.Header
+	// Attempts adalah jumlah percobaan maksimum; default 3.
+	Attempts int
+	// BaseDelay adalah jeda awal backoff eksponensial; default 1 detik.
+	BaseDelay time.Duration
+}
+
+// Result adalah hasil Fetch.
+type Result struct {
+	Body      []byte
+	Status    Status
+	FetchedAt time.Time // waktu salinan body diambil dari sumber
+	// Err adalah error fetch asli jika Status adalah Stale, atau ErrEmptyBody.
+	Err error
+}
+
+type meta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// Cache menyimpan body dan metadata sumber di sebuah direktori.
+type Cache struct {
+	dir string
+}
+
+// New membuat cache di dir, membuat direktorinya jika belum ada.
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+func (c *Cache) paths(url string) (bodyPath, metaPath string) {
+	sum := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(sum[:12])
+	return filepath.Join(c.dir, name+".body"), filepath.Join(c.dir, name+".json")
+}
+
+func (c *Cache) load(url string) (*meta, []byte, error) {
+	bodyPath, metaPath := c.paths(url)
+	raw, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var m meta
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, nil, err
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &m, body, nil
+}
+
+func (c *Cache) store(m *meta, body []byte) error {
+	bodyPath, metaPath := c.paths(m.URL)
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Body ditulis lebih dulu supaya metadata tidak pernah menunjuk body lama
+	if err := writeAtomic(bodyPath, body); err != nil {
+		return err
+	}
+	return writeAtomic(metaPath, raw)
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Fetch mengambil url dengan request bersyarat berdasarkan salinan di cache.
+// Jika sumber gagal setelah semua percobaan tetapi salinan lama tersedia,
+// salinan itu dikembalikan dengan Status Stale dan error aslinya di Result.Err;
+// begitu juga jika sumber membalas body kosong.
+func (c *Cache) Fetch(ctx context.Context, client *http.Client, url string, opts Options) (Result, error) {
+	cached, cachedBody, cacheErr := c.load(url)
+	if cacheErr != nil && !errors.Is(cacheErr, fs.ErrNotExist) {
+		// Cache rusak diperlakukan seperti tidak ada
+		cached, cachedBody = nil, nil
+	}
+
+	if opts.Offline {
+		if cached == nil {
+			return Result{}, ErrNotCached
+		}
+		return Result{Body: cachedBody, Status: Offline, FetchedAt: cached.FetchedAt}, nil
+	}
+
+	resp, err := doWithRetry(ctx, client, url, cached, opts)
+	if err != nil {
+		if cached != nil {
+			return Result{Body: cachedBody, Status: Stale, FetchedAt: cached.FetchedAt, Err: err}, nil
+		}
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return Result{Body: cachedBody, Status: NotModified, FetchedAt: cached.FetchedAt}, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		err = fmt.Errorf("failed to read response: %w", err)
+		if cached != nil {
+			return Result{Body: cachedBody, Status: Stale, FetchedAt: cached.FetchedAt, Err: err}, nil
+		}
+		return Result{}, err
+	}
+
+	m := &meta{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	// Body kosong dari sumber yang biasanya berisi tidak menimpa salinan
+	// baik; salinan itu dipakai seperti saat sumber gagal
+	if len(body) == 0 && cached != nil {
+		return Result{Body: cachedBody, Status: Stale, FetchedAt: cached.FetchedAt, Err: ErrEmptyBody}, nil
+	}
+	if err := c.store(m, body); err != nil {
+		return Result{}, fmt.Errorf("failed to write cache: %w", err)
+	}
+	return Result{Body: body, Status: Fresh, FetchedAt: m.FetchedAt}, nil
+}
+
+// doWithRetry mengirim request dengan backoff eksponensial. Hanya error
+// jaringan, 429 dan 5xx yang dicoba ulang; 4xx lain langsung dikembalikan.
+func doWithRetry(ctx context.Context, client *http.Client, url string, cached *meta, opts Options) (*http.Response, error) {
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	delay := opts.BaseDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			// Jitter mencegah semua sumber di host yang sama dicoba bersamaan
+			wait := delay + rand.N(delay/2+1)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			delay *= 2
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range opts.Header {
+			req.Header[key] = values
+		}
+		if cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		switch {
+		case resp.StatusCode == http.StatusOK, resp.StatusCode == http.StatusNotModified:
+			return resp, nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("HTTP %d", resp.StatusCode)
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > delay {
+				delay = retryAfter
+			}
+			resp.Body.Close()
+		default:
+			resp.Body.Close()
+			return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+	}
+	return nil, fmt.Errorf("failed to fetch after %d attempts: %w", attempts, lastErr)
+}
+
+// parseRetryAfter membaca header Retry-After dalam detik, dibatasi 30 detik.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return min(time.Duration(seconds)*time.Second, 30*time.Second)
+}
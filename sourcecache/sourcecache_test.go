@@ -0,0 +1,143 @@
+package sourcecache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeSource adalah sumber daftar proxy yang memakai ETag. status, jika
+// tidak nol, dipakai sebagai balasan request berikutnya.
+type fakeSource struct {
+	body     atomic.Value
+	status   atomic.Int32
+	requests atomic.Int32
+}
+
+func newFakeSource(t *testing.T, body string) (*fakeSource, string) {
+	t.Helper()
+	f := &fakeSource{}
+	f.body.Store(body)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.requests.Add(1)
+		if status := f.status.Load(); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+		body := f.body.Load().(string)
+		etag := `"` + body + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv.URL
+}
+
+var testOpts = Options{BaseDelay: time.Millisecond}
+
+func fetch(t *testing.T, c *Cache, url string, opts Options) Result {
+	t.Helper()
+	result, err := c.Fetch(context.Background(), http.DefaultClient, url, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestFetchConditional(t *testing.T) {
+	c, _ := New(t.TempDir())
+	src, url := newFakeSource(t, "1.2.3.4:80")
+
+	if r := fetch(t, c, url, testOpts); r.Status != Fresh || string(r.Body) != "1.2.3.4:80" {
+		t.Fatalf("fetch pertama = %s %q", r.Status, r.Body)
+	}
+	if r := fetch(t, c, url, testOpts); r.Status != NotModified || string(r.Body) != "1.2.3.4:80" {
+		t.Fatalf("fetch kedua = %s %q, ingin not_modified dari cache", r.Status, r.Body)
+	}
+	src.body.Store("5.6.7.8:80")
+	if r := fetch(t, c, url, testOpts); r.Status != Fresh || string(r.Body) != "5.6.7.8:80" {
+		t.Fatalf("fetch setelah berubah = %s %q", r.Status, r.Body)
+	}
+}
+
+func TestFetchStale(t *testing.T) {
+	c, _ := New(t.TempDir())
+	src, url := newFakeSource(t, "1.2.3.4:80")
+	fetch(t, c, url, testOpts)
+
+	// 5xx dicoba ulang sampai Attempts lalu salinan lama dipakai
+	src.status.Store(http.StatusServiceUnavailable)
+	src.requests.Store(0)
+	r := fetch(t, c, url, Options{Attempts: 3, BaseDelay: time.Millisecond})
+	if r.Status != Stale || string(r.Body) != "1.2.3.4:80" || r.Err == nil {
+		t.Fatalf("fetch saat down = %s %q %v", r.Status, r.Body, r.Err)
+	}
+	if n := src.requests.Load(); n != 3 {
+		t.Errorf("request = %d, ingin 3 percobaan", n)
+	}
+
+	// 4xx selain 429 tidak dicoba ulang
+	src.status.Store(http.StatusNotFound)
+	src.requests.Store(0)
+	if r := fetch(t, c, url, testOpts); r.Status != Stale {
+		t.Errorf("404 = %s, ingin stale", r.Status)
+	}
+	if n := src.requests.Load(); n != 1 {
+		t.Errorf("request 404 = %d, ingin 1", n)
+	}
+}
+
+func TestFetchEmptyKeepsCopy(t *testing.T) {
+	c, _ := New(t.TempDir())
+	src, url := newFakeSource(t, "1.2.3.4:80")
+	fetch(t, c, url, testOpts)
+
+	// Body kosong diganti salinan baik dan tidak menimpanya
+	src.body.Store("")
+	if r := fetch(t, c, url, testOpts); r.Status != Stale || string(r.Body) != "1.2.3.4:80" || !errors.Is(r.Err, ErrEmptyBody) {
+		t.Fatalf("fetch kosong = %s %q %v, ingin stale dari cache", r.Status, r.Body, r.Err)
+	}
+	if r := fetch(t, c, url, Options{Offline: true}); string(r.Body) != "1.2.3.4:80" {
+		t.Errorf("salinan tertimpa body kosong: %q", r.Body)
+	}
+}
+
+func TestFetchOffline(t *testing.T) {
+	c, _ := New(t.TempDir())
+	src, url := newFakeSource(t, "1.2.3.4:80")
+	if _, err := c.Fetch(context.Background(), http.DefaultClient, url, Options{Offline: true}); !errors.Is(err, ErrNotCached) {
+		t.Fatalf("offline tanpa cache = %v, ingin ErrNotCached", err)
+	}
+	fetch(t, c, url, testOpts)
+	src.requests.Store(0)
+	if r := fetch(t, c, url, Options{Offline: true}); r.Status != Offline || string(r.Body) != "1.2.3.4:80" {
+		t.Fatalf("offline = %s %q", r.Status, r.Body)
+	}
+	if n := src.requests.Load(); n != 0 {
+		t.Errorf("mode offline mengirim %d request", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"":      0,
+		"abc":   0,
+		"-5":    0,
+		"2":     2 * time.Second,
+		"3600":  30 * time.Second,
+		"Wed, ": 0,
+	} {
+		if got := parseRetryAfter(in); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, ingin %v", in, got, want)
+		}
+	}
+}
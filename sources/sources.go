@@ -0,0 +1,125 @@
+// Package sources mengambil daftar proxy dari sumber publik. Pengambilan
+// lewat sourcecache memakai fetch bersyarat, retry dan fallback ke salinan
+// terakhir; tanpa cache setiap sumber diambil langsung.
+package sources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"deep.go/parse"
+	"deep.go/sourcecache"
+)
+
+// Source adalah satu daftar proxy yang bisa diambil lewat HTTP.
+type Source struct {
+	Name string
+	URL  string
+}
+
+// Default adalah daftar sumber proxy gratis bawaan.
+var Default = []Source{
+	{"ProxyList-1", "https://raw.githubusercontent.com/TheSpeedX/PROXY-List/master/http.txt"},
+	{"ProxyList-2", "https://raw.githubusercontent.com/monosans/proxy-list/main/proxies/http.txt"},
+	{"ProxyList-3", "https://raw.githubusercontent.com/clarketm/proxy-list/master/proxy-list-raw.txt"},
+	{"ProxyList-4", "https://raw.githubusercontent.com/sunny9577/proxy-scraper/master/proxies.txt"},
+	{"ProxyList-5", "https://raw.githubusercontent.com/ShiftyTR/Proxy-List/master/http.txt"},
+	{"ProxyList-6", "https://raw.githubusercontent.com/roosterkid/openproxylist/main/HTTPS_RAW.txt"},
+	{"ProxyList-7", "https://raw.githubusercontent.com/mmpx12/proxy-list/master/http.txt"},
+	{"ProxyList-8", "https://raw.githubusercontent.com/proxy4parsing/proxy-list/main/http.txt"},
+}
+
+// Fetch adalah hasil pengambilan satu sumber.
+type Fetch struct {
+	Source Source
+	// Proxies sudah ditandai dengan nama sumber di Proxy.Sources.
+	Proxies []parse.Proxy
+	// Result berisi status cache; kosong jika Scraper tanpa cache.
+	Result sourcecache.Result
+	Err    error
+}
+
+// Scraper mengambil sumber. Nilai nol siap dipakai tanpa cache.
+type Scraper struct {
+	// Client untuk request HTTP; nil berarti client dengan timeout 30 detik.
+	Client *http.Client
+	// Cache menyimpan salinan tiap sumber; nil berarti tanpa cache.
+	Cache *sourcecache.Cache
+	// Offline hanya memakai salinan di Cache, tanpa akses jaringan.
+	Offline bool
+}
+
+// NewScraper membuat Scraper yang memakai cache.
+func NewScraper(cache *sourcecache.Cache, offline bool) *Scraper {
+	return &Scraper{
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		Cache:   cache,
+		Offline: offline,
+	}
+}
+
+func (s *Scraper) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// Scrape mengambil satu sumber dan mem-parse isinya dengan parse.Text.
+func (s *Scraper) Scrape(ctx context.Context, src Source) Fetch {
+	fetch := Fetch{Source: src}
+	var body []byte
+	if s.Cache != nil {
+		fetch.Result, fetch.Err = s.Cache.Fetch(ctx, s.client(), src.URL, sourcecache.Options{
+			Offline: s.Offline,
+		})
+		body = fetch.Result.Body
+	} else {
+		body, fetch.Err = get(ctx, s.client(), src.URL)
+	}
+	if fetch.Err != nil {
+		return fetch
+	}
+
+	fetch.Proxies = parse.Text(string(body))
+	for i := range fetch.Proxies {
+		fetch.Proxies[i].Sources = []string{src.Name}
+	}
+	return fetch
+}
+
+// ScrapeAll mengambil semua sumber bersamaan. Hasil dikembalikan dengan
+// urutan yang sama dengan srcs; proxy belum dideduplikasi antar sumber.
+func (s *Scraper) ScrapeAll(ctx context.Context, srcs []Source) []Fetch {
+	fetches := make([]Fetch, len(srcs))
+	var wg sync.WaitGroup
+	for i, src := range srcs {
+		wg.Add(1)
+		go func(i int, src Source) {
+			defer wg.Done()
+			fetches[i] = s.Scrape(ctx, src)
+		}(i, src)
+	}
+	wg.Wait()
+	return fetches
+}
+
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}